@@ -0,0 +1,456 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmi/value"
+	"github.com/openconfig/ygot/ygot"
+	"go.uber.org/zap"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/status"
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+var (
+	defaultTTL        = 5 * time.Minute
+	defaultMaxEntries = 1000000
+	subscriberBuffer  = 1000
+)
+
+// Cache represents an in-memory cache of the latest value per path
+// per device. The entries expire once they haven't been updated within
+// the TTL and the new paths are rejected once the cache is full.
+type Cache struct {
+	sync.RWMutex
+
+	logger      *zap.Logger
+	ttl         time.Duration
+	maxEntries  int
+	size        int
+	devices     map[string]map[string]*entry
+	subscribers map[*subscriber]struct{}
+	metrics     map[string]status.Metrics
+}
+
+// entry represents the latest value of a path.
+type entry struct {
+	key       string
+	path      *gpb.Path
+	value     *gpb.TypedValue
+	timestamp int64
+	updated   time.Time
+}
+
+// subscriber receives the cache updates that match its paths.
+type subscriber struct {
+	target string
+	paths  []*gpb.Path
+	ch     chan *gpb.Notification
+}
+
+// New constructs a new cache.
+func New(cfg config.Config) *Cache {
+	conf := cfg.Global().Cache
+
+	c := &Cache{
+		logger:      config.NamedLogger(cfg.Logger(), "cache"),
+		ttl:         time.Duration(conf.TTL) * time.Second,
+		maxEntries:  conf.MaxEntries,
+		devices:     make(map[string]map[string]*entry),
+		subscribers: make(map[*subscriber]struct{}),
+		metrics:     make(map[string]status.Metrics),
+	}
+
+	if c.ttl < 1 {
+		c.ttl = defaultTTL
+	}
+
+	if c.maxEntries < 1 {
+		c.maxEntries = defaultMaxEntries
+	}
+
+	c.metrics["entries"] = status.NewGauge("cache_entries", "Latest value cache entries")
+	c.metrics["rejectsTotal"] = status.NewCounter("cache_rejects_total", "New paths rejected by the full cache")
+	c.metrics["expiredTotal"] = status.NewCounter("cache_expired_total", "Expired cache entries")
+	c.metrics["errorsTotal"] = status.NewCounter("cache_errors_total", "Data points that couldn't be cached")
+	c.metrics["subscriberDropsTotal"] = status.NewCounter("cache_subscriber_drops_total", "Updates dropped by slow subscribers")
+
+	status.Register(status.Labels{}, c.metrics)
+
+	return c
+}
+
+// Start removes the expired entries periodically until the context is canceled.
+func (c *Cache) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(c.ttl / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.expire(time.Now())
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Set caches the data point value and sends it to the subscribers.
+func (c *Cache) Set(ds telemetry.DataStore) {
+	target, _ := ds["system_id"].(string)
+	if target == "" {
+		c.metrics["errorsTotal"].Inc()
+		return
+	}
+
+	path, err := getPath(ds)
+	if err != nil {
+		c.metrics["errorsTotal"].Inc()
+		return
+	}
+
+	key, err := ygot.PathToString(path)
+	if err != nil {
+		c.metrics["errorsTotal"].Inc()
+		return
+	}
+
+	tv, err := getTypedValue(ds["value"])
+	if err != nil {
+		c.metrics["errorsTotal"].Inc()
+		return
+	}
+
+	e := &entry{
+		key:       key,
+		path:      path,
+		value:     tv,
+		timestamp: getTimestamp(ds["timestamp"]),
+		updated:   time.Now(),
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	device, ok := c.devices[target]
+	if !ok {
+		device = make(map[string]*entry)
+		c.devices[target] = device
+	}
+
+	if _, ok := device[key]; !ok {
+		if c.size >= c.maxEntries {
+			c.metrics["rejectsTotal"].Inc()
+			return
+		}
+
+		c.size++
+		c.metrics["entries"].Set(uint64(c.size))
+	}
+
+	device[key] = e
+
+	if len(c.subscribers) > 0 {
+		c.publish(target, e)
+	}
+}
+
+// publish sends the entry to the matched subscribers without blocking.
+func (c *Cache) publish(target string, e *entry) {
+	var n *gpb.Notification
+
+	for s := range c.subscribers {
+		if !s.match(target, e.path) {
+			continue
+		}
+
+		if n == nil {
+			n = e.notification(target)
+		}
+
+		select {
+		case s.ch <- n:
+		default:
+			c.metrics["subscriberDropsTotal"].Inc()
+		}
+	}
+}
+
+// Get returns the entries that match the target and the paths sorted by
+// the target and path, an empty or "*" target matches all of the devices.
+// The expired entries are skipped.
+func (c *Cache) Get(target string, paths []*gpb.Path) []*gpb.Notification {
+	var (
+		notifications []*gpb.Notification
+		keys          []string
+	)
+
+	s := &subscriber{target: target, paths: paths}
+	now := time.Now()
+
+	c.RLock()
+	for t, device := range c.devices {
+		for _, e := range device {
+			if now.Sub(e.updated) > c.ttl || !s.match(t, e.path) {
+				continue
+			}
+
+			notifications = append(notifications, e.notification(t))
+			keys = append(keys, t+e.key)
+		}
+	}
+	c.RUnlock()
+
+	sort.Sort(byKey{notifications, keys})
+
+	return notifications
+}
+
+// subscribe registers a subscriber, the cancel function unregisters it.
+func (c *Cache) subscribe(target string, paths []*gpb.Path) (<-chan *gpb.Notification, func()) {
+	s := &subscriber{
+		target: target,
+		paths:  paths,
+		ch:     make(chan *gpb.Notification, subscriberBuffer),
+	}
+
+	c.Lock()
+	c.subscribers[s] = struct{}{}
+	c.Unlock()
+
+	return s.ch, func() {
+		c.Lock()
+		delete(c.subscribers, s)
+		c.Unlock()
+	}
+}
+
+func (c *Cache) expire(now time.Time) {
+	c.Lock()
+	defer c.Unlock()
+
+	for target, device := range c.devices {
+		for key, e := range device {
+			if now.Sub(e.updated) > c.ttl {
+				delete(device, key)
+				c.size--
+				c.metrics["expiredTotal"].Inc()
+			}
+		}
+
+		if len(device) == 0 {
+			delete(c.devices, target)
+		}
+	}
+
+	c.metrics["entries"].Set(uint64(c.size))
+}
+
+// byKey sorts the notifications by their keys.
+type byKey struct {
+	notifications []*gpb.Notification
+	keys          []string
+}
+
+func (b byKey) Len() int           { return len(b.keys) }
+func (b byKey) Less(i, j int) bool { return b.keys[i] < b.keys[j] }
+func (b byKey) Swap(i, j int) {
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+	b.notifications[i], b.notifications[j] = b.notifications[j], b.notifications[i]
+}
+
+func (e *entry) notification(target string) *gpb.Notification {
+	return &gpb.Notification{
+		Timestamp: e.timestamp,
+		Prefix:    &gpb.Path{Target: target},
+		Update: []*gpb.Update{
+			{
+				Path: e.path,
+				Val:  e.value,
+			},
+		},
+	}
+}
+
+// match returns true if the target matches and any of the paths is a prefix of the path.
+func (s *subscriber) match(target string, path *gpb.Path) bool {
+	if s.target != "" && s.target != "*" && s.target != target {
+		return false
+	}
+
+	if len(s.paths) == 0 {
+		return true
+	}
+
+	for _, p := range s.paths {
+		if matchPath(p, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPath returns true if the pattern is a prefix of the path, the
+// wildcard "*" matches any element name or key value and "..." matches
+// the rest of the path. The pattern keys match any of the path keys
+// since the data point labels aren't bound to their elements, and the
+// origin is ignored since the data points don't carry it.
+func matchPath(pattern, path *gpb.Path) bool {
+	elems := path.GetElem()
+	for i, p := range pattern.GetElem() {
+		if p.Name == "..." {
+			break
+		}
+
+		if i >= len(elems) {
+			return false
+		}
+
+		if p.Name != "*" && p.Name != elems[i].Name {
+			return false
+		}
+	}
+
+	for _, p := range pattern.GetElem() {
+		for k, v := range p.Key {
+			if v != "*" && !hasKey(elems, k, v) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func hasKey(elems []*gpb.PathElem, key, value string) bool {
+	for _, elem := range elems {
+		if v, ok := elem.Key[key]; ok && v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getPath returns the gNMI path of the data point, the labels are
+// the keys of the last prefix element, e.g. the data point with
+// the /interfaces/interface/state/counters prefix, out-octets key
+// and name label is /interfaces/interface/state/counters[name=et-0/0/0]/out-octets.
+func getPath(ds telemetry.DataStore) (*gpb.Path, error) {
+	path := &gpb.Path{}
+
+	prefix, _ := ds["prefix"].(string)
+	for _, name := range strings.Split(prefix, "/") {
+		if name != "" {
+			path.Elem = append(path.Elem, &gpb.PathElem{Name: name})
+		}
+	}
+
+	labels := getLabels(ds["labels"])
+	if len(labels) > 0 {
+		if len(path.Elem) == 0 {
+			path.Elem = append(path.Elem, &gpb.PathElem{})
+		}
+		path.Elem[len(path.Elem)-1].Key = labels
+	}
+
+	key, _ := ds["key"].(string)
+	for _, name := range strings.Split(key, "/") {
+		if name != "" {
+			path.Elem = append(path.Elem, &gpb.PathElem{Name: name})
+		}
+	}
+
+	if len(path.Elem) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	return path, nil
+}
+
+func getLabels(v interface{}) map[string]string {
+	switch labels := v.(type) {
+	case map[string]string:
+		if len(labels) == 0 {
+			return nil
+		}
+
+		m := make(map[string]string, len(labels))
+		for k, v := range labels {
+			m[k] = v
+		}
+
+		return m
+	case map[string]interface{}:
+		if len(labels) == 0 {
+			return nil
+		}
+
+		m := make(map[string]string, len(labels))
+		for k, v := range labels {
+			m[k] = fmt.Sprint(v)
+		}
+
+		return m
+	}
+
+	return nil
+}
+
+// getTypedValue returns the gNMI value, the non-scalar values are JSON encoded.
+func getTypedValue(v interface{}) (*gpb.TypedValue, error) {
+	if tv, err := value.FromScalar(v); err == nil {
+		return tv, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gpb.TypedValue{Value: &gpb.TypedValue_JsonVal{JsonVal: b}}, nil
+}
+
+// getTimestamp returns the data point timestamp in nanoseconds, the
+// telemetries report it in seconds, milliseconds or nanoseconds.
+func getTimestamp(v interface{}) int64 {
+	var ts int64
+
+	switch t := v.(type) {
+	case int64:
+		ts = t
+	case uint64:
+		ts = int64(t)
+	case int:
+		ts = int64(t)
+	case uint32:
+		ts = int64(t)
+	case float64:
+		ts = int64(t)
+	}
+
+	switch {
+	case ts <= 0:
+		return time.Now().UnixNano()
+	case ts < 1e11:
+		return ts * int64(time.Second)
+	case ts < 1e14:
+		return ts * int64(time.Millisecond)
+	}
+
+	return ts
+}
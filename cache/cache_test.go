@@ -0,0 +1,129 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/ygot/ygot"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+func testDataStore(systemID, name, key string, value interface{}) telemetry.DataStore {
+	return telemetry.DataStore{
+		"system_id": systemID,
+		"prefix":    "/interfaces/interface/state/counters/",
+		"labels":    map[string]string{"name": name},
+		"key":       key,
+		"value":     value,
+		"timestamp": int64(1596928627212),
+	}
+}
+
+func testPath(t *testing.T, path string) *gpb.Path {
+	p, err := ygot.StringToStructuredPath(path)
+	assert.NoError(t, err)
+	return p
+}
+
+func pathString(t *testing.T, n *gpb.Notification) string {
+	p, err := ygot.PathToString(n.Update[0].Path)
+	assert.NoError(t, err)
+	return p
+}
+
+func TestCacheSetGet(t *testing.T) {
+	c := New(config.NewMockConfig())
+
+	c.Set(testDataStore("core1.lax", "et-0/0/0", "out-octets", uint64(10)))
+	c.Set(testDataStore("core1.lax", "et-0/0/0", "out-octets", uint64(20)))
+	c.Set(testDataStore("core1.lax", "et-0/0/1", "in-octets", int64(5)))
+	c.Set(testDataStore("core1.lhr", "et-0/0/0", "oper-status", "UP"))
+	c.Set(testDataStore("core1.lhr", "et-0/0/0", "queues", map[string]interface{}{"q0": 1}))
+	// invalid data points
+	c.Set(telemetry.DataStore{"key": "out-octets", "value": 1})
+	c.Set(telemetry.DataStore{"system_id": "core1.lax", "value": 1})
+
+	assert.Equal(t, 4, c.size)
+	assert.Equal(t, uint64(2), c.metrics["errorsTotal"].Get())
+
+	n := c.Get("core1.lax", nil)
+	assert.Len(t, n, 2)
+	assert.Equal(t, "core1.lax", n[0].Prefix.Target)
+	assert.Equal(t, "/interfaces/interface/state/counters[name=et-0/0/0]/out-octets", pathString(t, n[0]))
+	assert.Equal(t, uint64(20), n[0].Update[0].Val.GetUintVal())
+	assert.Equal(t, int64(1596928627212000000), n[0].Timestamp)
+
+	n = c.Get("*", []*gpb.Path{testPath(t, "/interfaces/interface[name=et-0/0/0]/state/counters")})
+	assert.Len(t, n, 3)
+	assert.Equal(t, "core1.lax", n[0].Prefix.Target)
+	assert.Equal(t, "core1.lhr", n[1].Prefix.Target)
+	assert.Equal(t, "UP", n[1].Update[0].Val.GetStringVal())
+	assert.Equal(t, `{"q0":1}`, string(n[2].Update[0].Val.GetJsonVal()))
+
+	n = c.Get("", []*gpb.Path{testPath(t, "/interfaces/*/state/counters/in-octets")})
+	assert.Len(t, n, 1)
+	assert.Equal(t, int64(5), n[0].Update[0].Val.GetIntVal())
+
+	n = c.Get("", []*gpb.Path{testPath(t, "/network-instances/...")})
+	assert.Len(t, n, 0)
+}
+
+func TestCacheLimits(t *testing.T) {
+	cfg := config.NewMockConfig()
+	cfg.MGlobal.Cache = config.Cache{TTL: 60, MaxEntries: 2}
+	c := New(cfg)
+
+	c.Set(testDataStore("core1.lax", "et-0/0/0", "out-octets", 1))
+	c.Set(testDataStore("core1.lax", "et-0/0/0", "in-octets", 1))
+	c.Set(testDataStore("core1.lax", "et-0/0/0", "out-errors", 1))
+	// the existing path is updated once the cache is full
+	c.Set(testDataStore("core1.lax", "et-0/0/0", "out-octets", 2))
+
+	assert.Equal(t, 2, c.size)
+	assert.Equal(t, uint64(1), c.metrics["rejectsTotal"].Get())
+	assert.Len(t, c.Get("", nil), 2)
+
+	c.expire(time.Now().Add(30 * time.Second))
+	assert.Equal(t, 2, c.size)
+
+	c.expire(time.Now().Add(2 * time.Minute))
+	assert.Equal(t, 0, c.size)
+	assert.Len(t, c.devices, 0)
+	assert.Equal(t, uint64(2), c.metrics["expiredTotal"].Get())
+	assert.Equal(t, uint64(0), c.metrics["entries"].Get())
+
+	c.Set(testDataStore("core1.lax", "et-0/0/0", "out-errors", 1))
+	assert.Len(t, c.Get("", nil), 1)
+}
+
+func TestCacheSubscribe(t *testing.T) {
+	c := New(config.NewMockConfig())
+
+	ch, cancel := c.subscribe("core1.lax", []*gpb.Path{testPath(t, "/interfaces/interface[name=*]/state/counters/out-octets")})
+
+	c.Set(testDataStore("core1.lax", "et-0/0/0", "out-octets", 1))
+	c.Set(testDataStore("core1.lax", "et-0/0/0", "in-octets", 1))
+	c.Set(testDataStore("core1.lhr", "et-0/0/0", "out-octets", 1))
+
+	assert.Len(t, ch, 1)
+	assert.Equal(t, "core1.lax", (<-ch).Prefix.Target)
+
+	cancel()
+	assert.Len(t, c.subscribers, 0)
+}
+
+func TestGetTimestamp(t *testing.T) {
+	ns := int64(1596928627212000000)
+
+	assert.Equal(t, ns, getTimestamp(ns))
+	assert.Equal(t, ns, getTimestamp(uint64(1596928627212)))
+	assert.Equal(t, int64(1596928627000000000), getTimestamp(1596928627))
+	assert.InDelta(t, time.Now().UnixNano(), getTimestamp(nil), float64(time.Second))
+}
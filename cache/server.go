@@ -0,0 +1,212 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	grpcstatus "google.golang.org/grpc/status"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/secret"
+)
+
+const gnmiVersion = "0.7.0"
+
+// Server represents the gNMI server of the cache (collector as target),
+// it answers Get from the cache and re-streams the cache updates to the
+// subscribers with the device as the target.
+type Server struct {
+	cfg    config.Config
+	cache  *Cache
+	logger *zap.Logger
+}
+
+// NewServer constructs a new gNMI server.
+func NewServer(cfg config.Config, cache *Cache) *Server {
+	return &Server{
+		cfg:    cfg,
+		cache:  cache,
+		logger: cache.logger,
+	}
+}
+
+// Start starts the gNMI server, it stops once the context is canceled.
+func (s *Server) Start(ctx context.Context) error {
+	var grpcSrvOpts []grpc.ServerOption
+
+	conf := s.cfg.Global().Cache
+
+	if conf.Addr == "" {
+		return errors.New("address is empty")
+	}
+
+	if conf.TLSConfig.Enabled {
+		tlsConfig, err := secret.GetTLSServerConfig(&conf.TLSConfig)
+		if err != nil {
+			return err
+		}
+
+		grpcSrvOpts = append(grpcSrvOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	ln, err := net.Listen("tcp", conf.Addr)
+	if err != nil {
+		return err
+	}
+
+	srv := grpc.NewServer(grpcSrvOpts...)
+	gpb.RegisterGNMIServer(srv, s)
+	go srv.Serve(ln)
+
+	go func() {
+		<-ctx.Done()
+		srv.Stop()
+	}()
+
+	s.logger.Info("cache.gnmi", zap.String("address", conf.Addr), zap.Bool("tls", conf.TLSConfig.Enabled))
+
+	return nil
+}
+
+// Capabilities returns the supported encodings and gNMI version.
+func (s *Server) Capabilities(context.Context, *gpb.CapabilityRequest) (*gpb.CapabilityResponse, error) {
+	return &gpb.CapabilityResponse{
+		SupportedEncodings: []gpb.Encoding{gpb.Encoding_JSON, gpb.Encoding_PROTO},
+		GNMIVersion:        gnmiVersion,
+	}, nil
+}
+
+// Get returns the latest values of the requested paths, the prefix
+// target is the device and an empty or "*" target matches all of them.
+func (s *Server) Get(ctx context.Context, req *gpb.GetRequest) (*gpb.GetResponse, error) {
+	notifications := s.cache.Get(req.GetPrefix().GetTarget(), joinPaths(req.GetPrefix(), req.GetPath()))
+	if len(notifications) == 0 {
+		return nil, grpcstatus.Error(codes.NotFound, "no data found")
+	}
+
+	return &gpb.GetResponse{Notification: notifications}, nil
+}
+
+// Set isn't supported by the cache.
+func (s *Server) Set(context.Context, *gpb.SetRequest) (*gpb.SetResponse, error) {
+	return nil, grpcstatus.Error(codes.Unimplemented, "set is not supported")
+}
+
+// Subscribe supports the once, poll and stream modes. The stream
+// subscription sends the cached values, the sync response and then
+// re-streams every update regardless of the subscription mode.
+func (s *Server) Subscribe(stream gpb.GNMI_SubscribeServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	list := req.GetSubscribe()
+	if list == nil {
+		return grpcstatus.Error(codes.InvalidArgument, "subscription list is required")
+	}
+
+	target := list.GetPrefix().GetTarget()
+	paths := make([]*gpb.Path, 0, len(list.GetSubscription()))
+	for _, sub := range list.GetSubscription() {
+		paths = append(paths, sub.GetPath())
+	}
+	paths = joinPaths(list.GetPrefix(), paths)
+
+	if p, ok := peer.FromContext(stream.Context()); ok {
+		s.logger.Info("cache.gnmi", zap.String("event", "subscribe"), zap.String("peer", p.Addr.String()),
+			zap.String("mode", list.GetMode().String()), zap.String("target", target))
+	}
+
+	switch list.GetMode() {
+	case gpb.SubscriptionList_ONCE:
+		return s.sendCache(stream, target, paths, list.GetUpdatesOnly())
+	case gpb.SubscriptionList_POLL:
+		return s.poll(stream, target, paths)
+	}
+
+	ch, cancel := s.cache.subscribe(target, paths)
+	defer cancel()
+
+	if err := s.sendCache(stream, target, paths, list.GetUpdatesOnly()); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case n := <-ch:
+			if err := stream.Send(&gpb.SubscribeResponse{Response: &gpb.SubscribeResponse_Update{Update: n}}); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// poll sends the cached values once the subscriber polls.
+func (s *Server) poll(stream gpb.GNMI_SubscribeServer, target string, paths []*gpb.Path) error {
+	if err := s.sendCache(stream, target, paths, false); err != nil {
+		return err
+	}
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if req.GetPoll() == nil {
+			return grpcstatus.Error(codes.InvalidArgument, "poll request is required")
+		}
+
+		if err := s.sendCache(stream, target, paths, false); err != nil {
+			return err
+		}
+	}
+}
+
+// sendCache sends the cached values, unless updates only is requested, and the sync response.
+func (s *Server) sendCache(stream gpb.GNMI_SubscribeServer, target string, paths []*gpb.Path, updatesOnly bool) error {
+	if !updatesOnly {
+		for _, n := range s.cache.Get(target, paths) {
+			if err := stream.Send(&gpb.SubscribeResponse{Response: &gpb.SubscribeResponse_Update{Update: n}}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return stream.Send(&gpb.SubscribeResponse{Response: &gpb.SubscribeResponse_SyncResponse{SyncResponse: true}})
+}
+
+// joinPaths prepends the prefix elements to the paths.
+func joinPaths(prefix *gpb.Path, paths []*gpb.Path) []*gpb.Path {
+	if len(prefix.GetElem()) == 0 {
+		return paths
+	}
+
+	if len(paths) == 0 {
+		return []*gpb.Path{{Elem: prefix.GetElem()}}
+	}
+
+	joined := make([]*gpb.Path, 0, len(paths))
+	for _, p := range paths {
+		elems := append(append([]*gpb.PathElem{}, prefix.GetElem()...), p.GetElem()...)
+		joined = append(joined, &gpb.Path{Elem: elems})
+	}
+
+	return joined
+}
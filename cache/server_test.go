@@ -0,0 +1,135 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+
+	"github.com/yahoo/panoptes-stream/config"
+)
+
+func TestServer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.NewMockConfig()
+	cfg.MGlobal.Cache = config.Cache{Enabled: true, Addr: "127.0.0.1:50580"}
+
+	c := New(cfg)
+	c.Set(testDataStore("core1.lax", "et-0/0/0", "out-octets", uint64(10)))
+	c.Set(testDataStore("core1.lhr", "et-0/0/0", "out-octets", uint64(20)))
+
+	err := NewServer(cfg, c).Start(ctx)
+	assert.NoError(t, err)
+
+	conn, err := grpc.Dial("127.0.0.1:50580", grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(time.Second))
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	client := gpb.NewGNMIClient(conn)
+
+	t.Run("get", func(t *testing.T) {
+		resp, err := client.Get(ctx, &gpb.GetRequest{
+			Prefix: &gpb.Path{Target: "core1.lax"},
+			Path:   []*gpb.Path{testPath(t, "/interfaces/interface[name=et-0/0/0]")},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Notification, 1)
+		assert.Equal(t, uint64(10), resp.Notification[0].Update[0].Val.GetUintVal())
+
+		_, err = client.Get(ctx, &gpb.GetRequest{Prefix: &gpb.Path{Target: "core1.ams"}})
+		assert.Equal(t, codes.NotFound, grpcstatus.Code(err))
+
+		_, err = client.Set(ctx, &gpb.SetRequest{})
+		assert.Equal(t, codes.Unimplemented, grpcstatus.Code(err))
+	})
+
+	t.Run("once", func(t *testing.T) {
+		stream, err := client.Subscribe(ctx)
+		assert.NoError(t, err)
+
+		err = stream.Send(&gpb.SubscribeRequest{Request: &gpb.SubscribeRequest_Subscribe{
+			Subscribe: &gpb.SubscriptionList{Mode: gpb.SubscriptionList_ONCE, Prefix: &gpb.Path{Target: "*"}},
+		}})
+		assert.NoError(t, err)
+
+		for _, target := range []string{"core1.lax", "core1.lhr"} {
+			resp, err := stream.Recv()
+			assert.NoError(t, err)
+			assert.Equal(t, target, resp.GetUpdate().GetPrefix().GetTarget())
+		}
+
+		resp, err := stream.Recv()
+		assert.NoError(t, err)
+		assert.True(t, resp.GetSyncResponse())
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		stream, err := client.Subscribe(ctx)
+		assert.NoError(t, err)
+
+		err = stream.Send(&gpb.SubscribeRequest{Request: &gpb.SubscribeRequest_Subscribe{
+			Subscribe: &gpb.SubscriptionList{
+				Mode:   gpb.SubscriptionList_STREAM,
+				Prefix: &gpb.Path{Target: "core1.lhr"},
+				Subscription: []*gpb.Subscription{
+					{Path: testPath(t, "/interfaces/interface/state/counters/out-octets")},
+				},
+			},
+		}})
+		assert.NoError(t, err)
+
+		resp, err := stream.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(20), resp.GetUpdate().GetUpdate()[0].GetVal().GetUintVal())
+
+		resp, err = stream.Recv()
+		assert.NoError(t, err)
+		assert.True(t, resp.GetSyncResponse())
+
+		c.Set(testDataStore("core1.lax", "et-0/0/0", "out-octets", uint64(11)))
+		c.Set(testDataStore("core1.lhr", "et-0/0/0", "out-octets", uint64(21)))
+
+		resp, err = stream.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, "core1.lhr", resp.GetUpdate().GetPrefix().GetTarget())
+		assert.Equal(t, uint64(21), resp.GetUpdate().GetUpdate()[0].GetVal().GetUintVal())
+	})
+
+	t.Run("poll", func(t *testing.T) {
+		stream, err := client.Subscribe(ctx)
+		assert.NoError(t, err)
+
+		err = stream.Send(&gpb.SubscribeRequest{Request: &gpb.SubscribeRequest_Subscribe{
+			Subscribe: &gpb.SubscriptionList{
+				Mode:   gpb.SubscriptionList_POLL,
+				Prefix: &gpb.Path{Target: "core1.lax"},
+			},
+		}})
+		assert.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			resp, err := stream.Recv()
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(11), resp.GetUpdate().GetUpdate()[0].GetVal().GetUintVal())
+
+			resp, err = stream.Recv()
+			assert.NoError(t, err)
+			assert.True(t, resp.GetSyncResponse())
+
+			err = stream.Send(&gpb.SubscribeRequest{Request: &gpb.SubscribeRequest_Poll{Poll: &gpb.Poll{}}})
+			assert.NoError(t, err)
+		}
+
+		stream.CloseSend()
+	})
+}
@@ -25,6 +25,7 @@ type DeviceConfig struct {
 	Port int
 
 	GroupID int `yaml:"groupID"`
+	Dialout bool
 
 	DeviceOptions `yaml:",inline"`
 }
@@ -78,6 +79,7 @@ type Global struct {
 	Version          string
 	Logger           map[string]interface{}
 	Dialout          Dialout
+	Cache            Cache
 }
 
 // TLSConfig represents TLS client configuration
@@ -94,9 +96,19 @@ type TLSConfig struct {
 type Status struct {
 	Addr      string
 	Disabled  bool
+	Admin     bool
 	TLSConfig TLSConfig `yaml:"tlsConfig"`
 }
 
+// Cache represents the latest value cache and its gNMI server configuration
+type Cache struct {
+	Enabled    bool
+	Addr       string
+	TTL        int       `yaml:"ttl"`
+	MaxEntries int       `yaml:"maxEntries"`
+	TLSConfig  TLSConfig `yaml:"tlsConfig"`
+}
+
 // Shards represents shard service configuration
 type Shards struct {
 	Enabled            bool
@@ -124,12 +136,15 @@ type Dialout struct {
 	TLSConfig     TLSConfig `yaml:"tlsConfig"`
 	DefaultOutput string    `yaml:"defaultOutput"`
 	Services      map[string]DialoutService
+	AllowedCIDRs  []string `yaml:"allowedCIDRs"`
+	AllowedNames  []string `yaml:"allowedNames"`
 }
 
 // DialoutService represent specific dialout telemetry
 type DialoutService struct {
-	Addr    string
-	Workers int
+	Addr        string
+	Workers     int
+	Descriptors string
 }
 
 // DeviceTemplate represents device configuration structure
@@ -26,6 +26,8 @@ func ConvDeviceTemplate(d DeviceTemplate) Device {
 }
 
 // GetLogger tries to create a zap logger based on the user configuration.
+// The encoding is console if it's not configured. The logger level and
+// the component levels (levels) are changeable at runtime, see SetLogLevel.
 func GetLogger(lcfg map[string]interface{}) *zap.Logger {
 	var cfg zap.Config
 	b, err := json.Marshal(lcfg)
@@ -37,18 +39,30 @@ func GetLogger(lcfg map[string]interface{}) *zap.Logger {
 		return GetDefaultLogger()
 	}
 
-	cfg.Encoding = "console"
+	if cfg.Encoding == "" {
+		cfg.Encoding = "console"
+	}
+
 	cfg.EncoderConfig = zap.NewProductionEncoderConfig()
 	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 	cfg.EncoderConfig.EncodeCaller = nil
 	cfg.DisableStacktrace = true
 
+	// the level core filters the entries by the root and component levels
+	cfg.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+
 	logger, err := cfg.Build()
 	if err != nil {
 		return GetDefaultLogger()
 	}
 
-	return logger
+	if err := SetLogLevels(lcfg); err != nil {
+		logger.Error("logger", zap.Error(err))
+	}
+
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &levelCore{Core: core, root: levels.root}
+	}))
 }
 
 // GetDefaultLogger creates default zap logger.
@@ -83,10 +97,16 @@ func DeviceValidation(device Device) error {
 		return fmt.Errorf("device: %s doesn't have host", device.Host)
 	}
 
-	if device.Port < 1 {
+	// the dial-out device initiates the connection
+	if device.Port < 1 && !device.Dialout {
 		return fmt.Errorf("device: %s has invalid port", device.Host)
 	}
 
+	// the dial-out device updates are decoded by a single service
+	if device.Dialout && len(device.Sensors) > 1 {
+		return fmt.Errorf("device: %s dial-out has more than one service", device.Host)
+	}
+
 	return nil
 }
 
@@ -99,6 +119,7 @@ func SensorValidation(sensor Sensor) error {
 		"cisco.mdt":         true,
 		"cisco.mdt.dialout": true,
 		"juniper.jti":       true,
+		"juniper.udp":       true,
 	}
 
 	if _, ok := availSensors[sensor.Service]; !ok {
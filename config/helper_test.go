@@ -40,6 +40,14 @@ func TestDeviceValidation(t *testing.T) {
 	d.Port = 50051
 	assert.Nil(t, DeviceValidation(d))
 
+	// the dial-out device doesn't have port
+	d.Port = 0
+	d.Dialout = true
+	assert.Nil(t, DeviceValidation(d))
+
+	// the dial-out device has a single service
+	d.Sensors["arista.gnmi"] = []*Sensor{{}}
+	assert.NotNil(t, DeviceValidation(d))
 }
 
 func TestGetDefaultLogger(t *testing.T) {
@@ -0,0 +1,221 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RootLogger is the name of the root logger level.
+const RootLogger = "root"
+
+// logLevels represents the runtime log levels, the component levels
+// override the root level. The levels are copy on write to read them
+// without lock at every log entry.
+type logLevels struct {
+	sync.Mutex
+	root   zap.AtomicLevel
+	levels atomic.Value
+}
+
+// levelCore filters the log entries by the component levels, the
+// names are ordered from the most to the least specific component.
+// The root enabler decides if none of the components has a level.
+type levelCore struct {
+	zapcore.Core
+	root      zapcore.LevelEnabler
+	component string
+	names     []string
+}
+
+var levels = newLogLevels()
+
+func newLogLevels() *logLevels {
+	l := &logLevels{root: zap.NewAtomicLevelAt(zapcore.InfoLevel)}
+	l.levels.Store(map[string]zapcore.Level{})
+
+	return l
+}
+
+func (l *logLevels) get() map[string]zapcore.Level {
+	return l.levels.Load().(map[string]zapcore.Level)
+}
+
+func (l *logLevels) set(name string, level *zapcore.Level) {
+	l.Lock()
+	defer l.Unlock()
+
+	m := make(map[string]zapcore.Level)
+	for k, v := range l.get() {
+		m[k] = v
+	}
+
+	if level != nil {
+		m[name] = *level
+	} else {
+		delete(m, name)
+	}
+
+	l.levels.Store(m)
+}
+
+// reset replaces the component levels.
+func (l *logLevels) reset(m map[string]zapcore.Level) {
+	l.Lock()
+	defer l.Unlock()
+	l.levels.Store(m)
+}
+
+// NamedLogger returns the component logger, its level is
+// changeable at runtime through SetLogLevel by the logger name,
+// e.g. telemetry.juniper.gnmi. The component inherits its parent
+// level if the level isn't set.
+func NamedLogger(logger *zap.Logger, name string) *zap.Logger {
+	if logger == nil {
+		return nil
+	}
+
+	return logger.Named(name).WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		c := unwrapCore(core)
+		if c.component != "" {
+			c.component += "." + name
+		} else {
+			c.component = name
+		}
+
+		c.names = append([]string{c.component}, c.names...)
+
+		return c
+	}))
+}
+
+// DeviceLogger returns the device logger of the component logger,
+// the device level (device:host) overrides the component level.
+func DeviceLogger(logger *zap.Logger, host string) *zap.Logger {
+	if logger == nil {
+		return nil
+	}
+
+	return logger.With(zap.String("host", host)).WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		c := unwrapCore(core)
+		c.names = append([]string{"device:" + host}, c.names...)
+
+		return c
+	}))
+}
+
+// unwrapCore returns a copy of the level core, the root
+// enabler of a core that's not a level core is the core itself.
+func unwrapCore(core zapcore.Core) *levelCore {
+	if c, ok := core.(*levelCore); ok {
+		return &levelCore{
+			Core:      c.Core,
+			root:      c.root,
+			component: c.component,
+			names:     c.names,
+		}
+	}
+
+	return &levelCore{Core: core, root: core}
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	m := levels.get()
+	for _, name := range c.names {
+		if l, ok := m[name]; ok {
+			return l.Enabled(level)
+		}
+	}
+
+	return c.root.Enabled(level)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), root: c.root, component: c.component, names: c.names}
+}
+
+func (c *levelCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(e.Level) {
+		return ce
+	}
+
+	return c.Core.Check(e, ce)
+}
+
+// SetLogLevel sets the logger level at runtime, the name is
+// RootLogger, a component logger name or device:host.
+func SetLogLevel(name, level string) error {
+	var l zapcore.Level
+
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return err
+	}
+
+	if name == RootLogger {
+		levels.root.SetLevel(l)
+		return nil
+	}
+
+	levels.set(name, &l)
+
+	return nil
+}
+
+// ResetLogLevel resets the component logger level to its parent level.
+func ResetLogLevel(name string) {
+	levels.set(name, nil)
+}
+
+// LogLevels returns the root level and the component levels.
+func LogLevels() map[string]string {
+	m := map[string]string{RootLogger: levels.root.Level().String()}
+	for name, level := range levels.get() {
+		m[name] = level.String()
+	}
+
+	return m
+}
+
+// SetLogLevels sets the root level and replaces the component levels
+// from the logger configuration (level and levels keys).
+func SetLogLevels(lcfg map[string]interface{}) error {
+	var cfg struct {
+		Level  string
+		Levels map[string]string
+	}
+
+	b, err := json.Marshal(lcfg)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return err
+	}
+
+	m := make(map[string]zapcore.Level)
+	for name, level := range cfg.Levels {
+		var l zapcore.Level
+		if err := l.UnmarshalText([]byte(level)); err != nil {
+			return fmt.Errorf("logger %s: %v", name, err)
+		}
+		m[name] = l
+	}
+
+	if cfg.Level != "" {
+		if err := SetLogLevel(RootLogger, cfg.Level); err != nil {
+			return err
+		}
+	}
+
+	levels.reset(m)
+
+	return nil
+}
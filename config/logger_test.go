@@ -0,0 +1,113 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func testLogger() (*zap.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return zap.New(&levelCore{Core: core, root: levels.root}), logs
+}
+
+func TestNamedLogger(t *testing.T) {
+	defer SetLogLevels(map[string]interface{}{"level": "info"})
+
+	root, logs := testLogger()
+	telemetry := NamedLogger(root, "telemetry")
+	nmi := NamedLogger(telemetry, "juniper.gnmi")
+	device1 := DeviceLogger(nmi, "core1.lax")
+	device2 := DeviceLogger(nmi, "core1.lhr")
+
+	device1.Debug("debug")
+	telemetry.Info("info")
+	assert.Equal(t, 1, logs.Len())
+	assert.Equal(t, "telemetry", logs.TakeAll()[0].LoggerName)
+
+	// component level
+	assert.NoError(t, SetLogLevel("telemetry.juniper.gnmi", "debug"))
+	device1.Debug("debug")
+	telemetry.Debug("debug")
+	entries := logs.TakeAll()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "telemetry.juniper.gnmi", entries[0].LoggerName)
+	assert.Equal(t, "core1.lax", entries[0].ContextMap()["host"])
+
+	// device level overrides the component level
+	assert.NoError(t, SetLogLevel("device:core1.lax", "error"))
+	device1.Info("info")
+	device2.Info("info")
+	entries = logs.TakeAll()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "core1.lhr", entries[0].ContextMap()["host"])
+
+	// the component inherits the parent level
+	ResetLogLevel("telemetry.juniper.gnmi")
+	ResetLogLevel("device:core1.lax")
+	assert.NoError(t, SetLogLevel("telemetry", "warn"))
+	device1.Info("info")
+	nmi.With(zap.String("key", "value")).Warn("warn")
+	assert.Equal(t, 1, logs.Len())
+	logs.TakeAll()
+
+	// root level
+	ResetLogLevel("telemetry")
+	assert.NoError(t, SetLogLevel(RootLogger, "debug"))
+	device2.Debug("debug")
+	assert.Equal(t, 1, logs.Len())
+
+	assert.Error(t, SetLogLevel("demux", "verbose"))
+	assert.Equal(t, map[string]string{RootLogger: "debug"}, LogLevels())
+}
+
+func TestSetLogLevels(t *testing.T) {
+	defer SetLogLevels(map[string]interface{}{"level": "info"})
+
+	err := SetLogLevels(map[string]interface{}{
+		"level":  "warn",
+		"levels": map[string]interface{}{"demux": "debug", "device:core1.lax": "debug"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{RootLogger: "warn", "demux": "debug", "device:core1.lax": "debug"}, LogLevels())
+
+	err = SetLogLevels(map[string]interface{}{"levels": map[string]interface{}{"demux": "verbose"}})
+	assert.Error(t, err)
+	assert.Equal(t, "debug", LogLevels()["demux"])
+
+	// the levels are replaced
+	assert.NoError(t, SetLogLevels(map[string]interface{}{"level": "info"}))
+	assert.Equal(t, map[string]string{RootLogger: "info"}, LogLevels())
+}
+
+func TestGetLoggerEncoding(t *testing.T) {
+	defer SetLogLevels(map[string]interface{}{"level": "info"})
+
+	logger := GetLogger(map[string]interface{}{
+		"level":       "error",
+		"encoding":    "json",
+		"outputPaths": []string{"stdout"},
+	})
+
+	assert.Equal(t, "error", LogLevels()[RootLogger])
+	assert.Nil(t, logger.Check(zapcore.InfoLevel, "info"))
+
+	assert.NoError(t, SetLogLevel(RootLogger, "info"))
+	assert.NotNil(t, logger.Check(zapcore.InfoLevel, "info"))
+}
+
+func TestNamedLoggerForeignCore(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := NamedLogger(zap.New(core), "demux")
+
+	logger.Debug("debug")
+	logger.Info("info")
+	assert.Equal(t, 1, logs.Len())
+	assert.Nil(t, NamedLogger(nil, "demux"))
+}
@@ -9,13 +9,31 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/lifecycle"
 	"github.com/yahoo/panoptes-stream/telemetry"
 )
 
 // Factory is a function that returns a new instance of database
-type Factory func(context.Context, config.Database, *zap.Logger, telemetry.ExtDSChan) Database
+type Factory func(config.Database, *zap.Logger, telemetry.ExtDSChan) Database
 
 // Database represents a database
 type Database interface {
-	Start()
+	// Start runs the database until the context is canceled or
+	// Stop is called, it returns an error if the database fails.
+	Start(context.Context) error
+	// Stop stops the database after the buffered data is written.
+	Stop()
+	// Flush writes the buffered data.
+	Flush() error
+	// Health returns nil if the database is healthy.
+	Health() error
+	// Stats returns the database statistics.
+	Stats() lifecycle.Stats
+}
+
+// Reloader is implemented by the databases that apply a configuration
+// change without reconnecting, Reload returns an error if the change
+// requires a new instance.
+type Reloader interface {
+	Reload(config.Database) error
 }
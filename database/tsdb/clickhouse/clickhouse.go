@@ -0,0 +1,457 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package clickhouse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"go.uber.org/zap"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/database"
+	"github.com/yahoo/panoptes-stream/lifecycle"
+	"github.com/yahoo/panoptes-stream/secret"
+	"github.com/yahoo/panoptes-stream/status"
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+var defaultColumns = columns{
+	Timestamp:   "timestamp",
+	SystemID:    "system_id",
+	Prefix:      "prefix",
+	Key:         "key",
+	Labels:      "labels",
+	ValueType:   "value_type",
+	ValueInt:    "value_int",
+	ValueUint:   "value_uint",
+	ValueFloat:  "value_float",
+	ValueBool:   "value_bool",
+	ValueString: "value_string",
+}
+
+// ClickHouse represents ClickHouse.
+type ClickHouse struct {
+	lifecycle.Database
+
+	ch      telemetry.ExtDSChan
+	logger  *zap.Logger
+	metrics *status.OutputMetrics
+	cfg     config.Database
+
+	client   *http.Client
+	username string
+	password string
+}
+
+type clickHouseConfig struct {
+	Server        string
+	Format        string
+	Database      string
+	Table         string
+	Columns       columns
+	BatchSize     int
+	FlushInterval int
+	Timeout       int
+	MaxRetries    int
+	Username      string
+	Password      string
+
+	CreateTable bool
+	Engine      string
+	PartitionBy string
+	OrderBy     string
+	TTL         int
+
+	AsyncInsert            bool
+	WaitForAsyncInsert     bool
+	AsyncInsertBusyTimeout int
+	AsyncInsertMaxDataSize int
+	Settings               map[string]string
+
+	TLSConfig config.TLSConfig
+}
+
+// statusError represents ClickHouse exception response.
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("clickhouse: status code %d: %s", e.code, e.body)
+}
+
+// New returns a new clickhouse instance.
+func New(cfg config.Database, lg *zap.Logger, inChan telemetry.ExtDSChan) database.Database {
+	return &ClickHouse{
+		cfg:     cfg,
+		ch:      inChan,
+		logger:  lg,
+		metrics: status.NewOutputMetrics(cfg.Name),
+	}
+}
+
+// Start starts clickhouse ingestion.
+func (c *ClickHouse) Start(ctx context.Context) error {
+	return c.Run(ctx, c.start)
+}
+
+func (c *ClickHouse) start(ctx context.Context) error {
+	var flush bool
+
+	config, err := c.getConfig()
+	if err != nil {
+		return err
+	}
+
+	if err = c.setClient(config); err != nil {
+		return err
+	}
+
+	if config.CreateTable {
+		if err = c.exec(ctx, config, getCreateTable(config), nil, nil); err != nil {
+			return fmt.Errorf("create table: %v", err)
+		}
+	}
+
+	c.logger.Info("clickhouse", zap.String("name", c.cfg.Name), zap.String("server", config.Server),
+		zap.String("format", config.Format), zap.String("table", config.Database+"."+config.Table))
+
+	query := getInsert(config)
+	settings := getSettings(config)
+
+	buf := new(bytes.Buffer)
+	batch := make([]row, 0, config.BatchSize)
+	w := &lifecycle.Writer{
+		Lifecycle:  &c.Lifecycle,
+		Metrics:    c.metrics,
+		MaxRetries: config.MaxRetries,
+		Permanent:  isClientError,
+		OnError: func(err error) {
+			c.logger.Error("clickhouse", zap.String("event", "insert"), zap.Error(err))
+		},
+	}
+	insert := func(ctx context.Context) (int, error) {
+		return c.insert(ctx, config, buf, query, settings, batch)
+	}
+
+	flushTicker := time.NewTicker(time.Duration(config.FlushInterval) * time.Second)
+	defer flushTicker.Stop()
+
+	for {
+		select {
+		case v, ok := <-c.ch:
+			if !ok {
+				// the channel is closed and drained
+				w.Write(ctx, len(batch), insert)
+				return nil
+			}
+
+			c.metrics.DataIn.Inc()
+
+			r, err := getRow(&config.Columns, v.DS)
+			if err != nil {
+				c.logger.Error("clickhouse", zap.Error(err), zap.String("output", v.Output))
+				c.AddDropped(1)
+				c.metrics.Error(status.ErrorInvalid)
+				continue
+			}
+
+			batch = append(batch, r)
+
+		case req := <-c.ReloadRequest():
+			cfg := req.Database()
+			conf, err := (&ClickHouse{cfg: cfg}).getConfig()
+			if err == nil {
+				err = lifecycle.Reloadable(config, conf, lifecycle.BatchFields...)
+			}
+			if err == nil {
+				c.cfg, config, w.MaxRetries = cfg, conf, conf.MaxRetries
+				flushTicker.Reset(time.Duration(config.FlushInterval) * time.Second)
+			}
+			req.Reply(err)
+
+		case <-flushTicker.C:
+			if len(batch) > 0 {
+				flush = true
+			} else {
+				continue
+			}
+
+		case req := <-c.FlushRequest():
+			req <- w.Write(ctx, len(batch), insert)
+			flush = false
+			if ctx.Err() == nil {
+				batch = batch[:0]
+			}
+			continue
+
+		case <-ctx.Done():
+			c.logger.Info("clickhouse", zap.String("event", "terminate"), zap.String("name", c.cfg.Name))
+			w.Final(len(batch), insert)
+			return nil
+		}
+
+		if len(batch) >= config.BatchSize || flush {
+			w.Write(ctx, len(batch), insert)
+			flush = false
+			if ctx.Err() == nil {
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// insert inserts the batch and returns the request body size.
+func (c *ClickHouse) insert(ctx context.Context, config *clickHouseConfig, buf *bytes.Buffer, query string, settings url.Values, batch []row) (int, error) {
+	buf.Reset()
+	cols := config.Columns.list()
+	if config.Format == "native" {
+		writeNative(buf, cols, batch)
+	} else if err := writeJSONEachRow(buf, cols, batch); err != nil {
+		return 0, err
+	}
+
+	n := buf.Len()
+
+	return n, c.exec(ctx, config, query, settings, buf)
+}
+
+// exec sends the query through ClickHouse HTTP interface,
+// the body is the insert data if it's available.
+func (c *ClickHouse) exec(ctx context.Context, config *clickHouseConfig, query string, settings url.Values, body io.Reader) error {
+	params := url.Values{}
+	for k, v := range settings {
+		params[k] = v
+	}
+	params.Set("database", config.Database)
+
+	if body == nil {
+		body = strings.NewReader(query)
+	} else {
+		params.Set("query", query)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(config.Server, "/")+"/?"+params.Encode(), body)
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+	if c.username != "" {
+		req.Header.Set("X-ClickHouse-User", c.username)
+		req.Header.Set("X-ClickHouse-Key", c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil
+	}
+
+	b, _ := ioutil.ReadAll(resp.Body)
+
+	return &statusError{code: resp.StatusCode, body: strings.TrimSpace(string(b))}
+}
+
+// isClientError reports whether the insert failed with a
+// client error (e.g. bad data), it doesn't need to retry.
+func isClientError(err error) bool {
+	var e *statusError
+	return errors.As(err, &e) && e.code >= 400 && e.code < 500
+}
+
+func (c *ClickHouse) setClient(config *clickHouseConfig) error {
+	var err error
+
+	c.client = &http.Client{
+		Timeout: time.Duration(config.Timeout) * time.Second,
+	}
+
+	if config.TLSConfig.Enabled {
+		tlsConfig, err := secret.GetTLSConfig(&config.TLSConfig)
+		if err != nil {
+			return err
+		}
+
+		c.client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	}
+
+	c.username, c.password, err = secret.GetUsernamePassword(config.Username, config.Password)
+
+	return err
+}
+
+// getCreateTable returns the idempotent create table statement.
+func getCreateTable(config *clickHouseConfig) string {
+	buf := new(bytes.Buffer)
+
+	fmt.Fprintf(buf, "CREATE TABLE IF NOT EXISTS %s (", quoteIdentifier(config.Table))
+	for i, c := range config.Columns.list() {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(buf, "%s %s", quoteIdentifier(c.name), c.typeName)
+	}
+	fmt.Fprintf(buf, ") ENGINE = %s", config.Engine)
+
+	if config.PartitionBy != "" {
+		fmt.Fprintf(buf, " PARTITION BY %s", config.PartitionBy)
+	}
+
+	fmt.Fprintf(buf, " ORDER BY %s", config.OrderBy)
+
+	if config.TTL > 0 {
+		fmt.Fprintf(buf, " TTL toDateTime(%s) + INTERVAL %d DAY", config.Columns.Timestamp, config.TTL)
+	}
+
+	return buf.String()
+}
+
+func getInsert(config *clickHouseConfig) string {
+	var names []string
+	for _, c := range config.Columns.list() {
+		names = append(names, quoteIdentifier(c.name))
+	}
+
+	format := "JSONEachRow"
+	if config.Format == "native" {
+		format = "Native"
+	}
+
+	return fmt.Sprintf("INSERT INTO %s (%s) FORMAT %s", quoteIdentifier(config.Table), strings.Join(names, ", "), format)
+}
+
+// getSettings returns the query settings including async insert tuning.
+func getSettings(config *clickHouseConfig) url.Values {
+	settings := url.Values{}
+	for k, v := range config.Settings {
+		settings.Set(k, v)
+	}
+
+	if config.AsyncInsert {
+		settings.Set("async_insert", "1")
+		settings.Set("wait_for_async_insert", boolSetting(config.WaitForAsyncInsert))
+		if config.AsyncInsertBusyTimeout > 0 {
+			settings.Set("async_insert_busy_timeout_ms", strconv.Itoa(config.AsyncInsertBusyTimeout))
+		}
+		if config.AsyncInsertMaxDataSize > 0 {
+			settings.Set("async_insert_max_data_size", strconv.Itoa(config.AsyncInsertMaxDataSize))
+		}
+	}
+
+	return settings
+}
+
+func boolSetting(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+func quoteIdentifier(s string) string {
+	return "`" + strings.Replace(s, "`", "\\`", -1) + "`"
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (c *ClickHouse) getConfig() (*clickHouseConfig, error) {
+	conf := new(clickHouseConfig)
+	b, err := json.Marshal(c.cfg.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(b, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := "panoptes_database_" + c.cfg.Name
+	err = envconfig.Process(prefix, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	config.SetDefault(&conf.BatchSize, 10000)
+	config.SetDefault(&conf.FlushInterval, 1)
+	config.SetDefault(&conf.Timeout, 10)
+	config.SetDefault(&conf.MaxRetries, 2)
+
+	if conf.Database == "" {
+		conf.Database = "default"
+	}
+
+	if conf.Table == "" {
+		conf.Table = "panoptes"
+	}
+
+	if conf.Engine == "" {
+		conf.Engine = "MergeTree"
+	}
+
+	if conf.Columns == (columns{}) {
+		conf.Columns = defaultColumns
+	}
+
+	if conf.Columns.Timestamp == "" {
+		return nil, errors.New("timestamp column is required")
+	}
+
+	if conf.PartitionBy == "" {
+		conf.PartitionBy = fmt.Sprintf("toYYYYMM(%s)", conf.Columns.Timestamp)
+	}
+
+	if conf.OrderBy == "" {
+		var keys []string
+		c := conf.Columns
+		for _, name := range []string{c.SystemID, c.Prefix, c.Key, c.Timestamp} {
+			if name != "" {
+				keys = append(keys, name)
+			}
+		}
+		conf.OrderBy = "(" + strings.Join(keys, ", ") + ")"
+	}
+
+	switch conf.Format {
+	case "":
+		conf.Format = "json"
+	case "json", "native":
+	default:
+		return nil, fmt.Errorf("format %s not supported", conf.Format)
+	}
+
+	if conf.Server == "" {
+		return nil, errors.New("server is empty")
+	}
+
+	return conf, nil
+}
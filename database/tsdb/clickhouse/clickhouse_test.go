@@ -0,0 +1,238 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package clickhouse
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+func TestGetRow(t *testing.T) {
+	testCases := []struct {
+		value    interface{}
+		expected row
+	}{
+		{int32(-1), row{ValueType: "int", ValueInt: -1}},
+		{uint64(1), row{ValueType: "uint", ValueUint: 1}},
+		{1.5, row{ValueType: "float", ValueFloat: 1.5}},
+		{true, row{ValueType: "bool", ValueBool: 1}},
+		{"UP", row{ValueType: "string", ValueString: "UP"}},
+		{[]interface{}{"a", "b"}, row{ValueType: "json", ValueString: `["a","b"]`}},
+	}
+
+	c := &defaultColumns
+	ds := telemetry.DataStore{
+		"prefix":    "/interfaces/interface/state/counters/",
+		"labels":    map[string]string{"name": "Ethernet3"},
+		"system_id": "core1.bur",
+		"timestamp": int64(1595768623436661269),
+		"key":       "k",
+	}
+
+	for _, tc := range testCases {
+		ds["value"] = tc.value
+		r, err := getRow(c, ds)
+		require.NoError(t, err)
+
+		tc.expected.Timestamp = 1595768623436661269
+		tc.expected.SystemID = "core1.bur"
+		tc.expected.Prefix = "/interfaces/interface/state/counters/"
+		tc.expected.Key = "k"
+		tc.expected.Labels = map[string]string{"name": "Ethernet3"}
+		assert.Equal(t, tc.expected, r)
+	}
+
+	ds["value"] = nil
+	_, err := getRow(c, ds)
+	assert.Error(t, err)
+
+	// without value string column
+	ds["value"] = "UP"
+	_, err = getRow(&columns{Timestamp: "timestamp", ValueInt: "value"}, ds)
+	assert.Error(t, err)
+}
+
+func TestJSONEachRow(t *testing.T) {
+	buf := new(bytes.Buffer)
+	r, _ := getRow(&defaultColumns, telemetry.DataStore{
+		"prefix":    "/interfaces/interface/state/counters/",
+		"labels":    map[string]string{"name": "Ethernet3"},
+		"system_id": "core1.bur",
+		"timestamp": int64(1595768623436661269),
+		"key":       "in-octets",
+		"value":     uint64(10),
+	})
+
+	err := writeJSONEachRow(buf, defaultColumns.list(), []row{r})
+	require.NoError(t, err)
+	assert.Equal(t, `{"timestamp":"2020-07-26 13:03:43.436661269","system_id":"core1.bur","prefix":"/interfaces/interface/state/counters/",`+
+		`"key":"in-octets","labels":{"name":"Ethernet3"},"value_type":"uint","value_int":0,"value_uint":10,"value_float":0,"value_bool":0,"value_string":""}`+"\n", buf.String())
+
+	// custom columns
+	buf.Reset()
+	c := &columns{Timestamp: "ts", SystemID: "host", Key: "metric", ValueUint: "value"}
+	err = writeJSONEachRow(buf, c.list(), []row{r})
+	require.NoError(t, err)
+	assert.Equal(t, `{"ts":"2020-07-26 13:03:43.436661269","host":"core1.bur","metric":"in-octets","value":10}`+"\n", buf.String())
+}
+
+func TestNative(t *testing.T) {
+	buf := new(bytes.Buffer)
+	writeNative(buf, defaultColumns.list(), []row{
+		{Timestamp: 1, SystemID: "a", Labels: map[string]string{"y": "2", "x": "1"}, ValueType: "int", ValueInt: -1},
+		{Timestamp: 2, Labels: map[string]string{}, ValueType: "bool", ValueBool: 1},
+	})
+
+	b := buf.Bytes()
+	// 11 columns, 2 rows
+	assert.Equal(t, []byte{11, 2}, b[:2])
+
+	col := func(name, typeName string) []byte {
+		return append(append([]byte{byte(len(name))}, name...), append([]byte{byte(len(typeName))}, typeName...)...)
+	}
+
+	expected := col("timestamp", "DateTime64(9, 'UTC')")
+	expected = append(expected, 1, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0)
+	expected = append(expected, col("system_id", "String")...)
+	expected = append(expected, 1, 'a', 0)
+	assert.True(t, bytes.HasPrefix(b[2:], expected))
+
+	expected = col("labels", "Map(String, String)")
+	// offsets, keys and values
+	expected = append(expected, 2, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0)
+	expected = append(expected, 1, 'x', 1, 'y', 1, '1', 1, '2')
+	assert.True(t, bytes.Contains(b, expected))
+
+	expected = col("value_int", "Int64")
+	expected = append(expected, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0)
+	assert.True(t, bytes.Contains(b, expected))
+
+	expected = col("value_bool", "UInt8")
+	expected = append(expected, 0, 1)
+	assert.True(t, bytes.Contains(b, expected))
+}
+
+func TestStart(t *testing.T) {
+	done := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var tableCreated bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "panoptes", r.URL.Query().Get("database"))
+		assert.Equal(t, "user", r.Header.Get("X-ClickHouse-User"))
+		assert.Equal(t, "pass", r.Header.Get("X-ClickHouse-Key"))
+
+		query := r.URL.Query().Get("query")
+		if query == "" {
+			body, _ := ioutil.ReadAll(r.Body)
+			assert.True(t, strings.HasPrefix(string(body), "CREATE TABLE IF NOT EXISTS `telemetry` (`timestamp` DateTime64(9, 'UTC'), "))
+			assert.True(t, strings.HasSuffix(string(body), "TTL toDateTime(timestamp) + INTERVAL 30 DAY"))
+			tableCreated = true
+			return
+		}
+
+		assert.True(t, tableCreated)
+		assert.True(t, strings.HasPrefix(query, "INSERT INTO `telemetry` (`timestamp`, `system_id`"))
+		assert.True(t, strings.HasSuffix(query, "FORMAT JSONEachRow"))
+		assert.Equal(t, "1", r.URL.Query().Get("async_insert"))
+		assert.Equal(t, "0", r.URL.Query().Get("wait_for_async_insert"))
+		assert.Equal(t, "200", r.URL.Query().Get("async_insert_busy_timeout_ms"))
+
+		var keys []string
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			m := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &m))
+			keys = append(keys, m["key"].(string))
+		}
+		assert.Equal(t, []string{"in-octets", "out-octets"}, keys)
+
+		close(done)
+	}))
+	defer server.Close()
+
+	cfg := config.NewMockConfig()
+	ch := make(telemetry.ExtDSChan, 10)
+
+	dbCfg := config.Database{Name: "clickhouse1", Service: "clickhouse", Config: map[string]interface{}{
+		"server":                 server.URL,
+		"database":               "panoptes",
+		"table":                  "telemetry",
+		"batchSize":              2,
+		"username":               "user",
+		"password":               "pass",
+		"createTable":            true,
+		"ttl":                    30,
+		"asyncInsert":            true,
+		"asyncInsertBusyTimeout": 200,
+	}}
+
+	db := New(dbCfg, cfg.Logger(), ch)
+	go db.Start(ctx)
+
+	for _, v := range []telemetry.DataStore{
+		{"prefix": "/interfaces/interface/state/counters/", "system_id": "core1.bur", "timestamp": 1595768623436661269, "key": "in-octets", "value": 10},
+		{"prefix": "/interfaces/interface/state/counters/", "system_id": "core1.bur", "timestamp": 1595768623436661269, "key": "out-octets", "value": 20},
+	} {
+		ch <- telemetry.ExtDataStore{Output: "clickhouse1::ifcounters", DS: v}
+	}
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Error("time limit exceeded")
+	}
+}
+
+func TestConfig(t *testing.T) {
+	c := &ClickHouse{cfg: config.Database{Name: "clickhouse1", Config: map[string]interface{}{
+		"server": "http://127.0.0.1:8123",
+	}}}
+
+	conf, err := c.getConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "json", conf.Format)
+	assert.Equal(t, "default", conf.Database)
+	assert.Equal(t, "panoptes", conf.Table)
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS `panoptes` (`timestamp` DateTime64(9, 'UTC'), `system_id` String, `prefix` String, "+
+		"`key` String, `labels` Map(String, String), `value_type` String, `value_int` Int64, `value_uint` UInt64, `value_float` Float64, "+
+		"`value_bool` UInt8, `value_string` String) ENGINE = MergeTree PARTITION BY toYYYYMM(timestamp) ORDER BY (system_id, prefix, key, timestamp)",
+		getCreateTable(conf))
+
+	c.cfg.Config = map[string]interface{}{"server": "http://127.0.0.1:8123", "format": "csv"}
+	_, err = c.getConfig()
+	assert.Error(t, err)
+
+	// custom columns
+	c.cfg.Config = map[string]interface{}{
+		"server":  "http://127.0.0.1:8123",
+		"ttl":     7,
+		"columns": map[string]interface{}{"timestamp": "ts", "systemId": "host", "key": "metric", "valueFloat": "value"},
+	}
+	conf, err = c.getConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS `panoptes` (`ts` DateTime64(9, 'UTC'), `host` String, `metric` String, `value` Float64) "+
+		"ENGINE = MergeTree PARTITION BY toYYYYMM(ts) ORDER BY (host, metric, ts) TTL toDateTime(ts) + INTERVAL 7 DAY", getCreateTable(conf))
+	assert.Equal(t, "INSERT INTO `panoptes` (`ts`, `host`, `metric`, `value`) FORMAT JSONEachRow", getInsert(conf))
+
+	c.cfg.Config = map[string]interface{}{"server": "http://127.0.0.1:8123", "columns": map[string]interface{}{"key": "metric"}}
+	_, err = c.getConfig()
+	assert.Error(t, err)
+}
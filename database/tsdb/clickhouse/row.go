@@ -0,0 +1,347 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package clickhouse
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+// columns represents the column mapping, an empty
+// column isn't written.
+type columns struct {
+	Timestamp   string
+	SystemID    string `split_words:"true"`
+	Prefix      string
+	Key         string
+	Labels      string
+	ValueType   string `split_words:"true"`
+	ValueInt    string `split_words:"true"`
+	ValueUint   string `split_words:"true"`
+	ValueFloat  string `split_words:"true"`
+	ValueBool   string `split_words:"true"`
+	ValueString string `split_words:"true"`
+}
+
+// column represents a table column, the native format
+// type must be identical to the table column type.
+type column struct {
+	name     string
+	typeName string
+	field    int
+}
+
+const (
+	fieldTimestamp = iota
+	fieldSystemID
+	fieldPrefix
+	fieldKey
+	fieldLabels
+	fieldValueType
+	fieldValueInt
+	fieldValueUint
+	fieldValueFloat
+	fieldValueBool
+	fieldValueString
+)
+
+// list returns the written columns.
+func (c *columns) list() []column {
+	var list []column
+	for _, col := range []column{
+		{c.Timestamp, "DateTime64(9, 'UTC')", fieldTimestamp},
+		{c.SystemID, "String", fieldSystemID},
+		{c.Prefix, "String", fieldPrefix},
+		{c.Key, "String", fieldKey},
+		{c.Labels, "Map(String, String)", fieldLabels},
+		{c.ValueType, "String", fieldValueType},
+		{c.ValueInt, "Int64", fieldValueInt},
+		{c.ValueUint, "UInt64", fieldValueUint},
+		{c.ValueFloat, "Float64", fieldValueFloat},
+		{c.ValueBool, "UInt8", fieldValueBool},
+		{c.ValueString, "String", fieldValueString},
+	} {
+		if col.name != "" {
+			list = append(list, col)
+		}
+	}
+
+	return list
+}
+
+// valueColumn returns the column name of the value type.
+func (c *columns) valueColumn(valueType string) string {
+	switch valueType {
+	case "int":
+		return c.ValueInt
+	case "uint":
+		return c.ValueUint
+	case "float":
+		return c.ValueFloat
+	case "bool":
+		return c.ValueBool
+	default:
+		return c.ValueString
+	}
+}
+
+// row represents a table row, only the value column
+// that the value type points to is set.
+type row struct {
+	Timestamp   int64
+	SystemID    string
+	Prefix      string
+	Key         string
+	Labels      map[string]string
+	ValueType   string
+	ValueInt    int64
+	ValueUint   uint64
+	ValueFloat  float64
+	ValueBool   uint8
+	ValueString string
+}
+
+// getRow returns the table row of the datastore, the value
+// type column must be available.
+func getRow(c *columns, ds telemetry.DataStore) (row, error) {
+	r := row{}
+
+	switch v := ds["timestamp"].(type) {
+	case int64:
+		r.Timestamp = v
+	case int:
+		r.Timestamp = int64(v)
+	case uint64:
+		r.Timestamp = int64(v)
+	default:
+		return r, fmt.Errorf("invalid timestamp %v", ds["timestamp"])
+	}
+
+	r.SystemID, _ = ds["system_id"].(string)
+	r.Prefix, _ = ds["prefix"].(string)
+	r.Key, _ = ds["key"].(string)
+	r.Labels, _ = ds["labels"].(map[string]string)
+	if r.Labels == nil {
+		r.Labels = map[string]string{}
+	}
+
+	switch v := ds["value"].(type) {
+	case int64:
+		r.ValueType, r.ValueInt = "int", v
+	case int32:
+		r.ValueType, r.ValueInt = "int", int64(v)
+	case int16:
+		r.ValueType, r.ValueInt = "int", int64(v)
+	case int8:
+		r.ValueType, r.ValueInt = "int", int64(v)
+	case int:
+		r.ValueType, r.ValueInt = "int", int64(v)
+	case uint64:
+		r.ValueType, r.ValueUint = "uint", v
+	case uint32:
+		r.ValueType, r.ValueUint = "uint", uint64(v)
+	case uint16:
+		r.ValueType, r.ValueUint = "uint", uint64(v)
+	case uint8:
+		r.ValueType, r.ValueUint = "uint", uint64(v)
+	case uint:
+		r.ValueType, r.ValueUint = "uint", uint64(v)
+	case float64:
+		r.ValueType, r.ValueFloat = "float", v
+	case float32:
+		r.ValueType, r.ValueFloat = "float", float64(v)
+	case bool:
+		r.ValueType = "bool"
+		if v {
+			r.ValueBool = 1
+		}
+	case string:
+		r.ValueType, r.ValueString = "string", v
+	case []byte:
+		r.ValueType, r.ValueString = "string", string(v)
+	case []interface{}, map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return r, err
+		}
+		r.ValueType, r.ValueString = "json", string(b)
+	default:
+		return r, fmt.Errorf("unsupported value type %T", v)
+	}
+
+	if c.valueColumn(r.ValueType) == "" {
+		return r, fmt.Errorf("%s value column isn't available", r.ValueType)
+	}
+
+	return r, nil
+}
+
+// writeJSONEachRow writes the rows in JSONEachRow format,
+// the timestamp writes as string in UTC.
+func writeJSONEachRow(buf *bytes.Buffer, cols []column, rows []row) error {
+	names := make([][]byte, len(cols))
+	for i, c := range cols {
+		names[i], _ = json.Marshal(c.name)
+	}
+
+	for _, r := range rows {
+		buf.WriteByte('{')
+		for i, c := range cols {
+			var v interface{}
+
+			switch c.field {
+			case fieldTimestamp:
+				v = time.Unix(0, r.Timestamp).UTC().Format("2006-01-02 15:04:05.000000000")
+			case fieldSystemID:
+				v = r.SystemID
+			case fieldPrefix:
+				v = r.Prefix
+			case fieldKey:
+				v = r.Key
+			case fieldLabels:
+				v = r.Labels
+			case fieldValueType:
+				v = r.ValueType
+			case fieldValueInt:
+				v = r.ValueInt
+			case fieldValueUint:
+				v = r.ValueUint
+			case fieldValueFloat:
+				v = r.ValueFloat
+			case fieldValueBool:
+				v = r.ValueBool
+			case fieldValueString:
+				v = r.ValueString
+			}
+
+			b, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(names[i])
+			buf.WriteByte(':')
+			buf.Write(b)
+		}
+		buf.WriteString("}\n")
+	}
+
+	return nil
+}
+
+// writeNative writes the rows as a block in ClickHouse Native format:
+// number of columns, number of rows and the column name, type and
+// data for each column.
+func writeNative(buf *bytes.Buffer, cols []column, rows []row) {
+	w := &nativeWriter{buf: buf}
+
+	w.uvarint(uint64(len(cols)))
+	w.uvarint(uint64(len(rows)))
+
+	for _, c := range cols {
+		w.string(c.name)
+		w.string(c.typeName)
+
+		switch c.field {
+		case fieldTimestamp:
+			for _, r := range rows {
+				w.uint64(uint64(r.Timestamp))
+			}
+		case fieldSystemID:
+			for _, r := range rows {
+				w.string(r.SystemID)
+			}
+		case fieldPrefix:
+			for _, r := range rows {
+				w.string(r.Prefix)
+			}
+		case fieldKey:
+			for _, r := range rows {
+				w.string(r.Key)
+			}
+		case fieldLabels:
+			w.labels(rows)
+		case fieldValueType:
+			for _, r := range rows {
+				w.string(r.ValueType)
+			}
+		case fieldValueInt:
+			for _, r := range rows {
+				w.uint64(uint64(r.ValueInt))
+			}
+		case fieldValueUint:
+			for _, r := range rows {
+				w.uint64(r.ValueUint)
+			}
+		case fieldValueFloat:
+			for _, r := range rows {
+				w.uint64(math.Float64bits(r.ValueFloat))
+			}
+		case fieldValueBool:
+			for _, r := range rows {
+				buf.WriteByte(r.ValueBool)
+			}
+		case fieldValueString:
+			for _, r := range rows {
+				w.string(r.ValueString)
+			}
+		}
+	}
+}
+
+type nativeWriter struct {
+	buf     *bytes.Buffer
+	scratch [binary.MaxVarintLen64]byte
+}
+
+func (w *nativeWriter) uvarint(v uint64) {
+	n := binary.PutUvarint(w.scratch[:], v)
+	w.buf.Write(w.scratch[:n])
+}
+
+func (w *nativeWriter) uint64(v uint64) {
+	binary.LittleEndian.PutUint64(w.scratch[:8], v)
+	w.buf.Write(w.scratch[:8])
+}
+
+func (w *nativeWriter) string(s string) {
+	w.uvarint(uint64(len(s)))
+	w.buf.WriteString(s)
+}
+
+// labels writes the map column as Array(Tuple(String, String)):
+// the cumulative offsets, all the keys and all the values.
+func (w *nativeWriter) labels(rows []row) {
+	var (
+		offset uint64
+		keys   = make([][]string, len(rows))
+	)
+
+	for i, r := range rows {
+		keys[i] = sortedKeys(r.Labels)
+		offset += uint64(len(keys[i]))
+		w.uint64(offset)
+	}
+
+	for i := range rows {
+		for _, k := range keys[i] {
+			w.string(k)
+		}
+	}
+
+	for i, r := range rows {
+		for _, k := range keys[i] {
+			w.string(r.Labels[k])
+		}
+	}
+}
@@ -0,0 +1,335 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package graphite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"go.uber.org/zap"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/database"
+	"github.com/yahoo/panoptes-stream/database/tsdb/metric"
+	"github.com/yahoo/panoptes-stream/lifecycle"
+	"github.com/yahoo/panoptes-stream/status"
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+var (
+	nodeReplacer    = strings.NewReplacer(".", "_", "/", "_")
+	pathReplacer    = strings.NewReplacer(" ", "_", "\t", "_", "\n", "_", "\r", "_")
+	tagReplacer     = strings.NewReplacer(" ", "_", "\t", "_", "\n", "_", "\r", "_", ";", "_", "~", "_")
+	tagNameReplacer = strings.NewReplacer(" ", "_", "\t", "_", "\n", "_", "\r", "_", ";", "_", "!", "_", "^", "_", "=", "_")
+	defaultTemplate = "{system_id}.{prefix}.{key}"
+	defaultPorts    = map[string]string{"plaintext": "2003", "pickle": "2004"}
+)
+
+// Graphite represents Graphite.
+type Graphite struct {
+	lifecycle.Database
+
+	ch      telemetry.ExtDSChan
+	logger  *zap.Logger
+	metrics *status.OutputMetrics
+	cfg     config.Database
+
+	conn net.Conn
+}
+
+type graphiteConfig struct {
+	Server        string
+	Protocol      string
+	Template      string
+	Tags          bool
+	BatchSize     int
+	FlushInterval int
+	Timeout       int
+	MaxRetries    int
+}
+
+type point struct {
+	path      string
+	value     interface{}
+	timestamp int64
+}
+
+// New returns a new graphite instance.
+func New(cfg config.Database, lg *zap.Logger, inChan telemetry.ExtDSChan) database.Database {
+	return &Graphite{
+		cfg:     cfg,
+		ch:      inChan,
+		logger:  lg,
+		metrics: status.NewOutputMetrics(cfg.Name),
+	}
+}
+
+// Start starts graphite ingestion.
+func (g *Graphite) Start(ctx context.Context) error {
+	return g.Run(ctx, g.start)
+}
+
+func (g *Graphite) start(ctx context.Context) error {
+	var flush bool
+
+	config, err := g.getConfig()
+	if err != nil {
+		return err
+	}
+
+	tpl, err := metric.ParseTemplate(config.Template)
+	if err != nil {
+		return err
+	}
+
+	g.logger.Info("graphite", zap.String("name", g.cfg.Name), zap.String("server", config.Server), zap.String("protocol", config.Protocol))
+
+	buf := new(bytes.Buffer)
+	wBuf := new(bytes.Buffer)
+	batch := make([]point, 0, config.BatchSize)
+	w := &lifecycle.Writer{
+		Lifecycle:  &g.Lifecycle,
+		Metrics:    g.metrics,
+		MaxRetries: config.MaxRetries,
+		OnError: func(err error) {
+			g.logger.Error("graphite", zap.String("event", "write"), zap.Error(err))
+			g.close()
+		},
+	}
+	write := func(context.Context) (int, error) {
+		err := g.write(config, wBuf, batch)
+		return wBuf.Len(), err
+	}
+
+	flushTicker := time.NewTicker(time.Duration(config.FlushInterval) * time.Second)
+	defer flushTicker.Stop()
+
+	for {
+		select {
+		case v, ok := <-g.ch:
+			if !ok {
+				// the channel is closed and drained
+				w.Write(ctx, len(batch), write)
+				return nil
+			}
+
+			g.metrics.DataIn.Inc()
+
+			p, err := getPoint(buf, tpl, config.Tags, v)
+			if err != nil {
+				g.logger.Error("graphite", zap.Error(err), zap.String("output", v.Output))
+				g.AddDropped(1)
+				g.metrics.Error(status.ErrorInvalid)
+				continue
+			}
+
+			batch = append(batch, p)
+
+		case req := <-g.ReloadRequest():
+			cfg := req.Database()
+			conf, err := (&Graphite{cfg: cfg}).getConfig()
+			if err == nil {
+				err = lifecycle.Reloadable(config, conf, lifecycle.BatchFields...)
+			}
+			if err == nil {
+				g.cfg, config, w.MaxRetries = cfg, conf, conf.MaxRetries
+				flushTicker.Reset(time.Duration(config.FlushInterval) * time.Second)
+			}
+			req.Reply(err)
+
+		case <-flushTicker.C:
+			if len(batch) > 0 {
+				flush = true
+			} else {
+				continue
+			}
+
+		case req := <-g.FlushRequest():
+			req <- w.Write(ctx, len(batch), write)
+			flush = false
+			if ctx.Err() == nil {
+				batch = batch[:0]
+			}
+			continue
+
+		case <-ctx.Done():
+			g.logger.Info("graphite", zap.String("event", "terminate"), zap.String("name", g.cfg.Name))
+			w.Final(len(batch), write)
+			g.close()
+			return nil
+		}
+
+		if len(batch) >= config.BatchSize || flush {
+			w.Write(ctx, len(batch), write)
+			flush = false
+			if ctx.Err() == nil {
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+func (g *Graphite) write(config *graphiteConfig, buf *bytes.Buffer, batch []point) error {
+	buf.Reset()
+	if config.Protocol == "pickle" {
+		writePickle(buf, batch)
+	} else {
+		writePlaintext(buf, batch)
+	}
+
+	if g.conn == nil {
+		conn, err := net.DialTimeout("tcp", config.Server, time.Duration(config.Timeout)*time.Second)
+		if err != nil {
+			return err
+		}
+		g.conn = conn
+	}
+
+	g.conn.SetWriteDeadline(time.Now().Add(time.Duration(config.Timeout) * time.Second))
+	_, err := g.conn.Write(buf.Bytes())
+
+	return err
+}
+
+func (g *Graphite) close() {
+	if g.conn != nil {
+		g.conn.Close()
+		g.conn = nil
+	}
+}
+
+// getPoint returns the point based on the template, the labels
+// appends to the path as graphite tags if tags enabled.
+func getPoint(buf *bytes.Buffer, tpl *metric.Template, tags bool, v telemetry.ExtDataStore) (point, error) {
+	value, ok := metric.Value(v.DS["value"])
+	if !ok {
+		return point{}, fmt.Errorf("unsupported value type %T", v.DS["value"])
+	}
+
+	timestamp, ok := metric.Timestamp(v.DS["timestamp"], time.Second)
+	if !ok {
+		return point{}, fmt.Errorf("invalid timestamp %v", v.DS["timestamp"])
+	}
+
+	buf.Reset()
+	if err := tpl.Execute(buf, metric.Topic(v.Output), v.DS, sanitize); err != nil {
+		return point{}, err
+	}
+
+	if tags {
+		writeTags(buf, v.DS)
+	}
+
+	return point{
+		path:      buf.String(),
+		value:     value,
+		timestamp: timestamp,
+	}, nil
+}
+
+// sanitize converts the prefix and key paths to graphite
+// path (slashes replaced by dots) and replaces dots and
+// slashes in the other variables by underscore.
+func sanitize(variable, value string) string {
+	switch variable {
+	case "prefix", "key":
+		value = strings.Replace(strings.Trim(value, "/"), "/", ".", -1)
+	default:
+		value = nodeReplacer.Replace(value)
+	}
+
+	return pathReplacer.Replace(value)
+}
+
+func writeTags(buf *bytes.Buffer, ds telemetry.DataStore) {
+	labels, _ := ds["labels"].(map[string]string)
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if k == "" || labels[k] == "" {
+			continue
+		}
+
+		buf.WriteByte(';')
+		buf.WriteString(tagNameReplacer.Replace(k))
+		buf.WriteByte('=')
+		buf.WriteString(tagReplacer.Replace(labels[k]))
+	}
+}
+
+func writePlaintext(buf *bytes.Buffer, batch []point) {
+	for _, m := range batch {
+		buf.WriteString(m.path)
+		buf.WriteByte(' ')
+		switch v := m.value.(type) {
+		case int64:
+			buf.WriteString(strconv.FormatInt(v, 10))
+		case uint64:
+			buf.WriteString(strconv.FormatUint(v, 10))
+		case float64:
+			buf.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatInt(m.timestamp, 10))
+		buf.WriteByte('\n')
+	}
+}
+
+func (g *Graphite) getConfig() (*graphiteConfig, error) {
+	conf := new(graphiteConfig)
+	b, err := json.Marshal(g.cfg.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(b, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := "panoptes_database_" + g.cfg.Name
+	err = envconfig.Process(prefix, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	config.SetDefault(&conf.BatchSize, 1000)
+	config.SetDefault(&conf.FlushInterval, 1)
+	config.SetDefault(&conf.Timeout, 5)
+	config.SetDefault(&conf.MaxRetries, 2)
+
+	if conf.Template == "" {
+		conf.Template = defaultTemplate
+	}
+
+	switch conf.Protocol {
+	case "":
+		conf.Protocol = "plaintext"
+	case "plaintext", "pickle":
+	default:
+		return nil, fmt.Errorf("protocol %s not supported", conf.Protocol)
+	}
+
+	if conf.Server == "" {
+		return nil, fmt.Errorf("server is empty")
+	}
+
+	if _, _, err := net.SplitHostPort(conf.Server); err != nil {
+		conf.Server = net.JoinHostPort(conf.Server, defaultPorts[conf.Protocol])
+	}
+
+	return conf, nil
+}
@@ -0,0 +1,159 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package graphite
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/database/tsdb/metric"
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+func TestGetPoint(t *testing.T) {
+	buf := new(bytes.Buffer)
+	tpl, err := metric.ParseTemplate("{system_id}.{prefix}.{labels.name}.{key}")
+	require.NoError(t, err)
+
+	v := telemetry.ExtDataStore{
+		Output: "graphite1::ifcounters",
+		DS: telemetry.DataStore{
+			"prefix":    "/interfaces/interface/state/counters/",
+			"labels":    map[string]string{"name": "Ethernet3/1", "description": "to core2; lag"},
+			"system_id": "core1.bur",
+			"timestamp": int64(1595768623436661269),
+			"key":       "in-octets",
+			"value":     10,
+		},
+	}
+	p, err := getPoint(buf, tpl, false, v)
+	require.NoError(t, err)
+	assert.Equal(t, point{path: "core1_bur.interfaces.interface.state.counters.Ethernet3_1.in-octets", value: int64(10), timestamp: 1595768623}, p)
+
+	// tags
+	p, err = getPoint(buf, tpl, true, v)
+	require.NoError(t, err)
+	assert.Equal(t, "core1_bur.interfaces.interface.state.counters.Ethernet3_1.in-octets;description=to_core2__lag;name=Ethernet3/1", p.path)
+
+	// unsupported value
+	v.DS["value"] = "UP"
+	_, err = getPoint(buf, tpl, false, v)
+	assert.Error(t, err)
+}
+
+func TestPlaintext(t *testing.T) {
+	buf := new(bytes.Buffer)
+	writePlaintext(buf, []point{
+		{path: "a.b", value: int64(-1), timestamp: 1},
+		{path: "a.c", value: uint64(math.MaxUint64), timestamp: 2},
+		{path: "a.d", value: 1.5, timestamp: 3},
+	})
+
+	assert.Equal(t, "a.b -1 1\na.c 18446744073709551615 2\na.d 1.5 3\n", buf.String())
+}
+
+func TestPickle(t *testing.T) {
+	buf := new(bytes.Buffer)
+	writePickle(buf, []point{
+		{path: "a", value: 1.5, timestamp: 1},
+		{path: "b", value: uint64(math.MaxUint64), timestamp: 4294967296},
+	})
+
+	expected := []byte{
+		0x80, 2, ']', '(',
+		// ('a', (1, 1.5))
+		'X', 1, 0, 0, 0, 'a', 'J', 1, 0, 0, 0, 'G', 0x3f, 0xf8, 0, 0, 0, 0, 0, 0, 0x86, 0x86,
+		// ('b', (4294967296, 18446744073709551615))
+		'X', 1, 0, 0, 0, 'b', 0x8a, 8, 0, 0, 0, 0, 1, 0, 0, 0, 0x8a, 9, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0, 0x86, 0x86,
+		'e', '.',
+	}
+
+	b := buf.Bytes()
+	assert.Equal(t, uint32(len(expected)), binary.BigEndian.Uint32(b[:4]))
+	assert.Equal(t, expected, b[4:])
+}
+
+func TestStart(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	lines := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			lines <- line
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.NewMockConfig()
+	ch := make(telemetry.ExtDSChan, 10)
+
+	dbCfg := config.Database{Name: "graphite1", Service: "graphite", Config: map[string]interface{}{
+		"server":    ln.Addr().String(),
+		"template":  "panoptes.{system_id}.{topic}.{labels.name}.{key}",
+		"batchSize": 2,
+	}}
+
+	db := New(dbCfg, cfg.Logger(), ch)
+	go db.Start(ctx)
+
+	for _, v := range []telemetry.DataStore{
+		{"prefix": "/interfaces/interface/state/counters/", "labels": map[string]string{"name": "Ethernet3/1"}, "system_id": "core1.bur", "timestamp": 1595768623436661269, "key": "in-octets", "value": 10},
+		{"prefix": "/interfaces/interface/state/counters/", "labels": map[string]string{"name": "Ethernet3/1"}, "system_id": "core1.bur", "timestamp": 1595768623436661269, "key": "out-octets", "value": 1.5},
+	} {
+		ch <- telemetry.ExtDataStore{Output: "graphite1::ifcounters", DS: v}
+	}
+
+	for _, expected := range []string{
+		"panoptes.core1_bur.ifcounters.Ethernet3_1.in-octets 10 1595768623\n",
+		"panoptes.core1_bur.ifcounters.Ethernet3_1.out-octets 1.5 1595768623\n",
+	} {
+		select {
+		case line := <-lines:
+			assert.Equal(t, expected, line)
+		case <-time.After(3 * time.Second):
+			t.Fatal("time limit exceeded")
+		}
+	}
+}
+
+func TestConfig(t *testing.T) {
+	g := &Graphite{cfg: config.Database{Name: "graphite1", Config: map[string]interface{}{
+		"server":   "127.0.0.1",
+		"protocol": "pickle",
+	}}}
+
+	conf, err := g.getConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:2004", conf.Server)
+	assert.Equal(t, defaultTemplate, conf.Template)
+
+	g.cfg.Config = map[string]interface{}{"server": "127.0.0.1", "protocol": "udp"}
+	_, err = g.getConfig()
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package graphite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// pickle opcodes (protocol 2)
+const (
+	opProto      = 0x80
+	opEmptyList  = ']'
+	opMark       = '('
+	opAppends    = 'e'
+	opBinUnicode = 'X'
+	opBinInt     = 'J'
+	opLong1      = 0x8a
+	opBinFloat   = 'G'
+	opTuple2     = 0x86
+	opStop       = '.'
+)
+
+// writePickle writes the carbon pickle protocol message: a 4-byte
+// big-endian length header and the pickled list of the metrics as
+// [(path, (timestamp, value)), ...]
+func writePickle(buf *bytes.Buffer, batch []point) {
+	// header placeholder
+	buf.Write([]byte{0, 0, 0, 0})
+
+	buf.Write([]byte{opProto, 2, opEmptyList, opMark})
+
+	for _, m := range batch {
+		pickleString(buf, m.path)
+		pickleInt(buf, m.timestamp)
+		switch v := m.value.(type) {
+		case int64:
+			pickleInt(buf, v)
+		case uint64:
+			pickleUint(buf, v)
+		case float64:
+			pickleFloat(buf, v)
+		}
+		buf.WriteByte(opTuple2)
+		buf.WriteByte(opTuple2)
+	}
+
+	buf.Write([]byte{opAppends, opStop})
+
+	binary.BigEndian.PutUint32(buf.Bytes()[:4], uint32(buf.Len()-4))
+}
+
+func pickleString(buf *bytes.Buffer, s string) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(len(s)))
+	buf.WriteByte(opBinUnicode)
+	buf.Write(b[:])
+	buf.WriteString(s)
+}
+
+func pickleInt(buf *bytes.Buffer, v int64) {
+	if v >= math.MinInt32 && v <= math.MaxInt32 {
+		var b [4]byte
+		binary.LittleEndian.PutUint32(b[:], uint32(int32(v)))
+		buf.WriteByte(opBinInt)
+		buf.Write(b[:])
+		return
+	}
+
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(v))
+	buf.WriteByte(opLong1)
+	buf.WriteByte(8)
+	buf.Write(b[:])
+}
+
+func pickleUint(buf *bytes.Buffer, v uint64) {
+	if v <= math.MaxInt64 {
+		pickleInt(buf, int64(v))
+		return
+	}
+
+	// two's complement needs an extra byte to keep it positive
+	var b [9]byte
+	binary.LittleEndian.PutUint64(b[:8], v)
+	buf.WriteByte(opLong1)
+	buf.WriteByte(9)
+	buf.Write(b[:])
+}
+
+func pickleFloat(buf *bytes.Buffer, v float64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], math.Float64bits(v))
+	buf.WriteByte(opBinFloat)
+	buf.Write(b[:])
+}
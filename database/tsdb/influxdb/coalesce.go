@@ -0,0 +1,150 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package influxdb
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yahoo/panoptes-stream/serializer"
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+// coalescer merges datapoints with the same series (measurement, system_id,
+// prefix and labels) and timestamp into one point with many fields.
+type coalescer struct {
+	window    time.Duration
+	maxPoints int
+	lp        serializer.LineProtocol
+	points    map[string]*point
+	keyBuf    *bytes.Buffer
+}
+
+type point struct {
+	measurement string
+	ds          telemetry.DataStore
+	lp          serializer.LineProtocol
+	fields      []serializer.Field
+	index       map[string]int
+	created     time.Time
+}
+
+func newCoalescer(window time.Duration, maxPoints int, lp serializer.LineProtocol) *coalescer {
+	return &coalescer{
+		window:    window,
+		maxPoints: maxPoints,
+		lp:        lp,
+		points:    make(map[string]*point),
+		keyBuf:    new(bytes.Buffer),
+	}
+}
+
+// add adds the datastore to an existing point or creates a new point,
+// it returns error if the value is not a valid line protocol field value.
+func (c *coalescer) add(measurement string, ds telemetry.DataStore) error {
+	if _, err := serializer.FieldValue(ds["value"]); err != nil {
+		return err
+	}
+
+	id := c.getPointID(measurement, ds)
+	key, _ := ds["key"].(string)
+
+	p, ok := c.points[id]
+	if !ok {
+		p = &point{
+			measurement: measurement,
+			ds:          ds,
+			lp:          c.lp,
+			index:       make(map[string]int),
+			created:     time.Now(),
+		}
+		c.points[id] = p
+	}
+
+	if i, ok := p.index[key]; ok {
+		p.fields[i].Value = ds["value"]
+		return nil
+	}
+
+	p.index[key] = len(p.fields)
+	p.fields = append(p.fields, serializer.Field{Key: key, Value: ds["value"]})
+
+	return nil
+}
+
+// full returns true if the number of points reached the limit.
+func (c *coalescer) full() bool {
+	return len(c.points) >= c.maxPoints
+}
+
+// expired returns line protocol of the points that are older than
+// the coalescing window and removes them.
+func (c *coalescer) expired(buf *bytes.Buffer, now time.Time) []string {
+	var lines []string
+
+	for id, p := range c.points {
+		if now.Sub(p.created) < c.window {
+			continue
+		}
+
+		if line, err := p.line(buf); err == nil {
+			lines = append(lines, line)
+		}
+		delete(c.points, id)
+	}
+
+	return lines
+}
+
+// drain returns line protocol of all points and removes them.
+func (c *coalescer) drain(buf *bytes.Buffer) []string {
+	var lines []string
+
+	for id, p := range c.points {
+		if line, err := p.line(buf); err == nil {
+			lines = append(lines, line)
+		}
+		delete(c.points, id)
+	}
+
+	return lines
+}
+
+func (c *coalescer) getPointID(measurement string, ds telemetry.DataStore) string {
+	c.keyBuf.Reset()
+	c.keyBuf.WriteString(measurement)
+	c.keyBuf.WriteByte(0)
+	fmt.Fprint(c.keyBuf, ds["system_id"])
+	c.keyBuf.WriteByte(0)
+	fmt.Fprint(c.keyBuf, ds["prefix"])
+	c.keyBuf.WriteByte(0)
+	fmt.Fprint(c.keyBuf, ds["timestamp"])
+
+	labels, _ := ds["labels"].(map[string]string)
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		c.keyBuf.WriteByte(0)
+		c.keyBuf.WriteString(k)
+		c.keyBuf.WriteByte('=')
+		c.keyBuf.WriteString(labels[k])
+	}
+
+	return c.keyBuf.String()
+}
+
+func (p *point) line(buf *bytes.Buffer) (string, error) {
+	buf.Reset()
+	if err := p.lp.WriteFields(buf, p.measurement, p.ds, p.fields); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,103 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package influxdb
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/serializer"
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+func getDataStore(key string, value interface{}, timestamp int64) telemetry.DataStore {
+	return telemetry.DataStore{
+		"prefix":    "/interfaces/interface/state/counters",
+		"labels":    map[string]string{"name": "Ethernet3"},
+		"system_id": "core1.bur",
+		"timestamp": timestamp,
+		"key":       key,
+		"value":     value,
+	}
+}
+
+func TestCoalescer(t *testing.T) {
+	buf := new(bytes.Buffer)
+	c := newCoalescer(time.Second, 10, serializer.LineProtocol{Precision: time.Nanosecond})
+
+	c.add("ifcounters", getDataStore("in-octets", 10, 1595768623436661269))
+	c.add("ifcounters", getDataStore("out-octets", 20, 1595768623436661269))
+	c.add("ifcounters", getDataStore("out-octets", 30, 1595768623436661269))
+	// different timestamp
+	c.add("ifcounters", getDataStore("in-octets", 40, 1595768623436661270))
+
+	assert.Len(t, c.points, 2)
+	assert.False(t, c.full())
+
+	// not expired
+	assert.Len(t, c.expired(buf, time.Now()), 0)
+
+	lines := c.expired(buf, time.Now().Add(time.Second))
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines, "ifcounters,_prefix_=/interfaces/interface/state/counters,_host_=core1.bur,name=Ethernet3 in-octets=10i,out-octets=30i 1595768623436661269")
+	assert.Contains(t, lines, "ifcounters,_prefix_=/interfaces/interface/state/counters,_host_=core1.bur,name=Ethernet3 in-octets=40i 1595768623436661270")
+	assert.Len(t, c.points, 0)
+
+	// drain
+	c.add("ifcounters", getDataStore("in-octets", 10, 1595768623436661269))
+	assert.Len(t, c.drain(buf), 1)
+	assert.Len(t, c.points, 0)
+
+	// labels order
+	ds1 := getDataStore("in-octets", 10, 1)
+	ds1["labels"] = map[string]string{"a": "1", "b": "2"}
+	ds2 := getDataStore("out-octets", 10, 1)
+	ds2["labels"] = map[string]string{"b": "2", "a": "1"}
+	assert.Equal(t, c.getPointID("m", ds1), c.getPointID("m", ds2))
+}
+
+func TestCoalesceWrite(t *testing.T) {
+	done := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, "interfaces_interface_state_counters,_prefix_=/interfaces/interface/state/counters,_host_=core1.bur,name=Ethernet3 in-octets=10i,out-octets=20i 150000000\n", string(body))
+		w.WriteHeader(http.StatusAccepted)
+		close(done)
+	}))
+	defer server.Close()
+
+	cfg := config.NewMockConfig()
+	ch := make(telemetry.ExtDSChan, 10)
+
+	dbCfg := config.Database{Name: "influxdb1", Service: "influxdb", Config: map[string]interface{}{
+		"server":         server.URL,
+		"bucket":         "mybucket",
+		"batchSize":      1,
+		"measurement":    "prefix",
+		"coalesceWindow": 100,
+	}}
+
+	db := New(dbCfg, cfg.Logger(), ch)
+	go db.Start(ctx)
+
+	ch <- telemetry.ExtDataStore{Output: "influxdb1::test", DS: getDataStore("in-octets", 10, 150000000)}
+	ch <- telemetry.ExtDataStore{Output: "influxdb1::test", DS: getDataStore("out-octets", 20, 150000000)}
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Error("time limit exceeded")
+	}
+}
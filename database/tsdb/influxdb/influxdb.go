@@ -13,27 +13,31 @@ import (
 	"time"
 
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
-	"github.com/influxdata/influxdb-client-go/v2/api/http"
-	"github.com/influxdata/influxdb/pkg/escape"
 	"github.com/kelseyhightower/envconfig"
 	"go.uber.org/zap"
 
 	"github.com/yahoo/panoptes-stream/config"
 	"github.com/yahoo/panoptes-stream/database"
+	"github.com/yahoo/panoptes-stream/lifecycle"
 	"github.com/yahoo/panoptes-stream/secret"
+	"github.com/yahoo/panoptes-stream/serializer"
+	"github.com/yahoo/panoptes-stream/status"
 	"github.com/yahoo/panoptes-stream/telemetry"
 )
 
 // InfluxDB represents InfluxDB.
 type InfluxDB struct {
-	ctx    context.Context
-	ch     telemetry.ExtDSChan
-	logger *zap.Logger
-	cfg    config.Database
+	lifecycle.Database
+
+	ch      telemetry.ExtDSChan
+	logger  *zap.Logger
+	metrics *status.OutputMetrics
+	cfg     config.Database
 }
 
 type influxDBConfig struct {
 	Server        string
+	Version       int
 	Bucket        string
 	Org           string
 	Token         string
@@ -41,57 +45,144 @@ type influxDBConfig struct {
 	MaxRetries    uint
 	Timeout       uint
 	FlushInterval int
+	Measurement   string
+
+	CoalesceWindow    int
+	CoalesceMaxPoints int
+	Precision         string
+	Uint              bool
+
+	Database        string
+	RetentionPolicy string
+	Username        string
+	Password        string
+	UDPPayloadSize  int
 
 	TLSConfig config.TLSConfig
+
+	precision time.Duration
 }
 
 // New returns a new influxdb instance.
-func New(ctx context.Context, cfg config.Database, lg *zap.Logger, inChan telemetry.ExtDSChan) database.Database {
+func New(cfg config.Database, lg *zap.Logger, inChan telemetry.ExtDSChan) database.Database {
 	return &InfluxDB{
-		ctx:    ctx,
-		cfg:    cfg,
-		ch:     inChan,
-		logger: lg,
+		cfg:     cfg,
+		ch:      inChan,
+		logger:  lg,
+		metrics: status.NewOutputMetrics(cfg.Name),
 	}
 }
 
 // Start starts influxdb ingestion.
-func (i *InfluxDB) Start() {
+func (i *InfluxDB) Start(ctx context.Context) error {
+	return i.Run(ctx, i.start)
+}
+
+func (i *InfluxDB) start(ctx context.Context) error {
 	var flush bool
 
 	config, err := i.getConfig()
 	if err != nil {
-		i.logger.Fatal("influxdb", zap.Error(err))
+		return err
 	}
 
-	client, err := i.getClient(config)
+	w, err := i.getWriter(config)
 	if err != nil {
-		i.logger.Fatal("influxdb", zap.Error(err))
+		return err
 	}
+	defer w.close()
+
+	i.logger.Info("influxdb", zap.String("name", i.cfg.Name), zap.String("server", config.Server), zap.Int("version", config.Version),
+		zap.String("bucket", config.Bucket), zap.String("database", config.Database))
 
-	writeAPI := client.WriteAPIBlocking(config.Org, config.Bucket)
+	var (
+		coalescer     *coalescer
+		coalesceTimer <-chan time.Time
+	)
 
-	i.logger.Info("influxdb", zap.String("name", i.cfg.Name), zap.String("server", config.Server), zap.String("bucket", config.Bucket))
+	lp := serializer.LineProtocol{
+		Precision: config.precision,
+		NoUint:    config.Version == 1 && !config.Uint,
+	}
 
 	buf := new(bytes.Buffer)
 	batch := make([]string, 0, config.BatchSize)
+	lw := &lifecycle.Writer{
+		Lifecycle:  &i.Lifecycle,
+		Metrics:    i.metrics,
+		MaxRetries: -1,
+		Permanent:  isBadRequest,
+		OnError: func(err error) {
+			i.logger.Error("influxdb", zap.String("event", "write"), zap.Error(err))
+		},
+	}
+	write := func(ctx context.Context) (int, error) {
+		return batchBytes(batch), w.write(ctx, batch)
+	}
+
 	flushTicker := time.NewTicker(time.Duration(config.FlushInterval) * time.Second)
+	defer flushTicker.Stop()
+
+	if config.CoalesceWindow > 0 {
+		window := time.Duration(config.CoalesceWindow) * time.Millisecond
+		coalescer = newCoalescer(window, config.CoalesceMaxPoints, lp)
+		coalesceTicker := time.NewTicker(window)
+		defer coalesceTicker.Stop()
+		coalesceTimer = coalesceTicker.C
+	}
 
-L:
 	for {
 		select {
 		case v, ok := <-i.ch:
 			if !ok {
-				break L
+				// the channel is closed and drained
+				if coalescer != nil {
+					batch = append(batch, coalescer.drain(buf)...)
+				}
+				lw.Write(ctx, len(batch), write)
+				return nil
 			}
 
-			line, err := getLineProtocol(buf, v)
+			i.metrics.DataIn.Inc()
+
+			measurement, err := getMeasurement(config.Measurement, v)
 			if err != nil {
 				i.logger.Error("influxdb", zap.Error(err), zap.String("output", v.Output))
+				i.AddDropped(1)
+				i.metrics.Error(status.ErrorInvalid)
 				continue
 			}
 
-			batch = append(batch, line)
+			if coalescer != nil {
+				if err := coalescer.add(measurement, v.DS); err != nil {
+					i.logger.Error("influxdb", zap.String("event", "line protocol"), zap.Error(err), zap.String("output", v.Output))
+					i.AddDropped(1)
+					i.metrics.Error(status.ErrorInvalid)
+					continue
+				}
+
+				if !coalescer.full() {
+					continue
+				}
+
+				batch = append(batch, coalescer.drain(buf)...)
+			} else {
+				line, err := getLineProtocol(buf, lp, measurement, v.DS)
+				if err != nil {
+					i.logger.Error("influxdb", zap.String("event", "line protocol"), zap.Error(err), zap.String("output", v.Output))
+					i.AddDropped(1)
+					i.metrics.Error(status.ErrorInvalid)
+					continue
+				}
+
+				batch = append(batch, line)
+			}
+
+		case now := <-coalesceTimer:
+			batch = append(batch, coalescer.expired(buf, now)...)
+			if len(batch) < int(config.BatchSize) {
+				continue
+			}
 
 		case <-flushTicker.C:
 			if len(batch) > 0 {
@@ -100,61 +191,90 @@ L:
 				continue
 			}
 
-		case <-i.ctx.Done():
-			i.logger.Info("influxdb", zap.String("event", "terminate"), zap.String("name", i.cfg.Name))
-			writeAPI.WriteRecord(i.ctx, batch...)
-			return
-		}
-
-		if len(batch) == int(config.BatchSize) || flush {
-			for i.ctx.Err() == nil {
-				err = writeAPI.WriteRecord(i.ctx, batch...)
-				if err != nil {
-					i.logger.Error("influxdb", zap.String("event", "write"), zap.Error(err))
-					v, ok := err.(*http.Error)
-					// 400 bad request doesn't need to retry
-					if ok && v.StatusCode == 400 {
-						break
-					}
-
-					// backoff
-					time.Sleep(1 * time.Second)
-					continue
+		case req := <-i.ReloadRequest():
+			cfg := req.Database()
+			conf, err := (&InfluxDB{cfg: cfg}).getConfig()
+			if err == nil {
+				err = lifecycle.Reloadable(config, conf, "BatchSize", "FlushInterval", "CoalesceMaxPoints")
+			}
+			if err == nil {
+				i.cfg, config = cfg, conf
+				flushTicker.Reset(time.Duration(config.FlushInterval) * time.Second)
+				if coalescer != nil {
+					coalescer.maxPoints = config.CoalesceMaxPoints
 				}
+			}
+			req.Reply(err)
+			continue
 
-				break
+		case req := <-i.FlushRequest():
+			if coalescer != nil {
+				batch = append(batch, coalescer.drain(buf)...)
 			}
+			req <- lw.Write(ctx, len(batch), write)
+			flush = false
+			if ctx.Err() == nil {
+				batch = batch[:0]
+			}
+			continue
 
+		case <-ctx.Done():
+			i.logger.Info("influxdb", zap.String("event", "terminate"), zap.String("name", i.cfg.Name))
+			if coalescer != nil {
+				batch = append(batch, coalescer.drain(buf)...)
+			}
+			lw.Final(len(batch), write)
+			return nil
+		}
+
+		if len(batch) >= int(config.BatchSize) || flush {
+			lw.Write(ctx, len(batch), write)
 			flush = false
-			batch = batch[:0]
+			if ctx.Err() == nil {
+				batch = batch[:0]
+			}
 		}
 	}
+}
+
+// batchBytes returns the line protocol size of the batch.
+func batchBytes(batch []string) int {
+	var n int
+	for _, line := range batch {
+		n += len(line)
+	}
 
+	return n
 }
 
-func getLineProtocol(buf *bytes.Buffer, v telemetry.ExtDataStore) (string, error) {
+func getLineProtocol(buf *bytes.Buffer, lp serializer.LineProtocol, measurement string, ds telemetry.DataStore) (string, error) {
+	buf.Reset()
+	if err := lp.Write(buf, measurement, ds); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// getMeasurement returns the measurement name based on the configuration:
+// topic: output topic (default)
+// prefix: prefix path without leading and trailing slash that slashes replaced by underscore
+func getMeasurement(mType string, v telemetry.ExtDataStore) (string, error) {
+	if mType == "prefix" {
+		prefix, ok := v.DS["prefix"].(string)
+		if !ok || strings.Trim(prefix, "/") == "" {
+			return "", errors.New("invalid prefix")
+		}
+
+		return strings.Replace(strings.Trim(prefix, "/"), "/", "_", -1), nil
+	}
+
 	out := strings.Split(v.Output, "::")
 	if len(out) < 2 {
 		return "", errors.New("invalid output")
 	}
 
-	buf.Reset()
-	buf.WriteString(out[1])
-	buf.WriteRune(',')
-	buf.WriteString("_prefix_=" + v.DS["prefix"].(string))
-	buf.WriteRune(',')
-	buf.WriteString("_host_=" + v.DS["system_id"].(string))
-	for k, v := range v.DS["labels"].(map[string]string) {
-		buf.WriteRune(',')
-		v = strings.Replace(v, " ", "_", -1)
-		buf.WriteString(escape.String(k) + "=" + v)
-	}
-	buf.WriteRune(' ')
-	buf.WriteString(escape.String(v.DS["key"].(string)) + "=" + getValueString(v.DS["value"]))
-	buf.WriteRune(' ')
-	buf.WriteString(getValueString(v.DS["timestamp"]))
-
-	return buf.String(), nil
+	return out[1], nil
 }
 
 func (i *InfluxDB) getClient(config *influxDBConfig) (influxdb2.Client, error) {
@@ -174,6 +294,7 @@ func (i *InfluxDB) getClient(config *influxDBConfig) (influxdb2.Client, error) {
 	}
 
 	opts.SetMaxRetries(config.MaxRetries)
+	opts.SetPrecision(config.precision)
 	opts.SetHTTPRequestTimeout(config.Timeout)
 
 	client := influxdb2.NewClientWithOptions(config.Server, token, opts)
@@ -203,6 +324,30 @@ func (i *InfluxDB) getConfig() (*influxDBConfig, error) {
 	config.SetDefault(&conf.Timeout, 1)
 	config.SetDefault(&conf.MaxRetries, 2)
 	config.SetDefault(&conf.FlushInterval, 1)
+	config.SetDefault(&conf.CoalesceMaxPoints, int(conf.BatchSize)*10)
+	config.SetDefault(&conf.Version, 2)
+	config.SetDefault(&conf.UDPPayloadSize, 512)
+
+	if conf.Version != 1 && conf.Version != 2 {
+		return nil, fmt.Errorf("version %d not supported", conf.Version)
+	}
+
+	if conf.Precision == "" {
+		conf.Precision = "ns"
+	}
+
+	conf.precision, err = serializer.GetPrecision(conf.Precision)
+	if err != nil {
+		return nil, err
+	}
+
+	switch conf.Measurement {
+	case "":
+		conf.Measurement = "topic"
+	case "topic", "prefix":
+	default:
+		return nil, fmt.Errorf("measurement %s not supported", conf.Measurement)
+	}
 
 	return conf, nil
 }
@@ -224,24 +369,3 @@ func getToken(tokenConfig string) (string, error) {
 
 	return tokenConfig, errors.New("token not found")
 }
-
-func getValueString(value interface{}) string {
-	switch v := value.(type) {
-	case uint64, uint32, uint16, uint8, uint,
-		int64, int32, int16, int8, int:
-		return fmt.Sprintf("%d", v)
-	case float64, float32:
-		return fmt.Sprintf("%f", v)
-	case bool:
-		return fmt.Sprintf("%t", v)
-	case string:
-		return fmt.Sprintf("\"%s\"", escape.String(v))
-	case []byte:
-		return fmt.Sprintf("\"%s\"", escape.String(string(v)))
-	case []interface{}, map[string]interface{}:
-		b, _ := json.Marshal(v)
-		return fmt.Sprintf("\"%s\"", escape.String(string(b)))
-	}
-
-	return ""
-}
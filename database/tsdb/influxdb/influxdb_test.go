@@ -16,6 +16,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/database"
+	"github.com/yahoo/panoptes-stream/lifecycle"
+	"github.com/yahoo/panoptes-stream/serializer"
 	"github.com/yahoo/panoptes-stream/telemetry"
 )
 
@@ -34,9 +37,24 @@ func TestLineProtocol(t *testing.T) {
 
 	buf := new(bytes.Buffer)
 
-	l, err := getLineProtocol(buf, data)
+	measurement, err := getMeasurement("topic", data)
 	require.Equal(t, err, nil)
-	assert.Equal(t, l, "ifcounters,_prefix_=/interfaces/interface/state/counters/,_host_=core1.bur,name=Ethernet3 out-octets=5587651 1595768623436661269")
+	l, err := getLineProtocol(buf, serializer.LineProtocol{Precision: time.Nanosecond}, measurement, data.DS)
+	require.Equal(t, err, nil)
+	assert.Equal(t, l, "ifcounters,_prefix_=/interfaces/interface/state/counters/,_host_=core1.bur,name=Ethernet3 out-octets=5587651i 1595768623436661269")
+
+	// escaping and precision
+	data.DS["labels"] = map[string]string{"name": "Ethernet3", "description": "uplink, core=1"}
+	data.DS["key"] = "out octets"
+	data.DS["value"] = uint64(5587651)
+	l, err = getLineProtocol(buf, serializer.LineProtocol{Precision: time.Second}, "if counters", data.DS)
+	require.Equal(t, err, nil)
+	assert.Equal(t, l, `if\ counters,_prefix_=/interfaces/interface/state/counters/,_host_=core1.bur,description=uplink\,\ core\=1,name=Ethernet3 out\ octets=5587651u 1595768623`)
+
+	// unsupported value
+	data.DS["value"] = nil
+	_, err = getLineProtocol(buf, serializer.LineProtocol{Precision: time.Nanosecond}, measurement, data.DS)
+	assert.Error(t, err)
 }
 
 func TestSingleMetric(t *testing.T) {
@@ -45,7 +63,7 @@ func TestSingleMetric(t *testing.T) {
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		body, _ := ioutil.ReadAll(r.Body)
-		assert.Equal(t, "test,_prefix_=/tests/test,_host_=127.0.0.1 mykey=0 150000000\n", string(body))
+		assert.Equal(t, "test,_prefix_=/tests/test,_host_=127.0.0.1 mykey=0i 150000000\n", string(body))
 		w.WriteHeader(http.StatusAccepted)
 		close(done)
 	}))
@@ -59,8 +77,8 @@ func TestSingleMetric(t *testing.T) {
 		"bactchSize": 1,
 	}}
 
-	db := New(ctx, dbCfg, cfg.Logger(), ch)
-	go db.Start()
+	db := New(dbCfg, cfg.Logger(), ch)
+	go db.Start(ctx)
 	ch <- telemetry.ExtDataStore{
 		Output: "influxdb1::test",
 		DS: map[string]interface{}{
@@ -80,9 +98,123 @@ func TestSingleMetric(t *testing.T) {
 	}
 
 	time.Sleep(time.Second)
+	assert.NoError(t, db.Health())
+	assert.Equal(t, uint64(1), db.Stats().Sent)
+
 	cancel()
 }
 
+func TestStartError(t *testing.T) {
+	cfg := config.NewMockConfig()
+	dbCfg := config.Database{Name: "influxdb1", Service: "influxdb", Config: map[string]interface{}{
+		"server":  "http://127.0.0.1:8086",
+		"version": 3,
+	}}
+
+	db := New(dbCfg, cfg.Logger(), make(telemetry.ExtDSChan))
+	err := db.Start(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, err, db.Health())
+}
+
+func TestReload(t *testing.T) {
+	lines := make(chan string, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		lines <- string(body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	cfg := config.NewMockConfig()
+	ch := make(telemetry.ExtDSChan, 10)
+
+	dbCfg := config.Database{Name: "influxdb1", Service: "influxdb", Config: map[string]interface{}{
+		"server":        server.URL,
+		"bucket":        "mybucket",
+		"batchSize":     10,
+		"flushInterval": 60,
+	}}
+
+	db := New(dbCfg, cfg.Logger(), ch)
+	done := make(chan error)
+	go func() {
+		done <- db.Start(context.Background())
+	}()
+
+	ds := map[string]interface{}{
+		"prefix":    "/tests/test",
+		"labels":    map[string]string{},
+		"system_id": "127.0.0.1",
+		"timestamp": 150000000,
+		"key":       "mykey",
+		"value":     0,
+	}
+
+	reloader := db.(database.Reloader)
+
+	// the batch size applies without reconnecting
+	dbCfg.Config = map[string]interface{}{"server": server.URL, "bucket": "mybucket", "batchSize": 1, "flushInterval": 60}
+	for i := 0; i < 10 && reloader.Reload(dbCfg) == lifecycle.ErrNotRunning; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.NoError(t, reloader.Reload(dbCfg))
+
+	ch <- telemetry.ExtDataStore{Output: "influxdb1::test", DS: ds}
+
+	select {
+	case line := <-lines:
+		assert.Equal(t, "test,_prefix_=/tests/test,_host_=127.0.0.1 mykey=0i 150000000\n", line)
+	case <-time.After(3 * time.Second):
+		t.Fatal("time limit exceeded")
+	}
+
+	// the bucket needs a new instance
+	dbCfg.Config = map[string]interface{}{"server": server.URL, "bucket": "newbucket", "batchSize": 1, "flushInterval": 60}
+	assert.Equal(t, lifecycle.ErrRestartRequired, reloader.Reload(dbCfg))
+
+	// the closed channel drains the buffered data
+	dbCfg.Config = map[string]interface{}{"server": server.URL, "bucket": "mybucket", "batchSize": 10, "flushInterval": 60}
+	assert.NoError(t, reloader.Reload(dbCfg))
+
+	ch <- telemetry.ExtDataStore{Output: "influxdb1::drain", DS: ds}
+	close(ch)
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("time limit exceeded")
+	}
+
+	assert.Equal(t, "drain,_prefix_=/tests/test,_host_=127.0.0.1 mykey=0i 150000000\n", <-lines)
+	assert.Equal(t, uint64(2), db.Stats().Sent)
+}
+
+func TestMeasurement(t *testing.T) {
+	data := telemetry.ExtDataStore{
+		Output: "influx1::ifcounters",
+		DS:     telemetry.DataStore{"prefix": "/interfaces/interface/state/counters/"},
+	}
+
+	m, err := getMeasurement("topic", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "ifcounters", m)
+
+	m, err = getMeasurement("prefix", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "interfaces_interface_state_counters", m)
+
+	data.Output = "influx1"
+	_, err = getMeasurement("topic", data)
+	assert.Error(t, err)
+
+	data.DS["prefix"] = "/"
+	_, err = getMeasurement("prefix", data)
+	assert.Error(t, err)
+}
+
 func BenchmarkLineProtocol(b *testing.B) {
 	data := telemetry.ExtDataStore{
 		Output: "influx1::ifcounters",
@@ -99,24 +231,6 @@ func BenchmarkLineProtocol(b *testing.B) {
 	buf := new(bytes.Buffer)
 
 	for i := 0; i < b.N; i++ {
-		getLineProtocol(buf, data)
+		getLineProtocol(buf, serializer.LineProtocol{Precision: time.Nanosecond}, "ifcounters", data.DS)
 	}
 }
-
-func TestGetValueString(t *testing.T) {
-	var v interface{}
-	v = 1
-	assert.Equal(t, "1", getValueString(v))
-	v = 1.5
-	assert.Equal(t, "1.500000", getValueString(v))
-	v = true
-	assert.Equal(t, "true", getValueString(v))
-	v = "test"
-	assert.Equal(t, "\"test\"", getValueString(v))
-	v = []byte("test")
-	assert.Equal(t, "\"test\"", getValueString(v))
-	v = map[string]interface{}{"name": "foo"}
-	assert.Equal(t, "\"{\\\"name\\\":\\\"foo\\\"}\"", getValueString(v))
-	v = map[string]string{"a": "b"}
-	assert.Equal(t, "", getValueString(v))
-}
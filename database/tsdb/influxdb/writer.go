@@ -0,0 +1,210 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package influxdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	ihttp "github.com/influxdata/influxdb-client-go/v2/api/http"
+
+	"github.com/yahoo/panoptes-stream/secret"
+)
+
+// writer represents line protocol transport.
+type writer interface {
+	write(context.Context, []string) error
+	close() error
+}
+
+// v2Writer writes line protocol through InfluxDB 2.x API.
+type v2Writer struct {
+	api api.WriteAPIBlocking
+}
+
+// v1Writer writes line protocol through InfluxDB 1.x /write API.
+type v1Writer struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+}
+
+// udpWriter writes line protocol to InfluxDB (or Telegraf) UDP listener.
+type udpWriter struct {
+	conn        net.Conn
+	payloadSize int
+}
+
+func (i *InfluxDB) getWriter(config *influxDBConfig) (writer, error) {
+	u, err := url.Parse(config.Server)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "udp" {
+		return newUDPWriter(u.Host, config.UDPPayloadSize)
+	}
+
+	if config.Version == 1 {
+		return newV1Writer(config)
+	}
+
+	client, err := i.getClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v2Writer{api: client.WriteAPIBlocking(config.Org, config.Bucket)}, nil
+}
+
+// isBadRequest returns true if the server rejected the lines (400 bad request).
+func isBadRequest(err error) bool {
+	var e *ihttp.Error
+	return errors.As(err, &e) && e.StatusCode == http.StatusBadRequest
+}
+
+func (w *v2Writer) write(ctx context.Context, lines []string) error {
+	return w.api.WriteRecord(ctx, lines...)
+}
+
+func (w *v2Writer) close() error {
+	return nil
+}
+
+func newV1Writer(config *influxDBConfig) (*v1Writer, error) {
+	if config.Database == "" {
+		return nil, errors.New("database is empty")
+	}
+
+	client := &http.Client{
+		Timeout: time.Duration(config.Timeout) * time.Second,
+	}
+
+	if config.TLSConfig.Enabled {
+		tlsConfig, err := secret.GetTLSConfig(&config.TLSConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	}
+
+	username, password, err := secret.GetUsernamePassword(config.Username, config.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("db", config.Database)
+	params.Set("precision", v1Precision(config.Precision))
+	if config.RetentionPolicy != "" {
+		params.Set("rp", config.RetentionPolicy)
+	}
+
+	return &v1Writer{
+		url:      fmt.Sprintf("%s/write?%s", strings.TrimSuffix(config.Server, "/"), params.Encode()),
+		username: username,
+		password: password,
+		client:   client,
+	}, nil
+}
+
+// v1Precision returns the precision of the 1.x write API, it
+// accepts n, u, ms and s instead of the configured ns and us.
+func v1Precision(precision string) string {
+	switch precision {
+	case "", "ns":
+		return "n"
+	case "us":
+		return "u"
+	}
+
+	return precision
+}
+
+func (w *v1Writer) write(ctx context.Context, lines []string) error {
+	req, err := http.NewRequest(http.MethodPost, w.url, strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.username != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	return &ihttp.Error{
+		StatusCode: resp.StatusCode,
+		Code:       resp.Status,
+		Message:    strings.TrimSpace(string(body)),
+	}
+}
+
+func (w *v1Writer) close() error {
+	w.client.CloseIdleConnections()
+	return nil
+}
+
+func newUDPWriter(addr string, payloadSize int) (*udpWriter, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &udpWriter{
+		conn:        conn,
+		payloadSize: payloadSize,
+	}, nil
+}
+
+// write sends lines in packets up to payload size, a line that
+// is bigger than the payload size sends in a separate packet.
+func (w *udpWriter) write(ctx context.Context, lines []string) error {
+	var packet []byte
+
+	for _, line := range lines {
+		if len(packet) > 0 && len(packet)+len(line)+1 > w.payloadSize {
+			if _, err := w.conn.Write(packet); err != nil {
+				return err
+			}
+			packet = packet[:0]
+		}
+
+		packet = append(packet, line...)
+		packet = append(packet, '\n')
+	}
+
+	if len(packet) > 0 {
+		_, err := w.conn.Write(packet)
+		return err
+	}
+
+	return nil
+}
+
+func (w *udpWriter) close() error {
+	return w.conn.Close()
+}
@@ -0,0 +1,104 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package influxdb
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV1Writer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/write", r.URL.Path)
+		assert.Equal(t, "mydb", r.URL.Query().Get("db"))
+		assert.Equal(t, "myrp", r.URL.Query().Get("rp"))
+		assert.Equal(t, "ms", r.URL.Query().Get("precision"))
+
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "pass", password)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"unable to parse"}`))
+			return
+		}
+
+		assert.Equal(t, "m1 f=1i 1\nm1 f=2i 2", string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	conf := &influxDBConfig{
+		Server:          server.URL,
+		Version:         1,
+		Database:        "mydb",
+		RetentionPolicy: "myrp",
+		Username:        "user",
+		Password:        "pass",
+		Precision:       "ms",
+		Timeout:         1,
+	}
+
+	w, err := newV1Writer(conf)
+	assert.NoError(t, err)
+	defer w.close()
+
+	err = w.write(context.Background(), []string{"m1 f=1i 1", "m1 f=2i 2"})
+	assert.NoError(t, err)
+
+	err = w.write(context.Background(), []string{"bad"})
+	assert.True(t, isBadRequest(err))
+
+	// database is required
+	conf.Database = ""
+	_, err = newV1Writer(conf)
+	assert.Error(t, err)
+}
+
+func TestV1WriterPrecision(t *testing.T) {
+	for precision, expected := range map[string]string{"ns": "n", "us": "u", "ms": "ms", "s": "s"} {
+		w, err := newV1Writer(&influxDBConfig{Server: "http://127.0.0.1:8086/", Database: "mydb", Precision: precision})
+		assert.NoError(t, err)
+
+		u, err := url.Parse(w.url)
+		assert.NoError(t, err)
+		assert.Equal(t, "/write", u.Path)
+		assert.Equal(t, url.Values{"db": {"mydb"}, "precision": {expected}}, u.Query())
+	}
+}
+
+func TestUDPWriter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w, err := newUDPWriter(conn.LocalAddr().String(), 20)
+	assert.NoError(t, err)
+	defer w.close()
+
+	err = w.write(context.Background(), []string{"m1 f=1i 1", "m1 f=2i 2", "m1 f=3i 3"})
+	assert.NoError(t, err)
+
+	b := make([]byte, 100)
+
+	n, _, err := conn.ReadFrom(b)
+	assert.NoError(t, err)
+	assert.Equal(t, "m1 f=1i 1\nm1 f=2i 2\n", string(b[:n]))
+
+	n, _, err = conn.ReadFrom(b)
+	assert.NoError(t, err)
+	assert.Equal(t, "m1 f=3i 3\n", string(b[:n]))
+}
@@ -0,0 +1,75 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+// Package metric provides the helpers for the databases that accept
+// numeric metrics with a name (e.g. Graphite and OpenTSDB).
+package metric
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// Value returns the numeric value as int64, uint64 or float64,
+// boolean converts to 0 or 1. it returns false if the value is not
+// numeric or it's NaN or infinity.
+func Value(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case int32:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int:
+		return int64(v), true
+	case uint64:
+		return v, true
+	case uint32:
+		return uint64(v), true
+	case uint16:
+		return uint64(v), true
+	case uint8:
+		return uint64(v), true
+	case uint:
+		return uint64(v), true
+	case float64:
+		return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+	case float32:
+		return float64(v), !math.IsNaN(float64(v)) && !math.IsInf(float64(v), 0)
+	case bool:
+		if v {
+			return int64(1), true
+		}
+		return int64(0), true
+	}
+
+	return nil, false
+}
+
+// Timestamp returns the datastore timestamp (nanoseconds)
+// in the given precision.
+func Timestamp(ts interface{}, precision time.Duration) (int64, bool) {
+	switch v := ts.(type) {
+	case int64:
+		return v / int64(precision), true
+	case int:
+		return int64(v) / int64(precision), true
+	case uint64:
+		return int64(v / uint64(precision)), true
+	}
+
+	return 0, false
+}
+
+// Topic returns the topic of the output (name::topic).
+func Topic(output string) string {
+	if out := strings.SplitN(output, "::", 2); len(out) == 2 {
+		return out[1]
+	}
+
+	return ""
+}
@@ -0,0 +1,56 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package metric
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValue(t *testing.T) {
+	testCases := []struct {
+		value    interface{}
+		expected interface{}
+	}{
+		{1, int64(1)},
+		{int32(-1), int64(-1)},
+		{uint8(1), uint64(1)},
+		{uint64(math.MaxUint64), uint64(math.MaxUint64)},
+		{float32(1.5), 1.5},
+		{true, int64(1)},
+		{false, int64(0)},
+	}
+
+	for _, tc := range testCases {
+		v, ok := Value(tc.value)
+		assert.True(t, ok, tc.value)
+		assert.Equal(t, tc.expected, v, tc.value)
+	}
+
+	for _, value := range []interface{}{"1", nil, math.NaN(), math.Inf(-1)} {
+		_, ok := Value(value)
+		assert.False(t, ok, value)
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	ts, ok := Timestamp(int64(1595768623436661269), time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1595768623), ts)
+
+	ts, ok = Timestamp(1595768623436661269, time.Millisecond)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1595768623436), ts)
+
+	_, ok = Timestamp("1595768623436661269", time.Second)
+	assert.False(t, ok)
+}
+
+func TestTopic(t *testing.T) {
+	assert.Equal(t, "ifcounters", Topic("graphite1::ifcounters"))
+	assert.Equal(t, "", Topic("graphite1"))
+}
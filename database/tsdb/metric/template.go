@@ -0,0 +1,125 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package metric
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+// Sanitizer returns the sanitized value of the variable.
+type Sanitizer func(variable, value string) string
+
+// Template represents a metric name template, it consists of literal
+// text and variables in braces: {system_id}, {prefix}, {key}, {topic}
+// and {labels.<name>} e.g. {system_id}.{prefix}.{labels.name}.{key}
+type Template struct {
+	parts []part
+}
+
+type part struct {
+	literal  string
+	variable string
+	label    string
+}
+
+// ParseTemplate parses the template.
+func ParseTemplate(text string) (*Template, error) {
+	t := &Template{}
+
+	for len(text) > 0 {
+		start := strings.IndexByte(text, '{')
+		if start < 0 {
+			t.parts = append(t.parts, part{literal: text})
+			break
+		}
+
+		if start > 0 {
+			t.parts = append(t.parts, part{literal: text[:start]})
+		}
+
+		end := strings.IndexByte(text[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("template: unclosed variable %s", text[start:])
+		}
+
+		p, err := getVariable(strings.TrimSpace(text[start+1 : start+end]))
+		if err != nil {
+			return nil, err
+		}
+
+		t.parts = append(t.parts, p)
+		text = text[start+end+1:]
+	}
+
+	if len(t.parts) < 1 {
+		return nil, fmt.Errorf("template: empty template")
+	}
+
+	return t, nil
+}
+
+func getVariable(name string) (part, error) {
+	switch {
+	case name == "system_id", name == "prefix", name == "key", name == "topic":
+		return part{variable: name}, nil
+	case strings.HasPrefix(name, "labels.") && len(name) > len("labels."):
+		return part{variable: "labels", label: strings.TrimPrefix(name, "labels.")}, nil
+	}
+
+	return part{}, fmt.Errorf("template: invalid variable %s", name)
+}
+
+// Execute writes the metric name to the buffer, it returns error
+// if a variable is not available in the datastore.
+func (t *Template) Execute(buf *bytes.Buffer, topic string, ds telemetry.DataStore, sanitize Sanitizer) error {
+	for _, p := range t.parts {
+		if p.variable == "" {
+			buf.WriteString(p.literal)
+			continue
+		}
+
+		var value string
+
+		switch p.variable {
+		case "topic":
+			value = topic
+		case "labels":
+			labels, _ := ds["labels"].(map[string]string)
+			value = labels[p.label]
+		default:
+			value, _ = ds[p.variable].(string)
+		}
+
+		if sanitize != nil {
+			value = sanitize(p.variable, value)
+		}
+
+		if value == "" {
+			if p.variable == "labels" {
+				return fmt.Errorf("template: label %s not available", p.label)
+			}
+			return fmt.Errorf("template: %s not available", p.variable)
+		}
+
+		buf.WriteString(value)
+	}
+
+	return nil
+}
+
+// Labels returns the label names that the template uses.
+func (t *Template) Labels() []string {
+	var labels []string
+	for _, p := range t.parts {
+		if p.variable == "labels" {
+			labels = append(labels, p.label)
+		}
+	}
+
+	return labels
+}
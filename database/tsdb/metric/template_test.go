@@ -0,0 +1,58 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package metric
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+var ds = telemetry.DataStore{
+	"prefix":    "/interfaces/interface/state/counters",
+	"labels":    map[string]string{"name": "Ethernet3"},
+	"system_id": "core1.bur",
+	"key":       "in-octets",
+}
+
+func TestTemplate(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	tpl, err := ParseTemplate("panoptes.{system_id}.{prefix}.{ labels.name }.{key}.{topic}")
+	require.NoError(t, err)
+
+	err = tpl.Execute(buf, "ifcounters", ds, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "panoptes.core1.bur./interfaces/interface/state/counters.Ethernet3.in-octets.ifcounters", buf.String())
+	assert.Equal(t, []string{"name"}, tpl.Labels())
+
+	// sanitizer
+	buf.Reset()
+	err = tpl.Execute(buf, "ifcounters", ds, func(variable, value string) string {
+		if variable == "prefix" {
+			return strings.Trim(value, "/")
+		}
+		return strings.ToUpper(value)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "panoptes.CORE1.BUR.interfaces/interface/state/counters.ETHERNET3.IN-OCTETS.IFCOUNTERS", buf.String())
+
+	// label not available
+	tpl, err = ParseTemplate("{labels.queue}")
+	require.NoError(t, err)
+	err = tpl.Execute(buf, "ifcounters", ds, nil)
+	assert.Error(t, err)
+}
+
+func TestParseError(t *testing.T) {
+	for _, text := range []string{"", "{system_id", "{host}", "{labels.}"} {
+		_, err := ParseTemplate(text)
+		assert.Error(t, err, text)
+	}
+}
@@ -0,0 +1,372 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package opentsdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kelseyhightower/envconfig"
+	"go.uber.org/zap"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/database"
+	"github.com/yahoo/panoptes-stream/database/tsdb/metric"
+	"github.com/yahoo/panoptes-stream/lifecycle"
+	"github.com/yahoo/panoptes-stream/secret"
+	"github.com/yahoo/panoptes-stream/status"
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+var defaultTemplate = "{prefix}.{key}"
+
+// OpenTSDB represents OpenTSDB.
+type OpenTSDB struct {
+	lifecycle.Database
+
+	ch      telemetry.ExtDSChan
+	logger  *zap.Logger
+	metrics *status.OutputMetrics
+	cfg     config.Database
+
+	client   *http.Client
+	url      string
+	username string
+	password string
+}
+
+type openTSDBConfig struct {
+	Server        string
+	Template      string
+	BatchSize     int
+	FlushInterval int
+	Timeout       int
+	MaxRetries    int
+	Username      string
+	Password      string
+
+	TLSConfig config.TLSConfig
+}
+
+type dataPoint struct {
+	Metric    string            `json:"metric"`
+	Timestamp int64             `json:"timestamp"`
+	Value     interface{}       `json:"value"`
+	Tags      map[string]string `json:"tags"`
+}
+
+// statusError represents non-successful http response.
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("opentsdb: status code %d: %s", e.code, e.body)
+}
+
+// New returns a new opentsdb instance.
+func New(cfg config.Database, lg *zap.Logger, inChan telemetry.ExtDSChan) database.Database {
+	return &OpenTSDB{
+		cfg:     cfg,
+		ch:      inChan,
+		logger:  lg,
+		metrics: status.NewOutputMetrics(cfg.Name),
+	}
+}
+
+// Start starts opentsdb ingestion.
+func (o *OpenTSDB) Start(ctx context.Context) error {
+	return o.Run(ctx, o.start)
+}
+
+func (o *OpenTSDB) start(ctx context.Context) error {
+	var flush bool
+
+	config, err := o.getConfig()
+	if err != nil {
+		return err
+	}
+
+	tpl, err := metric.ParseTemplate(config.Template)
+	if err != nil {
+		return err
+	}
+
+	if err = o.setClient(config); err != nil {
+		return err
+	}
+
+	o.logger.Info("opentsdb", zap.String("name", o.cfg.Name), zap.String("server", config.Server))
+
+	buf := new(bytes.Buffer)
+	batch := make([]dataPoint, 0, config.BatchSize)
+	w := &lifecycle.Writer{
+		Lifecycle:  &o.Lifecycle,
+		Metrics:    o.metrics,
+		MaxRetries: config.MaxRetries,
+		Permanent:  isBadRequest,
+		OnError: func(err error) {
+			o.logger.Error("opentsdb", zap.String("event", "write"), zap.Error(err))
+		},
+	}
+	write := func(ctx context.Context) (int, error) {
+		return o.write(ctx, batch)
+	}
+
+	flushTicker := time.NewTicker(time.Duration(config.FlushInterval) * time.Second)
+	defer flushTicker.Stop()
+
+	for {
+		select {
+		case v, ok := <-o.ch:
+			if !ok {
+				// the channel is closed and drained
+				w.Write(ctx, len(batch), write)
+				return nil
+			}
+
+			o.metrics.DataIn.Inc()
+
+			dp, err := getDataPoint(buf, tpl, v)
+			if err != nil {
+				o.logger.Error("opentsdb", zap.Error(err), zap.String("output", v.Output))
+				o.AddDropped(1)
+				o.metrics.Error(status.ErrorInvalid)
+				continue
+			}
+
+			batch = append(batch, dp)
+
+		case req := <-o.ReloadRequest():
+			cfg := req.Database()
+			conf, err := (&OpenTSDB{cfg: cfg}).getConfig()
+			if err == nil {
+				err = lifecycle.Reloadable(config, conf, lifecycle.BatchFields...)
+			}
+			if err == nil {
+				o.cfg, config, w.MaxRetries = cfg, conf, conf.MaxRetries
+				flushTicker.Reset(time.Duration(config.FlushInterval) * time.Second)
+			}
+			req.Reply(err)
+
+		case <-flushTicker.C:
+			if len(batch) > 0 {
+				flush = true
+			} else {
+				continue
+			}
+
+		case req := <-o.FlushRequest():
+			req <- w.Write(ctx, len(batch), write)
+			flush = false
+			if ctx.Err() == nil {
+				batch = batch[:0]
+			}
+			continue
+
+		case <-ctx.Done():
+			o.logger.Info("opentsdb", zap.String("event", "terminate"), zap.String("name", o.cfg.Name))
+			w.Final(len(batch), write)
+			return nil
+		}
+
+		if len(batch) >= config.BatchSize || flush {
+			w.Write(ctx, len(batch), write)
+			flush = false
+			if ctx.Err() == nil {
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// write writes the batch and returns the request body size.
+func (o *OpenTSDB) write(ctx context.Context, batch []dataPoint) (int, error) {
+	b, err := json.Marshal(batch)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, o.url, bytes.NewReader(b))
+	if err != nil {
+		return 0, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if o.username != "" {
+		req.SetBasicAuth(o.username, o.password)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusOK {
+		return len(b), nil
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	return len(b), &statusError{code: resp.StatusCode, body: strings.TrimSpace(string(body))}
+}
+
+// isBadRequest reports whether the write failed with 400
+// bad request, it doesn't need to retry.
+func isBadRequest(err error) bool {
+	var e *statusError
+	return errors.As(err, &e) && e.code == http.StatusBadRequest
+}
+
+func (o *OpenTSDB) setClient(config *openTSDBConfig) error {
+	var err error
+
+	o.client = &http.Client{
+		Timeout: time.Duration(config.Timeout) * time.Second,
+	}
+
+	if config.TLSConfig.Enabled {
+		tlsConfig, err := secret.GetTLSConfig(&config.TLSConfig)
+		if err != nil {
+			return err
+		}
+
+		o.client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	}
+
+	o.username, o.password, err = secret.GetUsernamePassword(config.Username, config.Password)
+	if err != nil {
+		return err
+	}
+
+	o.url = strings.TrimSuffix(config.Server, "/") + "/api/put"
+
+	return nil
+}
+
+// getDataPoint returns the data point based on the template, the system_id
+// and the labels are the data point tags.
+func getDataPoint(buf *bytes.Buffer, tpl *metric.Template, v telemetry.ExtDataStore) (dataPoint, error) {
+	value, ok := metric.Value(v.DS["value"])
+	if !ok {
+		return dataPoint{}, fmt.Errorf("unsupported value type %T", v.DS["value"])
+	}
+
+	// OpenTSDB supports signed 64-bit integers
+	if u, ok := value.(uint64); ok && u > math.MaxInt64 {
+		value = float64(u)
+	}
+
+	timestamp, ok := metric.Timestamp(v.DS["timestamp"], time.Millisecond)
+	if !ok {
+		return dataPoint{}, fmt.Errorf("invalid timestamp %v", v.DS["timestamp"])
+	}
+
+	buf.Reset()
+	if err := tpl.Execute(buf, metric.Topic(v.Output), v.DS, sanitizeMetric); err != nil {
+		return dataPoint{}, err
+	}
+
+	tags := make(map[string]string)
+	systemID, _ := v.DS["system_id"].(string)
+	if host := sanitize(systemID); host != "" {
+		tags["host"] = host
+	}
+
+	labels, _ := v.DS["labels"].(map[string]string)
+	for k, v := range labels {
+		k, v = sanitize(k), sanitize(v)
+		if k == "" || v == "" {
+			continue
+		}
+		tags[k] = v
+	}
+
+	// OpenTSDB rejects the data point without tags
+	if len(tags) < 1 {
+		return dataPoint{}, errors.New("no tags")
+	}
+
+	return dataPoint{
+		Metric:    buf.String(),
+		Timestamp: timestamp,
+		Value:     value,
+		Tags:      tags,
+	}, nil
+}
+
+// sanitizeMetric converts the prefix and key paths to dot
+// separated names and sanitizes the variables.
+func sanitizeMetric(variable, value string) string {
+	switch variable {
+	case "prefix", "key":
+		value = strings.Replace(strings.Trim(value, "/"), "/", ".", -1)
+	}
+
+	return sanitize(value)
+}
+
+// sanitize replaces the characters that OpenTSDB doesn't accept in the
+// metric names and the tags by underscore, the valid characters are
+// letters, numbers, -, _, . and /
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+
+		switch r {
+		case '-', '_', '.', '/':
+			return r
+		}
+
+		return '_'
+	}, s)
+}
+
+func (o *OpenTSDB) getConfig() (*openTSDBConfig, error) {
+	conf := new(openTSDBConfig)
+	b, err := json.Marshal(o.cfg.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(b, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := "panoptes_database_" + o.cfg.Name
+	err = envconfig.Process(prefix, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	config.SetDefault(&conf.BatchSize, 50)
+	config.SetDefault(&conf.FlushInterval, 1)
+	config.SetDefault(&conf.Timeout, 5)
+	config.SetDefault(&conf.MaxRetries, 2)
+
+	if conf.Template == "" {
+		conf.Template = defaultTemplate
+	}
+
+	if conf.Server == "" {
+		return nil, errors.New("server is empty")
+	}
+
+	return conf, nil
+}
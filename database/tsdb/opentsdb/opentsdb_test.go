@@ -0,0 +1,124 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package opentsdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/database/tsdb/metric"
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+func TestGetDataPoint(t *testing.T) {
+	buf := new(bytes.Buffer)
+	tpl, err := metric.ParseTemplate(defaultTemplate)
+	require.NoError(t, err)
+
+	v := telemetry.ExtDataStore{
+		Output: "opentsdb1::ifcounters",
+		DS: telemetry.DataStore{
+			"prefix":    "/interfaces/interface/state/counters/",
+			"labels":    map[string]string{"name": "Ethernet3/1", "description": "to core2 (lag)"},
+			"system_id": "core1.bur",
+			"timestamp": int64(1595768623436661269),
+			"key":       "in-octets",
+			"value":     uint64(10),
+		},
+	}
+	dp, err := getDataPoint(buf, tpl, v)
+	require.NoError(t, err)
+	assert.Equal(t, dataPoint{
+		Metric:    "interfaces.interface.state.counters.in-octets",
+		Timestamp: 1595768623436,
+		Value:     uint64(10),
+		Tags:      map[string]string{"host": "core1.bur", "name": "Ethernet3/1", "description": "to_core2__lag_"},
+	}, dp)
+
+	// empty system id
+	v.DS["system_id"] = ""
+	dp, err = getDataPoint(buf, tpl, v)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"name": "Ethernet3/1", "description": "to_core2__lag_"}, dp.Tags)
+
+	// no tags
+	delete(v.DS, "labels")
+	_, err = getDataPoint(buf, tpl, v)
+	assert.Error(t, err)
+
+	// unsupported value
+	v.DS["system_id"], v.DS["value"] = "core1.bur", "UP"
+	_, err = getDataPoint(buf, tpl, v)
+	assert.Error(t, err)
+}
+
+func TestStart(t *testing.T) {
+	done := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/put", r.URL.Path)
+
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "pass", password)
+
+		var dps []dataPoint
+		err := json.NewDecoder(r.Body).Decode(&dps)
+		assert.NoError(t, err)
+		assert.Len(t, dps, 2)
+		if len(dps) == 2 {
+			assert.Equal(t, "ifcounters.in-octets", dps[0].Metric)
+			assert.Equal(t, "ifcounters.out-octets", dps[1].Metric)
+			assert.Equal(t, 1.5, dps[1].Value)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		close(done)
+	}))
+	defer server.Close()
+
+	cfg := config.NewMockConfig()
+	ch := make(telemetry.ExtDSChan, 10)
+
+	dbCfg := config.Database{Name: "opentsdb1", Service: "opentsdb", Config: map[string]interface{}{
+		"server":    server.URL,
+		"template":  "{topic}.{key}",
+		"batchSize": 2,
+		"username":  "user",
+		"password":  "pass",
+	}}
+
+	db := New(dbCfg, cfg.Logger(), ch)
+	go db.Start(ctx)
+
+	for _, v := range []telemetry.DataStore{
+		{"prefix": "/interfaces/interface/state/counters/", "system_id": "core1.bur", "timestamp": 1595768623436661269, "key": "in-octets", "value": 10},
+		{"prefix": "/interfaces/interface/state/counters/", "system_id": "core1.bur", "timestamp": 1595768623436661269, "key": "out-octets", "value": 1.5},
+	} {
+		ch <- telemetry.ExtDataStore{Output: "opentsdb1::ifcounters", DS: v}
+	}
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Error("time limit exceeded")
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	assert.Equal(t, "Ethernet3/1.0_in_use", sanitize("Ethernet3/1.0 in:use"))
+	assert.Equal(t, "interfaces.interface", sanitizeMetric("prefix", "/interfaces/interface/"))
+}
@@ -0,0 +1,459 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/kelseyhightower/envconfig"
+	"go.uber.org/zap"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/database"
+	"github.com/yahoo/panoptes-stream/database/tsdb/metric"
+	"github.com/yahoo/panoptes-stream/lifecycle"
+	"github.com/yahoo/panoptes-stream/secret"
+	"github.com/yahoo/panoptes-stream/status"
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+// Postgres represents PostgreSQL and TimescaleDB.
+type Postgres struct {
+	lifecycle.Database
+
+	ch      telemetry.ExtDSChan
+	logger  *zap.Logger
+	metrics *status.OutputMetrics
+	cfg     config.Database
+
+	connConfig *pgx.ConnConfig
+	conn       *pgx.Conn
+}
+
+type postgresConfig struct {
+	Server        string
+	Database      string
+	Username      string
+	Password      string
+	Table         string
+	Columns       columns
+	BatchSize     int
+	FlushInterval int
+	Timeout       int
+	MaxRetries    int
+
+	CreateTable       bool
+	Hypertable        bool
+	ChunkTimeInterval string
+
+	TLSConfig config.TLSConfig
+}
+
+// columns represents the column mapping, an empty
+// column name means the column is not written.
+type columns struct {
+	Timestamp string
+	SystemID  string `split_words:"true"`
+	Prefix    string
+	Key       string
+	Labels    string
+	Value     string
+	ValueText string `split_words:"true"`
+}
+
+// New returns a new postgres instance.
+func New(cfg config.Database, lg *zap.Logger, inChan telemetry.ExtDSChan) database.Database {
+	return &Postgres{
+		cfg:     cfg,
+		ch:      inChan,
+		logger:  lg,
+		metrics: status.NewOutputMetrics(cfg.Name),
+	}
+}
+
+// Start starts postgres ingestion.
+func (p *Postgres) Start(ctx context.Context) error {
+	return p.Run(ctx, p.start)
+}
+
+func (p *Postgres) start(ctx context.Context) error {
+	var flush bool
+
+	config, err := p.getConfig()
+	if err != nil {
+		return err
+	}
+
+	p.connConfig, err = getConnConfig(config)
+	if err != nil {
+		return err
+	}
+
+	defer p.close()
+
+	if config.CreateTable {
+		if err = p.createTable(ctx, config); err != nil {
+			return fmt.Errorf("create table: %v", err)
+		}
+	}
+
+	p.logger.Info("postgres", zap.String("name", p.cfg.Name), zap.String("server", p.connConfig.Host),
+		zap.String("database", p.connConfig.Database), zap.String("table", config.Table))
+
+	table := pgx.Identifier(strings.Split(config.Table, "."))
+	columnNames := config.Columns.names()
+	batch := make([][]interface{}, 0, config.BatchSize)
+	w := &lifecycle.Writer{
+		Lifecycle:  &p.Lifecycle,
+		Metrics:    p.metrics,
+		MaxRetries: config.MaxRetries,
+		Permanent:  isRejected,
+		OnError: func(err error) {
+			p.logger.Error("postgres", zap.String("event", "copy"), zap.Error(err))
+			if !isRejected(err) {
+				p.close()
+			}
+		},
+	}
+	write := func(ctx context.Context) (int, error) {
+		return 0, p.copy(ctx, table, columnNames, batch)
+	}
+
+	flushTicker := time.NewTicker(time.Duration(config.FlushInterval) * time.Second)
+	defer flushTicker.Stop()
+
+	for {
+		select {
+		case v, ok := <-p.ch:
+			if !ok {
+				// the channel is closed and drained
+				w.Write(ctx, len(batch), write)
+				return nil
+			}
+
+			p.metrics.DataIn.Inc()
+
+			r, err := getRow(&config.Columns, v.DS)
+			if err != nil {
+				p.logger.Error("postgres", zap.Error(err), zap.String("output", v.Output))
+				p.AddDropped(1)
+				p.metrics.Error(status.ErrorInvalid)
+				continue
+			}
+
+			batch = append(batch, r)
+
+		case req := <-p.ReloadRequest():
+			cfg := req.Database()
+			conf, err := (&Postgres{cfg: cfg}).getConfig()
+			if err == nil {
+				err = lifecycle.Reloadable(config, conf, lifecycle.BatchFields...)
+			}
+			if err == nil {
+				p.cfg, config, w.MaxRetries = cfg, conf, conf.MaxRetries
+				flushTicker.Reset(time.Duration(config.FlushInterval) * time.Second)
+			}
+			req.Reply(err)
+
+		case <-flushTicker.C:
+			if len(batch) > 0 {
+				flush = true
+			} else {
+				continue
+			}
+
+		case req := <-p.FlushRequest():
+			req <- w.Write(ctx, len(batch), write)
+			flush = false
+			if ctx.Err() == nil {
+				batch = batch[:0]
+			}
+			continue
+
+		case <-ctx.Done():
+			p.logger.Info("postgres", zap.String("event", "terminate"), zap.String("name", p.cfg.Name))
+			w.Final(len(batch), write)
+			return nil
+		}
+
+		if len(batch) >= config.BatchSize || flush {
+			w.Write(ctx, len(batch), write)
+			flush = false
+			if ctx.Err() == nil {
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// copy writes the rows through COPY protocol.
+func (p *Postgres) copy(ctx context.Context, table pgx.Identifier, columnNames []string, rows [][]interface{}) error {
+	if err := p.connect(ctx); err != nil {
+		return err
+	}
+
+	_, err := p.conn.CopyFrom(ctx, table, columnNames, pgx.CopyFromRows(rows))
+
+	return err
+}
+
+// isRejected reports whether the server rejected the data
+// (e.g. invalid value), it doesn't need to retry.
+func isRejected(err error) bool {
+	var e *pgconn.PgError
+	return errors.As(err, &e)
+}
+
+func (p *Postgres) connect(ctx context.Context) error {
+	if p.conn != nil {
+		return nil
+	}
+
+	conn, err := pgx.ConnectConfig(ctx, p.connConfig)
+	if err != nil {
+		return err
+	}
+
+	p.conn = conn
+
+	return nil
+}
+
+func (p *Postgres) close() {
+	if p.conn != nil {
+		p.conn.Close(context.Background())
+		p.conn = nil
+	}
+}
+
+// createTable creates the table and the hypertable
+// if they don't exist.
+func (p *Postgres) createTable(ctx context.Context, config *postgresConfig) error {
+	if err := p.connect(ctx); err != nil {
+		return err
+	}
+
+	if _, err := p.conn.Exec(ctx, getCreateTable(config)); err != nil {
+		return err
+	}
+
+	if !config.Hypertable {
+		return nil
+	}
+
+	_, err := p.conn.Exec(ctx, "SELECT create_hypertable($1, $2, if_not_exists => TRUE, chunk_time_interval => $3::interval)",
+		pgx.QuerySimpleProtocol(true), config.Table, config.Columns.Timestamp, config.ChunkTimeInterval)
+
+	return err
+}
+
+// getCreateTable returns the idempotent create table statement.
+func getCreateTable(config *postgresConfig) string {
+	var defs []string
+
+	c := config.Columns
+	for _, col := range []struct{ name, typeName string }{
+		{c.Timestamp, "TIMESTAMPTZ NOT NULL"},
+		{c.SystemID, "TEXT"},
+		{c.Prefix, "TEXT"},
+		{c.Key, "TEXT"},
+		{c.Labels, "JSONB"},
+		{c.Value, "DOUBLE PRECISION"},
+		{c.ValueText, "TEXT"},
+	} {
+		if col.name != "" {
+			defs = append(defs, pgx.Identifier{col.name}.Sanitize()+" "+col.typeName)
+		}
+	}
+
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)",
+		pgx.Identifier(strings.Split(config.Table, ".")).Sanitize(), strings.Join(defs, ", "))
+}
+
+// names returns the configured column names in the row order.
+func (c *columns) names() []string {
+	var names []string
+	for _, name := range []string{c.Timestamp, c.SystemID, c.Prefix, c.Key, c.Labels, c.Value, c.ValueText} {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// getRow returns the row values in the configured column order,
+// the numeric and boolean values write to the value column and the
+// others write to the value text column if it's configured.
+func getRow(c *columns, ds telemetry.DataStore) ([]interface{}, error) {
+	var (
+		value     interface{}
+		valueText interface{}
+	)
+
+	ts, ok := metric.Timestamp(ds["timestamp"], time.Nanosecond)
+	if !ok {
+		return nil, fmt.Errorf("invalid timestamp %v", ds["timestamp"])
+	}
+
+	if v, ok := metric.Value(ds["value"]); ok {
+		value = toFloat64(v)
+	} else if c.ValueText != "" {
+		switch v := ds["value"].(type) {
+		case string:
+			valueText = v
+		case []byte:
+			valueText = string(v)
+		case []interface{}, map[string]interface{}:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			valueText = string(b)
+		default:
+			return nil, fmt.Errorf("unsupported value type %T", v)
+		}
+	} else {
+		return nil, fmt.Errorf("unsupported value type %T", ds["value"])
+	}
+
+	labels, _ := ds["labels"].(map[string]string)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	row := make([]interface{}, 0, 7)
+	for _, col := range []struct {
+		name  string
+		value interface{}
+	}{
+		{c.Timestamp, time.Unix(0, ts).UTC()},
+		{c.SystemID, ds["system_id"]},
+		{c.Prefix, ds["prefix"]},
+		{c.Key, ds["key"]},
+		{c.Labels, labels},
+		{c.Value, value},
+		{c.ValueText, valueText},
+	} {
+		if col.name != "" {
+			row = append(row, col.value)
+		}
+	}
+
+	return row, nil
+}
+
+func toFloat64(v interface{}) float64 {
+	switch t := v.(type) {
+	case int64:
+		return float64(t)
+	case uint64:
+		return float64(t)
+	case float64:
+		return t
+	}
+
+	return 0
+}
+
+func getConnConfig(config *postgresConfig) (*pgx.ConnConfig, error) {
+	connConfig, err := pgx.ParseConfig(config.Server)
+	if err != nil {
+		return nil, err
+	}
+
+	username, password, err := secret.GetUsernamePassword(config.Username, config.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	if username != "" {
+		connConfig.User = username
+		connConfig.Password = password
+	}
+
+	if config.Database != "" {
+		connConfig.Database = config.Database
+	}
+
+	connConfig.ConnectTimeout = time.Duration(config.Timeout) * time.Second
+
+	if config.TLSConfig.Enabled {
+		tlsConfig, err := secret.GetTLSConfig(&config.TLSConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = connConfig.Host
+		}
+
+		connConfig.TLSConfig = tlsConfig
+		// no plaintext fallback
+		connConfig.Fallbacks = nil
+	}
+
+	return connConfig, nil
+}
+
+func (p *Postgres) getConfig() (*postgresConfig, error) {
+	conf := new(postgresConfig)
+	b, err := json.Marshal(p.cfg.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(b, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := "panoptes_database_" + p.cfg.Name
+	err = envconfig.Process(prefix, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	config.SetDefault(&conf.BatchSize, 5000)
+	config.SetDefault(&conf.FlushInterval, 1)
+	config.SetDefault(&conf.Timeout, 10)
+	config.SetDefault(&conf.MaxRetries, 2)
+
+	if conf.Table == "" {
+		conf.Table = "panoptes"
+	}
+
+	if conf.ChunkTimeInterval == "" {
+		conf.ChunkTimeInterval = "1 day"
+	}
+
+	if conf.Columns == (columns{}) {
+		conf.Columns = columns{
+			Timestamp: "time",
+			SystemID:  "system_id",
+			Prefix:    "prefix",
+			Key:       "key",
+			Labels:    "labels",
+			Value:     "value",
+			ValueText: "value_text",
+		}
+	}
+
+	if conf.Columns.Timestamp == "" || conf.Columns.Value == "" {
+		return nil, errors.New("timestamp and value columns are required")
+	}
+
+	if conf.Server == "" {
+		return nil, errors.New("server is empty")
+	}
+
+	return conf, nil
+}
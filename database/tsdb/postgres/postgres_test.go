@@ -0,0 +1,274 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package postgres
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+// stubServer is a minimal PostgreSQL server that accepts
+// simple queries, prepare statements and COPY FROM STDIN.
+type stubServer struct {
+	ln      net.Listener
+	queries chan string
+	copies  chan [][][]byte
+}
+
+func newStubServer(t *testing.T) *stubServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &stubServer{
+		ln:      ln,
+		queries: make(chan string, 10),
+		copies:  make(chan [][][]byte, 10),
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.handle(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *stubServer) addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *stubServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	cr := pgproto3.NewChunkReader(conn)
+	backend := pgproto3.NewBackend(cr, conn)
+	if _, err := backend.ReceiveStartupMessage(); err != nil {
+		return
+	}
+
+	backend.Send(&pgproto3.AuthenticationOk{})
+	backend.Send(&pgproto3.ParameterStatus{Name: "standard_conforming_strings", Value: "on"})
+	backend.Send(&pgproto3.ParameterStatus{Name: "client_encoding", Value: "UTF8"})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+
+	for {
+		msg, err := backend.Receive()
+		if err != nil {
+			return
+		}
+
+		switch m := msg.(type) {
+		case *pgproto3.Query:
+			if strings.HasPrefix(m.String, "copy") {
+				backend.Send(&pgproto3.CopyInResponse{OverallFormat: 1, ColumnFormatCodes: []uint16{1, 1, 1, 1, 1, 1, 1}})
+				tuples, err := receiveCopy(cr)
+				if err != nil {
+					return
+				}
+				s.copies <- tuples
+				backend.Send(&pgproto3.CommandComplete{CommandTag: []byte(fmt.Sprintf("COPY %d", len(tuples)))})
+				backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+				continue
+			}
+			s.queries <- m.String
+			backend.Send(&pgproto3.CommandComplete{CommandTag: []byte("OK")})
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+		case *pgproto3.Parse:
+			backend.Send(&pgproto3.ParseComplete{})
+		case *pgproto3.Describe:
+			backend.Send(&pgproto3.ParameterDescription{})
+			backend.Send(&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+				{Name: []byte("time"), DataTypeOID: pgtype.TimestamptzOID, DataTypeSize: 8},
+				{Name: []byte("system_id"), DataTypeOID: pgtype.TextOID, DataTypeSize: -1},
+				{Name: []byte("prefix"), DataTypeOID: pgtype.TextOID, DataTypeSize: -1},
+				{Name: []byte("key"), DataTypeOID: pgtype.TextOID, DataTypeSize: -1},
+				{Name: []byte("labels"), DataTypeOID: pgtype.JSONBOID, DataTypeSize: -1},
+				{Name: []byte("value"), DataTypeOID: pgtype.Float8OID, DataTypeSize: 8},
+				{Name: []byte("value_text"), DataTypeOID: pgtype.TextOID, DataTypeSize: -1},
+			}})
+		case *pgproto3.Sync:
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+		case *pgproto3.Terminate:
+			return
+		}
+	}
+}
+
+// receiveCopy reads the copy data messages until copy done,
+// the backend doesn't decode the copy messages.
+func receiveCopy(cr pgproto3.ChunkReader) ([][][]byte, error) {
+	var data []byte
+
+	for {
+		header, err := cr.Next(5)
+		if err != nil {
+			return nil, err
+		}
+
+		msgType := header[0]
+		body, err := cr.Next(int(binary.BigEndian.Uint32(header[1:])) - 4)
+		if err != nil {
+			return nil, err
+		}
+
+		switch msgType {
+		case 'd':
+			data = append(data, body...)
+		case 'c':
+			return decodeCopy(data), nil
+		default:
+			return nil, fmt.Errorf("unexpected message type %c", msgType)
+		}
+	}
+}
+
+// decodeCopy decodes the binary COPY data to the tuples fields.
+func decodeCopy(b []byte) [][][]byte {
+	var tuples [][][]byte
+
+	// signature, flags and header extension
+	b = b[19:]
+
+	for len(b) >= 2 {
+		n := int16(binary.BigEndian.Uint16(b))
+		b = b[2:]
+		if n < 0 {
+			break
+		}
+
+		tuple := make([][]byte, n)
+		for i := range tuple {
+			l := int32(binary.BigEndian.Uint32(b))
+			b = b[4:]
+			if l < 0 {
+				continue
+			}
+			tuple[i] = b[:l]
+			b = b[l:]
+		}
+		tuples = append(tuples, tuple)
+	}
+
+	return tuples
+}
+
+func TestGetRow(t *testing.T) {
+	c := &columns{Timestamp: "time", Key: "key", Labels: "labels", Value: "value", ValueText: "value_text"}
+
+	ds := telemetry.DataStore{
+		"prefix":    "/interfaces/interface/state/counters/",
+		"labels":    map[string]string{"name": "Ethernet3"},
+		"system_id": "core1.bur",
+		"timestamp": int64(1595768623436661269),
+		"key":       "in-octets",
+		"value":     uint64(10),
+	}
+
+	row, err := getRow(c, ds)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{time.Unix(0, 1595768623436661269).UTC(), "in-octets", map[string]string{"name": "Ethernet3"}, float64(10), nil}, row)
+
+	ds["key"], ds["value"] = "oper-status", "UP"
+	row, err = getRow(c, ds)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{time.Unix(0, 1595768623436661269).UTC(), "oper-status", map[string]string{"name": "Ethernet3"}, nil, "UP"}, row)
+
+	// without value text column
+	c.ValueText = ""
+	_, err = getRow(c, ds)
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"time", "key", "labels", "value"}, c.names())
+}
+
+func TestStart(t *testing.T) {
+	server := newStubServer(t)
+	defer server.ln.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.NewMockConfig()
+	ch := make(telemetry.ExtDSChan, 10)
+
+	dbCfg := config.Database{Name: "postgres1", Service: "postgres", Config: map[string]interface{}{
+		"server":      fmt.Sprintf("postgres://%s/panoptes?sslmode=disable", server.addr()),
+		"username":    "panoptes",
+		"password":    "secret",
+		"batchSize":   2,
+		"createTable": true,
+		"hypertable":  true,
+	}}
+
+	db := New(dbCfg, cfg.Logger(), ch)
+	go db.Start(ctx)
+
+	for _, expected := range []string{
+		`CREATE TABLE IF NOT EXISTS "panoptes" ("time" TIMESTAMPTZ NOT NULL, "system_id" TEXT, "prefix" TEXT, "key" TEXT, "labels" JSONB, "value" DOUBLE PRECISION, "value_text" TEXT)`,
+		`SELECT create_hypertable('panoptes', 'time', if_not_exists => TRUE, chunk_time_interval => '1 day'::interval)`,
+	} {
+		select {
+		case query := <-server.queries:
+			assert.Equal(t, expected, query)
+		case <-time.After(3 * time.Second):
+			t.Fatal("time limit exceeded")
+		}
+	}
+
+	for _, v := range []telemetry.DataStore{
+		{"prefix": "/interfaces/interface/state/counters/", "labels": map[string]string{"name": "Ethernet3"}, "system_id": "core1.bur", "timestamp": 1595768623436661269, "key": "in-octets", "value": 10},
+		{"prefix": "/interfaces/interface/state/counters/", "labels": map[string]string{"name": "Ethernet3"}, "system_id": "core1.bur", "timestamp": 1595768623436661269, "key": "oper-status", "value": "UP"},
+	} {
+		ch <- telemetry.ExtDataStore{Output: "postgres1::ifcounters", DS: v}
+	}
+
+	select {
+	case tuples := <-server.copies:
+		require.Len(t, tuples, 2)
+		assert.Equal(t, "core1.bur", string(tuples[0][1]))
+		assert.Equal(t, "in-octets", string(tuples[0][3]))
+		// jsonb binary format version 1
+		assert.Equal(t, "\x01{\"name\":\"Ethernet3\"}", string(tuples[0][4]))
+		assert.Nil(t, tuples[0][6])
+		assert.Nil(t, tuples[1][5])
+		assert.Equal(t, "UP", string(tuples[1][6]))
+	case <-time.After(3 * time.Second):
+		t.Fatal("time limit exceeded")
+	}
+}
+
+func TestConfig(t *testing.T) {
+	p := &Postgres{cfg: config.Database{Name: "postgres1", Config: map[string]interface{}{
+		"server":  "postgres://127.0.0.1/panoptes",
+		"table":   "metrics.telemetry",
+		"columns": map[string]interface{}{"timestamp": "ts", "value": "v", "systemId": "host"},
+	}}}
+
+	conf, err := p.getConfig()
+	require.NoError(t, err)
+	assert.Equal(t, columns{Timestamp: "ts", Value: "v", SystemID: "host"}, conf.Columns)
+	assert.Equal(t, `CREATE TABLE IF NOT EXISTS "metrics"."telemetry" ("ts" TIMESTAMPTZ NOT NULL, "host" TEXT, "v" DOUBLE PRECISION)`, getCreateTable(conf))
+
+	p.cfg.Config = map[string]interface{}{"server": "postgres://127.0.0.1/panoptes", "columns": map[string]interface{}{"key": "key"}}
+	_, err = p.getConfig()
+	assert.Error(t, err)
+}
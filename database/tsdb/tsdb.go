@@ -5,10 +5,18 @@ package tsdb
 
 import (
 	"github.com/yahoo/panoptes-stream/database"
+	"github.com/yahoo/panoptes-stream/database/tsdb/clickhouse"
+	"github.com/yahoo/panoptes-stream/database/tsdb/graphite"
 	"github.com/yahoo/panoptes-stream/database/tsdb/influxdb"
+	"github.com/yahoo/panoptes-stream/database/tsdb/opentsdb"
+	"github.com/yahoo/panoptes-stream/database/tsdb/postgres"
 )
 
 // Register registers databases to database registrar
 func Register(databaseRegistrar *database.Registrar) {
 	databaseRegistrar.Register("influxdb", "influxdata.com", influxdb.New)
+	databaseRegistrar.Register("graphite", "graphiteapp.org", graphite.New)
+	databaseRegistrar.Register("opentsdb", "opentsdb.net", opentsdb.New)
+	databaseRegistrar.Register("clickhouse", "clickhouse.tech", clickhouse.New)
+	databaseRegistrar.Register("postgres", "postgresql.org", postgres.New)
 }
@@ -7,17 +7,33 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/yahoo/panoptes-stream/cache"
 	"github.com/yahoo/panoptes-stream/config"
 	"github.com/yahoo/panoptes-stream/database"
+	"github.com/yahoo/panoptes-stream/lifecycle"
 	"github.com/yahoo/panoptes-stream/producer"
+	"github.com/yahoo/panoptes-stream/status"
 	"github.com/yahoo/panoptes-stream/telemetry"
 )
 
+var (
+	minBackoff    = time.Second
+	maxBackoff    = time.Minute
+	drainTimeout  = 30 * time.Second
+	reloadTimeout = 5 * time.Second
+
+	errReloadTimeout = errors.New("reload timeout")
+	errNotStarted    = errors.New("demux not started")
+)
+
 // Demux manages instances of producer/database and
 // routes metrics to appropriate channels.
 type Demux struct {
@@ -29,11 +45,41 @@ type Demux struct {
 	pr        *producer.Registrar
 	db        *database.Registrar
 	mq        *MQ
-	register  map[string]context.CancelFunc
+	sinks     *sinkMap
+	taps      *tapMap
+	cache     *cache.Cache
+	draining  sync.WaitGroup
+	started   int32
 	producers map[string]config.Producer
 	databases map[string]config.Database
 }
 
+// sink represents the common lifecycle of producers and databases.
+type sink interface {
+	Start(context.Context) error
+	Stop()
+	Flush() error
+	Health() error
+	Stats() lifecycle.Stats
+}
+
+// sinkState represents a running producer or database.
+type sinkState struct {
+	sink
+
+	name     string
+	service  string
+	kind     string
+	restarts uint64
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+type sinkMap struct {
+	sync.RWMutex
+	sinks map[string]*sinkState
+}
+
 type extDSChanMap struct {
 	sync.RWMutex
 	eDSChan map[string]telemetry.ExtDSChan
@@ -44,12 +90,13 @@ func New(ctx context.Context, cfg config.Config, pr *producer.Registrar, db *dat
 	return &Demux{
 		ctx:       ctx,
 		cfg:       cfg,
-		logger:    cfg.Logger(),
+		logger:    config.NamedLogger(cfg.Logger(), "demux"),
 		pr:        pr,
 		db:        db,
 		inChan:    inChan,
 		chMap:     &extDSChanMap{eDSChan: make(map[string]telemetry.ExtDSChan)},
-		register:  make(map[string]context.CancelFunc),
+		sinks:     &sinkMap{sinks: make(map[string]*sinkState)},
+		taps:      newTapMap(),
 		producers: make(map[string]config.Producer),
 		databases: make(map[string]config.Database),
 	}
@@ -75,6 +122,11 @@ func (d *Demux) init() error {
 	return nil
 }
 
+// SetCache sets the latest value cache, it should be called before Start.
+func (d *Demux) SetCache(c *cache.Cache) {
+	d.cache = c
+}
+
 // Start starts demux.
 func (d *Demux) Start() {
 	d.init()
@@ -90,13 +142,18 @@ func (d *Demux) Start() {
 }
 
 func (d *Demux) start() {
-	var (
-		outChan telemetry.ExtDSChan
-		ok      bool
-	)
+	var extDS telemetry.ExtDataStore
+
+	atomic.StoreInt32(&d.started, 1)
+	defer atomic.StoreInt32(&d.started, 0)
 
 	for {
-		extDS := <-d.inChan
+		select {
+		case extDS = <-d.inChan:
+		case <-d.ctx.Done():
+			d.logger.Info("demux has been terminated")
+			return
+		}
 
 		output := strings.Split(extDS.Output, "::")
 		if len(output) < 2 {
@@ -104,32 +161,33 @@ func (d *Demux) start() {
 			continue
 		}
 
-		if outChan, ok = d.chMap.get(output[0]); !ok {
+		d.taps.send(extDS, output[0])
+
+		if d.cache != nil {
+			d.cache.Set(extDS.DS)
+		}
+
+		found, sent := d.chMap.send(output[0], extDS)
+		if !found {
 			d.logger.Error("demux", zap.String("error", "channel not found"), zap.String("name", output[0]))
 			continue
 		}
 
-		select {
-		case outChan <- extDS:
-
-		case <-d.ctx.Done():
-			d.logger.Info("demux has been terminated")
-			return
-
-		default:
-			if d.mq != nil {
-				d.mq.publish(extDS, output[0])
-				continue
-			}
+		if sent {
+			continue
+		}
 
-			d.logger.Warn("demux", zap.String("error", "dataset drop"), zap.String("name", output[0]))
+		if d.mq != nil {
+			d.mq.publish(extDS, output[0])
+			continue
 		}
+
+		d.logger.Warn("demux", zap.String("error", "dataset drop"), zap.String("name", output[0]))
+		status.OutputError(output[0], status.ErrorOverflow)
 	}
 }
 
 func (d *Demux) subscribeProducer(producer config.Producer) error {
-	var ctx context.Context
-
 	new, ok := d.pr.GetProducerFactory(producer.Service)
 	if !ok {
 		return errors.New("producer not exist")
@@ -145,19 +203,16 @@ func (d *Demux) subscribeProducer(producer config.Producer) error {
 	ch := make(telemetry.ExtDSChan, d.cfg.Global().OutputBufferSize)
 	// register channel
 	d.chMap.add(producer.Name, ch)
-	// register cancelFunnc
-	ctx, d.register[producer.Name] = context.WithCancel(d.ctx)
+	status.SetOutputChannel(producer.Name, func() int { return len(ch) }, cap(ch))
 	// construct
-	p := new(ctx, producer, d.logger, ch)
+	p := new(producer, d.sinkLogger("producer", producer.Name), ch)
 	// start the producer
-	go p.Start()
+	d.startSink(&sinkState{sink: p, name: producer.Name, service: producer.Service, kind: "producer"})
 
 	return nil
 }
 
 func (d *Demux) subscribeDatabase(database config.Database) error {
-	var ctx context.Context
-
 	new, ok := d.db.GetDatabaseFactory(database.Service)
 	if !ok {
 		d.logger.Info(database.Service)
@@ -174,38 +229,269 @@ func (d *Demux) subscribeDatabase(database config.Database) error {
 	ch := make(telemetry.ExtDSChan, d.cfg.Global().OutputBufferSize)
 	// register channel
 	d.chMap.add(database.Name, ch)
-	// register cancelFunnc
-	ctx, d.register[database.Name] = context.WithCancel(d.ctx)
+	status.SetOutputChannel(database.Name, func() int { return len(ch) }, cap(ch))
 	// construct
-	db := new(ctx, database, d.logger, ch)
+	db := new(database, d.sinkLogger("database", database.Name), ch)
 	// start the database agent
-	go db.Start()
+	d.startSink(&sinkState{sink: db, name: database.Name, service: database.Service, kind: "database"})
 
 	return nil
 }
 
+// sinkLogger returns the producer or database logger, e.g. producer.kafka1.
+func (d *Demux) sinkLogger(kind, name string) *zap.Logger {
+	return config.NamedLogger(config.NamedLogger(d.cfg.Logger(), kind), name)
+}
+
 func (d *Demux) unsubscribeProducer(producer config.Producer) {
-	if _, ok := d.register[producer.Name]; !ok {
+	s, ok := d.sinks.get(producer.Name)
+	if !ok {
 		d.logger.Error("demux", zap.String("event", "unavailable"), zap.String("name", producer.Name))
 		return
 	}
 
-	d.register[producer.Name]()
 	delete(d.producers, producer.Name)
-	delete(d.register, producer.Name)
-	d.chMap.del(producer.Name)
+	d.sinks.del(producer.Name)
+	ch, _ := d.chMap.del(producer.Name)
+	d.drain(s, ch, true)
 }
 
 func (d *Demux) unsubscribeDatabase(database config.Database) {
-	if _, ok := d.register[database.Name]; !ok {
+	s, ok := d.sinks.get(database.Name)
+	if !ok {
 		d.logger.Error("demux", zap.String("event", "unavailable"), zap.String("name", database.Name))
 		return
 	}
 
-	d.register[database.Name]()
 	delete(d.databases, database.Name)
-	delete(d.register, database.Name)
-	d.chMap.del(database.Name)
+	d.sinks.del(database.Name)
+	ch, _ := d.chMap.del(database.Name)
+	d.drain(s, ch, true)
+}
+
+// reloadProducer applies the producer configuration changes in place
+// if the producer supports it, otherwise it replaces the producer.
+func (d *Demux) reloadProducer(p config.Producer) {
+	s, ok := d.sinks.get(p.Name)
+	if !ok {
+		d.logger.Error("demux", zap.String("event", "unavailable"), zap.String("name", p.Name))
+		return
+	}
+
+	if r, ok := s.sink.(producer.Reloader); ok && d.producers[p.Name].Service == p.Service {
+		err := reload(func() error { return r.Reload(p) })
+		if err == nil {
+			d.producers[p.Name] = p
+			d.logger.Info("demux", zap.String("event", "reload"), zap.String("name", p.Name), zap.String("type", s.kind))
+			return
+		}
+
+		d.logger.Info("demux", zap.String("event", "replace"), zap.String("name", p.Name), zap.String("type", s.kind), zap.Error(err))
+	}
+
+	new, ok := d.pr.GetProducerFactory(p.Service)
+	if !ok {
+		d.logger.Error("demux", zap.String("event", "replace"), zap.String("name", p.Name), zap.String("error", "producer not exist"))
+		d.unsubscribeProducer(p)
+		return
+	}
+
+	d.producers[p.Name] = p
+	ch := make(telemetry.ExtDSChan, d.cfg.Global().OutputBufferSize)
+	d.replace(s, &sinkState{sink: new(p, d.sinkLogger("producer", p.Name), ch), name: p.Name, service: p.Service, kind: "producer"}, ch)
+}
+
+// reloadDatabase applies the database configuration changes in place
+// if the database supports it, otherwise it replaces the database.
+func (d *Demux) reloadDatabase(db config.Database) {
+	s, ok := d.sinks.get(db.Name)
+	if !ok {
+		d.logger.Error("demux", zap.String("event", "unavailable"), zap.String("name", db.Name))
+		return
+	}
+
+	if r, ok := s.sink.(database.Reloader); ok && d.databases[db.Name].Service == db.Service {
+		err := reload(func() error { return r.Reload(db) })
+		if err == nil {
+			d.databases[db.Name] = db
+			d.logger.Info("demux", zap.String("event", "reload"), zap.String("name", db.Name), zap.String("type", s.kind))
+			return
+		}
+
+		d.logger.Info("demux", zap.String("event", "replace"), zap.String("name", db.Name), zap.String("type", s.kind), zap.Error(err))
+	}
+
+	new, ok := d.db.GetDatabaseFactory(db.Service)
+	if !ok {
+		d.logger.Error("demux", zap.String("event", "replace"), zap.String("name", db.Name), zap.String("error", "database not exist"))
+		d.unsubscribeDatabase(db)
+		return
+	}
+
+	d.databases[db.Name] = db
+	ch := make(telemetry.ExtDSChan, d.cfg.Global().OutputBufferSize)
+	d.replace(s, &sinkState{sink: new(db, d.sinkLogger("database", db.Name), ch), name: db.Name, service: db.Service, kind: "database"}, ch)
+}
+
+// reload runs the sink reload and returns an error
+// if the sink doesn't reply within the reload timeout.
+func reload(f func() error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- f()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(reloadTimeout):
+		return errReloadTimeout
+	}
+}
+
+// replace starts the new sink, switches the channels
+// and then drains the old sink.
+func (d *Demux) replace(old, new *sinkState, ch telemetry.ExtDSChan) {
+	d.startSink(new)
+	oldCh, _ := d.chMap.swap(new.name, ch)
+	status.SetOutputChannel(new.name, func() int { return len(ch) }, cap(ch))
+	d.drain(old, oldCh, false)
+}
+
+// drain closes the channel that nothing sends to anymore, the sink
+// writes the buffered data and returns. The sink is canceled if it
+// doesn't return within the drain timeout. The metrics of the
+// unsubscribed sink are deleted once it returns unless its output
+// is subscribed again meanwhile.
+func (d *Demux) drain(s *sinkState, ch telemetry.ExtDSChan, unsubscribe bool) {
+	if ch == nil {
+		s.cancel()
+	} else {
+		close(ch)
+	}
+
+	d.draining.Add(1)
+	go func() {
+		defer d.draining.Done()
+
+		select {
+		case <-s.done:
+		case <-time.After(drainTimeout):
+			d.logger.Warn("demux", zap.String("event", "drain"), zap.String("name", s.name), zap.String("error", "timeout"))
+			s.cancel()
+			<-s.done
+		}
+
+		s.cancel()
+
+		if _, ok := d.sinks.get(s.name); unsubscribe && !ok {
+			status.DeleteOutputMetrics(s.name)
+		}
+	}()
+}
+
+// startSink runs the producer or database under supervision.
+func (d *Demux) startSink(s *sinkState) {
+	var ctx context.Context
+
+	ctx, s.cancel = context.WithCancel(d.ctx)
+	s.done = make(chan struct{})
+	d.sinks.add(s.name, s)
+
+	go d.supervise(ctx, s)
+}
+
+// supervise runs the producer or database and restarts it with
+// exponential backoff if it fails until the context is canceled.
+// The backoff resets if the sink was running longer than max backoff.
+func (d *Demux) supervise(ctx context.Context, s *sinkState) {
+	defer close(s.done)
+
+	backoff := minBackoff
+
+	for {
+		start := time.Now()
+
+		err := s.Start(ctx)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		if time.Since(start) > maxBackoff {
+			backoff = minBackoff
+		}
+
+		atomic.AddUint64(&s.restarts, 1)
+		d.logger.Error("demux", zap.String("event", "restart"), zap.String("name", s.name), zap.String("type", s.kind),
+			zap.Duration("backoff", backoff), zap.Error(err))
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// Stop stops the producers and databases after they write the buffered data.
+func (d *Demux) Stop() {
+	var wg sync.WaitGroup
+
+	for _, s := range d.sinks.list() {
+		wg.Add(1)
+		go func(s *sinkState) {
+			defer wg.Done()
+			s.Stop()
+			s.cancel()
+			<-s.done
+		}(s)
+	}
+
+	wg.Wait()
+	d.draining.Wait()
+}
+
+// SinkHealth returns the producers and databases health sorted by name.
+func (d *Demux) SinkHealth() []status.SinkHealth {
+	var health []status.SinkHealth
+
+	for _, s := range d.sinks.list() {
+		h := status.SinkHealth{
+			Name:     s.name,
+			Service:  s.service,
+			Type:     s.kind,
+			Healthy:  true,
+			Restarts: atomic.LoadUint64(&s.restarts),
+			Stats:    s.Stats(),
+		}
+
+		if err := s.Health(); err != nil {
+			h.Healthy = false
+			h.Error = err.Error()
+		}
+
+		health = append(health, h)
+	}
+
+	sort.Slice(health, func(i, j int) bool {
+		return health[i].Name < health[j].Name
+	})
+
+	return health
+}
+
+// Ready returns nil if demux routes the data points, it's a readiness check.
+func (d *Demux) Ready() error {
+	if atomic.LoadInt32(&d.started) == 0 {
+		return errNotStarted
+	}
+
+	return nil
 }
 
 // Update updates databases and producers.
@@ -254,8 +540,7 @@ func (d *Demux) updateDatabase() {
 	}
 
 	for _, database := range delta.mod {
-		d.unsubscribeDatabase(database)
-		d.subscribeDatabase(database)
+		d.reloadDatabase(database)
 	}
 }
 
@@ -295,8 +580,7 @@ func (d *Demux) updateProducer() {
 	}
 
 	for _, producer := range delta.mod {
-		d.unsubscribeProducer(producer)
-		d.subscribeProducer(producer)
+		d.reloadProducer(producer)
 	}
 }
 
@@ -313,10 +597,43 @@ func (e *extDSChanMap) add(key string, value telemetry.ExtDSChan) {
 	e.eDSChan[key] = value
 }
 
-func (e *extDSChanMap) del(key string) {
+// send sends the data to the channel without blocking,
+// it reports whether the channel exists and the data sent.
+func (e *extDSChanMap) send(key string, v telemetry.ExtDataStore) (bool, bool) {
+	e.RLock()
+	defer e.RUnlock()
+
+	ch, ok := e.eDSChan[key]
+	if !ok {
+		return false, false
+	}
+
+	select {
+	case ch <- v:
+		return true, true
+	default:
+		return true, false
+	}
+}
+
+// swap replaces the channel and returns the old one, nothing
+// sends to the old channel once swap returns.
+func (e *extDSChanMap) swap(key string, value telemetry.ExtDSChan) (telemetry.ExtDSChan, bool) {
+	e.Lock()
+	defer e.Unlock()
+	old, ok := e.eDSChan[key]
+	e.eDSChan[key] = value
+	return old, ok
+}
+
+// del deletes the channel and returns it, nothing
+// sends to the channel once del returns.
+func (e *extDSChanMap) del(key string) (telemetry.ExtDSChan, bool) {
 	e.Lock()
 	defer e.Unlock()
+	v, ok := e.eDSChan[key]
 	delete(e.eDSChan, key)
+	return v, ok
 }
 
 func (e *extDSChanMap) list() []string {
@@ -329,3 +646,33 @@ func (e *extDSChanMap) list() []string {
 
 	return r
 }
+
+func (s *sinkMap) get(name string) (*sinkState, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	v, ok := s.sinks[name]
+	return v, ok
+}
+
+func (s *sinkMap) add(name string, v *sinkState) {
+	s.Lock()
+	defer s.Unlock()
+	s.sinks[name] = v
+}
+
+func (s *sinkMap) del(name string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.sinks, name)
+}
+
+func (s *sinkMap) list() []*sinkState {
+	s.RLock()
+	defer s.RUnlock()
+	r := make([]*sinkState, 0, len(s.sinks))
+	for _, v := range s.sinks {
+		r = append(r, v)
+	}
+
+	return r
+}
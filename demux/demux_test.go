@@ -5,15 +5,22 @@ package demux
 
 import (
 	"context"
+	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
 
+	"github.com/yahoo/panoptes-stream/cache"
 	"github.com/yahoo/panoptes-stream/config"
 	"github.com/yahoo/panoptes-stream/database"
+	"github.com/yahoo/panoptes-stream/lifecycle"
 	"github.com/yahoo/panoptes-stream/producer"
 	"github.com/yahoo/panoptes-stream/register"
+	"github.com/yahoo/panoptes-stream/status"
 	"github.com/yahoo/panoptes-stream/telemetry"
 )
 
@@ -143,9 +150,11 @@ func TestUpdateDatabase(t *testing.T) {
 		},
 	}
 	_, cancel := context.WithCancel(context.Background())
-	d.register = map[string]context.CancelFunc{
-		"influx01": cancel,
-	}
+	done := make(chan struct{})
+	close(done)
+	d.sinks = &sinkMap{sinks: map[string]*sinkState{
+		"influx01": {name: "influx01", cancel: cancel, done: done},
+	}}
 	ch := make(telemetry.ExtDSChan)
 	d.chMap = &extDSChanMap{eDSChan: make(map[string]telemetry.ExtDSChan)}
 	d.chMap.add("influx01", ch)
@@ -166,9 +175,11 @@ func TestUpdateProducer(t *testing.T) {
 		},
 	}
 	_, cancel := context.WithCancel(context.Background())
-	d.register = map[string]context.CancelFunc{
-		"kafka01": cancel,
-	}
+	done := make(chan struct{})
+	close(done)
+	d.sinks = &sinkMap{sinks: map[string]*sinkState{
+		"kafka01": {name: "kafka01", cancel: cancel, done: done},
+	}}
 	ch := make(telemetry.ExtDSChan)
 	d.chMap = &extDSChanMap{eDSChan: make(map[string]telemetry.ExtDSChan)}
 	d.chMap.add("kafka01", ch)
@@ -177,6 +188,226 @@ func TestUpdateProducer(t *testing.T) {
 	assert.Equal(t, 0, len(d.producers))
 }
 
+// mockSink fails the first starts and then runs until
+// the context is canceled.
+type mockSink struct {
+	lifecycle.Lifecycle
+
+	failures int32
+	starts   int32
+}
+
+func (m *mockSink) Start(ctx context.Context) error {
+	return m.Run(ctx, func(ctx context.Context) error {
+		if atomic.AddInt32(&m.starts, 1) <= m.failures {
+			return errors.New("connection refused")
+		}
+
+		<-ctx.Done()
+		return nil
+	})
+}
+
+// drainSink writes the data out once it's released after its channel closed.
+type drainSink struct {
+	lifecycle.Lifecycle
+
+	ch      telemetry.ExtDSChan
+	release chan struct{}
+	metrics *status.OutputMetrics
+}
+
+func (s *drainSink) Start(ctx context.Context) error {
+	return s.Run(ctx, func(ctx context.Context) error {
+		for range s.ch {
+		}
+
+		<-s.release
+		s.metrics.DataOut.Inc()
+
+		return nil
+	})
+}
+
+func outputDataOut(t *testing.T, name string) float64 {
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	assert.NoError(t, err)
+
+	for _, mf := range mfs {
+		if mf.GetName() != "panoptes_output_data_out_total" {
+			continue
+		}
+
+		for _, m := range mf.Metric {
+			for _, l := range m.Label {
+				if l.GetName() == "output" && l.GetValue() == name {
+					return m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	return -1
+}
+
+func TestUnsubscribeMetrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := New(ctx, cfg, nil, nil, nil)
+	p := config.Producer{Name: "drain1", Service: "drain"}
+
+	ch := make(telemetry.ExtDSChan, 1)
+	sink := &drainSink{ch: ch, release: make(chan struct{}), metrics: status.NewOutputMetrics(p.Name)}
+	d.producers[p.Name] = p
+	d.chMap.add(p.Name, ch)
+	d.startSink(&sinkState{sink: sink, name: p.Name, service: p.Service, kind: "producer"})
+
+	sink.metrics.DataOut.Inc()
+	d.unsubscribeProducer(p)
+
+	// the metrics are available until the sink is drained
+	assert.Equal(t, float64(1), outputDataOut(t, p.Name))
+
+	close(sink.release)
+	d.draining.Wait()
+
+	assert.Equal(t, float64(-1), outputDataOut(t, p.Name))
+}
+
+func TestSupervise(t *testing.T) {
+	minBackoff, maxBackoff = time.Millisecond, 10*time.Millisecond
+	defer func() { minBackoff, maxBackoff = time.Second, time.Minute }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := New(ctx, cfg, nil, nil, nil)
+	m := &mockSink{failures: 3}
+	d.startSink(&sinkState{sink: m, name: "influx01", service: "influxdb", kind: "database"})
+
+	for i := 0; i < 100 && atomic.LoadInt32(&m.starts) < 4; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.Equal(t, int32(4), atomic.LoadInt32(&m.starts))
+	assert.Equal(t, []status.SinkHealth{{
+		Name:     "influx01",
+		Service:  "influxdb",
+		Type:     "database",
+		Healthy:  true,
+		Restarts: 3,
+	}}, d.SinkHealth())
+
+	d.Stop()
+
+	health := d.SinkHealth()
+	assert.False(t, health[0].Healthy)
+	assert.Equal(t, lifecycle.ErrNotRunning.Error(), health[0].Error)
+}
+
+// reloadSink applies the batch size changes in place and
+// records the received data until the channel is closed.
+type reloadSink struct {
+	lifecycle.Lifecycle
+
+	cfg      config.Database
+	ch       telemetry.ExtDSChan
+	received chan string
+}
+
+func (r *reloadSink) Start(ctx context.Context) error {
+	return r.Run(ctx, func(ctx context.Context) error {
+		for {
+			select {
+			case v, ok := <-r.ch:
+				if !ok {
+					return nil
+				}
+				r.received <- v.Output
+			case req := <-r.ReloadRequest():
+				cfg := req.Config.(config.Database)
+				if server(cfg) != server(r.cfg) {
+					req.Reply(lifecycle.ErrRestartRequired)
+					continue
+				}
+				req.Reply(nil)
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	})
+}
+
+func server(cfg config.Database) interface{} {
+	return cfg.Config.(map[string]interface{})["server"]
+}
+
+func (r *reloadSink) Reload(cfg config.Database) error {
+	return r.RequestReload(cfg)
+}
+
+func TestReload(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.NewMockConfig()
+	cfg.MGlobal.OutputBufferSize = 10
+
+	sinks := make(chan *reloadSink, 2)
+	databaseRegistrar := database.NewRegistrar(cfg.Logger())
+	databaseRegistrar.Register("mock", "-", func(c config.Database, lg *zap.Logger, ch telemetry.ExtDSChan) database.Database {
+		s := &reloadSink{cfg: c, ch: ch, received: make(chan string, 10)}
+		sinks <- s
+		return s
+	})
+
+	d := New(ctx, cfg, nil, databaseRegistrar, nil)
+
+	db := config.Database{Name: "db1", Service: "mock", Config: map[string]interface{}{"server": "127.0.0.1", "batchSize": 1}}
+	assert.NoError(t, d.subscribeDatabase(db))
+	old := <-sinks
+
+	for i := 0; i < 10 && old.Health() != nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// in place
+	db.Config = map[string]interface{}{"server": "127.0.0.1", "batchSize": 10}
+	cfg.MDatabases = []config.Database{db}
+	d.updateDatabase()
+
+	assert.Len(t, sinks, 0)
+	assert.Equal(t, db, d.databases["db1"])
+
+	// replace
+	found, sent := d.chMap.send("db1", telemetry.ExtDataStore{Output: "db1::old"})
+	assert.True(t, found && sent)
+
+	db.Config = map[string]interface{}{"server": "127.0.0.2", "batchSize": 10}
+	cfg.MDatabases = []config.Database{db}
+	d.updateDatabase()
+
+	new := <-sinks
+	assert.Equal(t, db, d.databases["db1"])
+	assert.Equal(t, "db1::old", <-old.received)
+
+	found, sent = d.chMap.send("db1", telemetry.ExtDataStore{Output: "db1::new"})
+	assert.True(t, found && sent)
+	assert.Equal(t, "db1::new", <-new.received)
+
+	// the old one is drained and the new one is running
+	d.draining.Wait()
+	assert.Equal(t, lifecycle.ErrNotRunning, old.Health())
+	assert.NoError(t, new.Health())
+
+	s, ok := d.sinks.get("db1")
+	assert.True(t, ok)
+	assert.Equal(t, new, s.sink)
+
+	d.Stop()
+}
+
 func BenchmarkDemux(b *testing.B) {
 	var (
 		outChan = make(telemetry.ExtDSChan, 1)
@@ -194,3 +425,51 @@ func BenchmarkDemux(b *testing.B) {
 		<-outChan
 	}
 }
+
+func TestReady(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cfg := config.NewMockConfig()
+	d := New(ctx, cfg, producer.NewRegistrar(cfg.Logger()), database.NewRegistrar(cfg.Logger()), make(telemetry.ExtDSChan))
+	assert.Equal(t, errNotStarted, d.Ready())
+
+	d.Start()
+	for i := 0; i < 10 && d.Ready() != nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.NoError(t, d.Ready())
+
+	cancel()
+	for i := 0; i < 10 && d.Ready() == nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, errNotStarted, d.Ready())
+}
+
+func TestCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inChan := make(telemetry.ExtDSChan, 1)
+	outChan := make(telemetry.ExtDSChan, 1)
+
+	cfg := config.NewMockConfig()
+	c := cache.New(cfg)
+
+	d := New(ctx, cfg, nil, nil, inChan)
+	d.chMap.add("console", outChan)
+	d.SetCache(c)
+	d.Start()
+
+	inChan <- telemetry.ExtDataStore{
+		Output: "console::stdout",
+		DS: telemetry.DataStore{
+			"system_id": "core1.lax",
+			"prefix":    "/interfaces/interface/state/counters/",
+			"key":       "out-octets",
+			"value":     uint64(10),
+		},
+	}
+
+	<-outChan
+	assert.Len(t, c.Get("core1.lax", nil), 1)
+}
@@ -39,8 +39,9 @@ type mqConfig struct {
 }
 
 type messageHandler struct {
-	ch  telemetry.ExtDSChan
-	err error
+	chMap *extDSChanMap
+	topic string
+	err   error
 }
 
 type noLogger struct{}
@@ -184,14 +185,14 @@ func (m *MQ) consumer(ctx context.Context, topic string) {
 
 	consumer.SetLogger(&noLogger{}, 0)
 
-	ch, ok := m.chMap.get(topic)
-	if !ok {
+	if _, ok := m.chMap.get(topic); !ok {
 		m.logger.Error("demux.mq", zap.String("topic", topic))
 	}
 
 	handler := &messageHandler{
-		ch:  ch,
-		err: errors.New("failed"),
+		chMap: m.chMap,
+		topic: topic,
+		err:   errors.New("failed"),
 	}
 
 	consumer.AddConcurrentHandlers(handler, 2)
@@ -211,9 +212,8 @@ func (h *messageHandler) HandleMessage(m *nsq.Message) error {
 	ds.DS["labels"] = labels
 	ds.DS["timestamp"] = int64(ds.DS["timestamp"].(float64))
 
-	select {
-	case h.ch <- ds:
-	default:
+	// the channel might be replaced or drained by reload
+	if _, sent := h.chMap.send(h.topic, ds); !sent {
 		return h.err
 	}
 
@@ -0,0 +1,187 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package demux
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yahoo/panoptes-stream/status"
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+var (
+	maxTaps        = 4
+	defaultTapRate = 10
+	maxTapRate     = 100
+	tapBufferSize  = 100
+)
+
+// tap represents an attached live data tap, it's only
+// accessed by the demux loop except the channel.
+type tap struct {
+	filter  status.TapFilter
+	key     *regexp.Regexp
+	ch      chan status.TapEvent
+	matched uint64
+	window  time.Time
+	sent    int
+}
+
+// tapMap holds the attached taps, the active counter
+// skips the taps lookup if none of them is attached.
+type tapMap struct {
+	sync.RWMutex
+	active int32
+	taps   map[*tap]struct{}
+}
+
+// Tap mirrors the routed data points that match the filter until the
+// context is canceled, it implements status.Tapper for the admin API.
+// The tap is sampled and rate limited and it drops the data points if
+// its consumer is slow, so it doesn't block the demux.
+func (d *Demux) Tap(ctx context.Context, filter status.TapFilter) (<-chan status.TapEvent, error) {
+	t := &tap{filter: filter}
+
+	if filter.Key != "" {
+		key, err := regexp.Compile(filter.Key)
+		if err != nil {
+			return nil, err
+		}
+		t.key = key
+	}
+
+	if t.filter.Rate < 1 {
+		t.filter.Rate = defaultTapRate
+	} else if t.filter.Rate > maxTapRate {
+		t.filter.Rate = maxTapRate
+	}
+
+	if t.filter.Sample < 1 {
+		t.filter.Sample = 1
+	}
+
+	t.ch = make(chan status.TapEvent, tapBufferSize)
+
+	if !d.taps.add(t) {
+		return nil, status.ErrTapLimit
+	}
+
+	d.logger.Info("demux", zap.String("event", "tap attached"), zap.Int("rate", t.filter.Rate), zap.Int("sample", t.filter.Sample))
+
+	go func() {
+		<-ctx.Done()
+		d.taps.del(t)
+		close(t.ch)
+		d.logger.Info("demux", zap.String("event", "tap detached"))
+	}()
+
+	return t.ch, nil
+}
+
+func newTapMap() *tapMap {
+	return &tapMap{taps: make(map[*tap]struct{})}
+}
+
+func (m *tapMap) add(t *tap) bool {
+	m.Lock()
+	defer m.Unlock()
+
+	if len(m.taps) >= maxTaps {
+		return false
+	}
+
+	m.taps[t] = struct{}{}
+	atomic.StoreInt32(&m.active, int32(len(m.taps)))
+
+	return true
+}
+
+// del deletes the tap, nothing sends to its channel once del returns.
+func (m *tapMap) del(t *tap) {
+	m.Lock()
+	defer m.Unlock()
+
+	delete(m.taps, t)
+	atomic.StoreInt32(&m.active, int32(len(m.taps)))
+}
+
+// send mirrors the data point to the attached taps.
+func (m *tapMap) send(extDS telemetry.ExtDataStore, output string) {
+	if atomic.LoadInt32(&m.active) == 0 {
+		return
+	}
+
+	m.RLock()
+	defer m.RUnlock()
+
+	for t := range m.taps {
+		t.send(extDS, output)
+	}
+}
+
+func (t *tap) send(extDS telemetry.ExtDataStore, output string) {
+	if !t.match(extDS, output) {
+		return
+	}
+
+	t.matched++
+	if t.matched%uint64(t.filter.Sample) != 0 {
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(t.window) >= time.Second {
+		t.window = now
+		t.sent = 0
+	}
+
+	if t.sent >= t.filter.Rate {
+		return
+	}
+
+	select {
+	case t.ch <- status.TapEvent{Output: extDS.Output, Data: extDS.DS}:
+		t.sent++
+	default:
+	}
+}
+
+// match returns true if the data point matches the filter, the output
+// is either the producer or database name or the whole output e.g. kafka1::topic,
+// the prefix filter matches the beginning of the path prefix.
+func (t *tap) match(extDS telemetry.ExtDataStore, output string) bool {
+	f := t.filter
+
+	if f.Output != "" && f.Output != output && f.Output != extDS.Output {
+		return false
+	}
+
+	if f.SystemID != "" {
+		if systemID, _ := extDS.DS["system_id"].(string); systemID != f.SystemID {
+			return false
+		}
+	}
+
+	if f.Prefix != "" {
+		if prefix, _ := extDS.DS["prefix"].(string); !strings.HasPrefix(prefix, f.Prefix) {
+			return false
+		}
+	}
+
+	if t.key != nil {
+		key, _ := extDS.DS["key"].(string)
+		if !t.key.MatchString(key) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,165 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package demux
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/status"
+	"github.com/yahoo/panoptes-stream/telemetry"
+	"github.com/yahoo/panoptes-stream/telemetry/arista/gnmi"
+	"github.com/yahoo/panoptes-stream/telemetry/mock"
+)
+
+func testExtDS(systemID, prefix, key, output string) telemetry.ExtDataStore {
+	return telemetry.ExtDataStore{
+		Output: output,
+		DS: telemetry.DataStore{
+			"system_id": systemID,
+			"prefix":    prefix,
+			"key":       key,
+			"value":     1,
+		},
+	}
+}
+
+func TestTapFilter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := New(ctx, config.NewMockConfig(), nil, nil, nil)
+
+	filter := status.TapFilter{
+		SystemID: "core1.lax",
+		Prefix:   "/interfaces/",
+		Key:      "^out-",
+		Output:   "kafka1",
+	}
+
+	ch, err := d.Tap(ctx, filter)
+	assert.NoError(t, err)
+
+	for _, extDS := range []telemetry.ExtDataStore{
+		testExtDS("core1.lhr", "/interfaces/interface/", "out-octets", "kafka1::topic"),
+		testExtDS("core1.lax", "/network-instances/", "out-octets", "kafka1::topic"),
+		testExtDS("core1.lax", "/interfaces/interface/", "in-octets", "kafka1::topic"),
+		testExtDS("core1.lax", "/interfaces/interface/", "out-octets", "influxdb1::db"),
+		testExtDS("core1.lax", "/interfaces/interface/", "out-octets", "kafka1::topic"),
+	} {
+		d.taps.send(extDS, strings.Split(extDS.Output, "::")[0])
+	}
+
+	assert.Len(t, ch, 1)
+	e := <-ch
+	assert.Equal(t, "kafka1::topic", e.Output)
+	assert.Equal(t, "core1.lax", e.Data["system_id"])
+	assert.Equal(t, "out-octets", e.Data["key"])
+
+	_, err = d.Tap(ctx, status.TapFilter{Key: "("})
+	assert.Error(t, err)
+}
+
+func TestTapFilterCollector(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addr := "127.0.0.1:50594"
+	ln, err := mock.StartGNMIServer(addr, mock.Update{Notification: mock.AristaUpdate(), Attempt: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	cfg := config.NewMockConfig()
+	sensors := []*config.Sensor{{Service: "arista.gnmi", Output: "console::stdout", Path: "/interfaces/interface/state/counters"}}
+	ch := make(telemetry.ExtDSChan, 1)
+	go gnmi.New(cfg.Logger(), conn, sensors, ch, nil).Start(ctx)
+
+	extDS := <-ch
+
+	d := New(ctx, cfg, nil, nil, nil)
+
+	matched, err := d.Tap(ctx, status.TapFilter{SystemID: "127.0.0.1", Prefix: "/interfaces"})
+	assert.NoError(t, err)
+	unmatched, err := d.Tap(ctx, status.TapFilter{Prefix: "/network-instances"})
+	assert.NoError(t, err)
+
+	d.taps.send(extDS, "console")
+
+	assert.Len(t, matched, 1)
+	assert.Len(t, unmatched, 0)
+	assert.Equal(t, "out-octets", (<-matched).Data["key"])
+}
+
+func TestTapLimits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := New(ctx, config.NewMockConfig(), nil, nil, nil)
+
+	sampled, err := d.Tap(ctx, status.TapFilter{Sample: 2, Rate: 1000})
+	assert.NoError(t, err)
+	limited, err := d.Tap(ctx, status.TapFilter{Rate: 5})
+	assert.NoError(t, err)
+
+	for i := 0; i < 50; i++ {
+		d.taps.send(testExtDS("core1.lax", "/interfaces/", "out-octets", "console::stdout"), "console")
+	}
+
+	assert.Len(t, sampled, 25)
+	assert.Len(t, limited, 5)
+
+	// the slow consumer drops the data points
+	for i := 0; i < 2*tapBufferSize; i++ {
+		d.taps.send(testExtDS("core1.lax", "/interfaces/", "out-octets", "console::stdout"), "console")
+	}
+	assert.Len(t, sampled, maxTapRate)
+
+	for i := 2; i < maxTaps; i++ {
+		_, err = d.Tap(ctx, status.TapFilter{})
+		assert.NoError(t, err)
+	}
+
+	_, err = d.Tap(ctx, status.TapFilter{})
+	assert.Equal(t, status.ErrTapLimit, err)
+}
+
+func TestTapDetach(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inChan := make(telemetry.ExtDSChan, 1)
+	outChan := make(telemetry.ExtDSChan, 1)
+
+	d := New(ctx, config.NewMockConfig(), nil, nil, inChan)
+	d.chMap.add("console", outChan)
+	d.Start()
+
+	tapCtx, tapCancel := context.WithCancel(ctx)
+	ch, err := d.Tap(tapCtx, status.TapFilter{})
+	assert.NoError(t, err)
+
+	inChan <- testExtDS("core1.lax", "/interfaces/", "out-octets", "console::stdout")
+	e := <-ch
+	assert.Equal(t, "console::stdout", e.Output)
+	assert.Equal(t, "out-octets", (<-outChan).DS["key"])
+
+	tapCancel()
+	for range ch {
+	}
+
+	assert.Equal(t, int32(0), d.taps.active)
+}
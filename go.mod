@@ -15,7 +15,12 @@ require (
 	github.com/hashicorp/vault/sdk v0.1.14-0.20200702114606-96dd7d6e10db
 	github.com/influxdata/influxdb v1.8.3
 	github.com/influxdata/influxdb-client-go/v2 v2.1.0
+	github.com/jackc/pgconn v1.7.0
+	github.com/jackc/pgproto3/v2 v2.0.5
+	github.com/jackc/pgtype v1.5.0
+	github.com/jackc/pgx/v4 v4.9.0
 	github.com/kelseyhightower/envconfig v1.4.0
+	github.com/linkedin/goavro/v2 v2.10.0
 	github.com/nsqio/go-nsq v1.0.8
 	github.com/openconfig/gnmi v0.0.0-20200617225440-d2b4e6a45802
 	github.com/openconfig/ygot v0.8.1
@@ -23,6 +28,7 @@ require (
 	github.com/satori/go.uuid v1.2.1-0.20181028125025-b2ce2384e17b // indirect
 	github.com/segmentio/kafka-go v0.3.7
 	github.com/stretchr/testify v1.6.1
+	github.com/ugorji/go/codec v1.1.7
 	github.com/urfave/cli/v2 v2.2.0
 	go.etcd.io/etcd v0.5.0-alpha.5.0.20200520232829-54ba9589114f
 	go.uber.org/zap v1.16.0
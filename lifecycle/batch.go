@@ -0,0 +1,139 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package lifecycle
+
+import (
+	"context"
+	"time"
+
+	"github.com/yahoo/panoptes-stream/config"
+)
+
+// BatchFields are the batch size, flush interval and max retries
+// configuration fields that a database applies without reconnecting.
+var BatchFields = []string{"BatchSize", "FlushInterval", "MaxRetries"}
+
+// backoff is the delay between the write retries.
+var backoff = time.Second
+
+// Observer observes the sink writes, status.OutputMetrics implements it.
+type Observer interface {
+	ObserveWrite(start time.Time, points, bytes int, err error)
+}
+
+// WriteFunc writes a batch and returns the number of written bytes.
+type WriteFunc func(ctx context.Context) (int, error)
+
+// Writer writes the batches of a sink. It observes every write
+// and updates the health and the statistics of the sink.
+type Writer struct {
+	Lifecycle *Lifecycle
+	Metrics   Observer
+	// MaxRetries is the maximum retries of a failed write,
+	// negative retries until the context is canceled.
+	MaxRetries int
+	// Permanent reports whether the write error isn't retryable.
+	Permanent func(error) bool
+	// OnError is called once a write failed, e.g. to log the error.
+	OnError func(error)
+}
+
+// Database is the Lifecycle of a database that applies configuration
+// changes in place, it implements database.Reloader. The database
+// serves ReloadRequest with the requested config.Database.
+type Database struct {
+	Lifecycle
+}
+
+// Reload requests the database to apply the configuration.
+func (d *Database) Reload(cfg config.Database) error {
+	return d.RequestReload(cfg)
+}
+
+// Database returns the requested database configuration.
+func (r *ReloadRequest) Database() config.Database {
+	return r.Config.(config.Database)
+}
+
+// Reloadable returns ErrRestartRequired unless the new configuration
+// differs from the current configuration only in the given fields.
+func Reloadable(current, new interface{}, fields ...string) error {
+	if !OnlyChanged(current, new, fields...) {
+		return ErrRestartRequired
+	}
+
+	return nil
+}
+
+// Write writes the batch of n data points and retries the failed write
+// with backoff up to the max retries unless the error is permanent.
+// Once the context is canceled, Write returns the context error and
+// the batch isn't counted as failed, the sink writes it by Final.
+func (w *Writer) Write(ctx context.Context, n int, write WriteFunc) error {
+	var err error
+
+	if n < 1 {
+		return nil
+	}
+
+	for retry := 0; (w.MaxRetries < 0 || retry <= w.MaxRetries) && ctx.Err() == nil; retry++ {
+		if err = w.write(ctx, n, write); err == nil {
+			w.Lifecycle.AddSent(n)
+			return nil
+		}
+
+		if retry == w.MaxRetries || ctx.Err() != nil || w.Permanent != nil && w.Permanent(err) {
+			break
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	w.Lifecycle.AddFailed(n)
+
+	return err
+}
+
+// Final writes the batch of n data points once without the sink
+// context, it's the last write of the sink once it's stopped.
+func (w *Writer) Final(n int, write WriteFunc) error {
+	if n < 1 {
+		return nil
+	}
+
+	err := w.write(context.Background(), n, write)
+	if err != nil {
+		w.Lifecycle.AddFailed(n)
+		return err
+	}
+
+	w.Lifecycle.AddSent(n)
+
+	return nil
+}
+
+func (w *Writer) write(ctx context.Context, n int, write WriteFunc) error {
+	start := time.Now()
+	b, err := write(ctx)
+	if w.Metrics != nil {
+		w.Metrics.ObserveWrite(start, n, b, err)
+	}
+
+	w.Lifecycle.SetHealth(err)
+
+	if err != nil && w.OnError != nil {
+		w.OnError(err)
+	}
+
+	return err
+}
@@ -0,0 +1,103 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type observer struct {
+	writes int
+}
+
+func (o *observer) ObserveWrite(start time.Time, points, bytes int, err error) {
+	o.writes++
+}
+
+func TestWriterRetry(t *testing.T) {
+	backoff = time.Millisecond
+	defer func() { backoff = time.Second }()
+
+	l := new(Lifecycle)
+	o := new(observer)
+	errWrite := errors.New("write failed")
+	errBad := errors.New("bad request")
+
+	var errs int
+	w := &Writer{
+		Lifecycle:  l,
+		Metrics:    o,
+		MaxRetries: 2,
+		Permanent:  func(err error) bool { return err == errBad },
+		OnError:    func(error) { errs++ },
+	}
+
+	// succeeds on the second retry
+	calls := 0
+	assert.NoError(t, w.Write(context.Background(), 5, func(context.Context) (int, error) {
+		if calls++; calls < 3 {
+			return 0, errWrite
+		}
+		return 10, nil
+	}))
+	assert.Equal(t, 3, o.writes)
+	assert.Equal(t, 2, errs)
+	assert.Equal(t, Stats{Sent: 5}, l.Stats())
+
+	// fails after the max retries
+	assert.Equal(t, errWrite, w.Write(context.Background(), 5, func(context.Context) (int, error) {
+		return 0, errWrite
+	}))
+	assert.Equal(t, 6, o.writes)
+	assert.Equal(t, Stats{Sent: 5, Failed: 5}, l.Stats())
+	assert.Equal(t, errWrite, l.Health())
+
+	// permanent error doesn't retry
+	assert.Equal(t, errBad, w.Write(context.Background(), 5, func(context.Context) (int, error) {
+		return 0, errBad
+	}))
+	assert.Equal(t, 7, o.writes)
+	assert.Equal(t, Stats{Sent: 5, Failed: 10}, l.Stats())
+
+	// empty batch
+	assert.NoError(t, w.Write(context.Background(), 0, nil))
+	assert.Equal(t, 7, o.writes)
+}
+
+func TestWriterCancel(t *testing.T) {
+	l := new(Lifecycle)
+	w := &Writer{Lifecycle: l, MaxRetries: -1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errWrite := errors.New("write failed")
+
+	// the backoff returns once the context is canceled
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	assert.Equal(t, context.Canceled, w.Write(ctx, 5, func(context.Context) (int, error) {
+		return 0, errWrite
+	}))
+	assert.Less(t, int64(time.Since(start)), int64(backoff))
+
+	// the canceled batch isn't failed, it's written by Final
+	assert.Equal(t, Stats{}, l.Stats())
+	assert.NoError(t, w.Final(5, func(ctx context.Context) (int, error) {
+		return 0, ctx.Err()
+	}))
+	assert.Equal(t, Stats{Sent: 5}, l.Stats())
+
+	assert.Equal(t, errWrite, w.Final(5, func(context.Context) (int, error) {
+		return 0, errWrite
+	}))
+	assert.Equal(t, Stats{Sent: 5, Failed: 5}, l.Stats())
+}
@@ -0,0 +1,241 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+// Package lifecycle provides the common lifecycle of producers and databases.
+// A sink embeds Lifecycle and runs its ingestion loop through Run; Lifecycle
+// then implements Stop, Flush, Health and Stats on behalf of the sink.
+// A sink that applies configuration changes in place serves ReloadRequest.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrNotRunning is returned when the sink isn't running.
+	ErrNotRunning = errors.New("not running")
+	// ErrRestartRequired is returned when the configuration
+	// change can't be applied without a new sink instance.
+	ErrRestartRequired = errors.New("restart required")
+)
+
+// Stats represents the sink statistics.
+type Stats struct {
+	// Sent is the number of data points written successfully.
+	Sent uint64 `json:"sent"`
+	// Failed is the number of data points that couldn't be written.
+	Failed uint64 `json:"failed"`
+	// Dropped is the number of data points that couldn't be encoded.
+	Dropped uint64 `json:"dropped"`
+}
+
+// Lifecycle implements Stop, Flush, Health and Stats of a sink.
+// The zero value is ready to use.
+type Lifecycle struct {
+	sent    uint64
+	failed  uint64
+	dropped uint64
+
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+	done     chan struct{}
+	flushCh  chan chan error
+	reloadCh chan *ReloadRequest
+	stopped  bool
+	err      error
+}
+
+// ReloadRequest represents a configuration reload request.
+type ReloadRequest struct {
+	Config interface{}
+	reply  chan error
+}
+
+// Reply replies the reload result to the requester.
+func (r *ReloadRequest) Reply(err error) {
+	r.reply <- err
+}
+
+// Run runs the sink loop until it returns or the context is canceled.
+// The loop receives a context that's canceled by Stop as well and it
+// should serve the FlushRequest channel. The loop error sets the health.
+func (l *Lifecycle) Run(ctx context.Context, loop func(context.Context) error) error {
+	l.mu.Lock()
+	ctx, l.cancel = context.WithCancel(ctx)
+	if l.stopped {
+		l.cancel()
+	}
+	if l.flushCh == nil {
+		l.flushCh = make(chan chan error)
+		l.reloadCh = make(chan *ReloadRequest)
+	}
+	l.done = make(chan struct{})
+	l.err = nil
+	done, cancel := l.done, l.cancel
+	l.mu.Unlock()
+
+	err := loop(ctx)
+	cancel()
+
+	l.mu.Lock()
+	if err != nil {
+		l.err = err
+	}
+	l.done = nil
+	l.mu.Unlock()
+
+	close(done)
+
+	return err
+}
+
+// Stop stops the sink and waits until the sink loop writes
+// the buffered data and returns. A stopped sink doesn't run again.
+func (l *Lifecycle) Stop() {
+	l.mu.Lock()
+	l.stopped = true
+	cancel, done := l.cancel, l.done
+	l.mu.Unlock()
+
+	if done == nil {
+		return
+	}
+
+	cancel()
+	<-done
+}
+
+// Flush requests the sink loop to write the buffered data
+// and returns the write error.
+func (l *Lifecycle) Flush() error {
+	l.mu.Lock()
+	done, flushCh := l.done, l.flushCh
+	l.mu.Unlock()
+
+	if done == nil {
+		return ErrNotRunning
+	}
+
+	req := make(chan error, 1)
+
+	select {
+	case flushCh <- req:
+	case <-done:
+		return ErrNotRunning
+	}
+
+	select {
+	case err := <-req:
+		return err
+	case <-done:
+		return ErrNotRunning
+	}
+}
+
+// FlushRequest returns the flush request channel, the sink
+// loop writes the buffered data and replies the write error
+// to the request. It's available after Run is called.
+func (l *Lifecycle) FlushRequest() <-chan chan error {
+	return l.flushCh
+}
+
+// RequestReload sends the configuration to the sink loop
+// and returns the reload result.
+func (l *Lifecycle) RequestReload(cfg interface{}) error {
+	l.mu.Lock()
+	done, reloadCh := l.done, l.reloadCh
+	l.mu.Unlock()
+
+	if done == nil {
+		return ErrNotRunning
+	}
+
+	req := &ReloadRequest{Config: cfg, reply: make(chan error, 1)}
+
+	select {
+	case reloadCh <- req:
+	case <-done:
+		return ErrNotRunning
+	}
+
+	select {
+	case err := <-req.reply:
+		return err
+	case <-done:
+		return ErrNotRunning
+	}
+}
+
+// ReloadRequest returns the reload request channel, the sink loop
+// applies the configuration and replies the result to the request.
+// It's available after Run is called.
+func (l *Lifecycle) ReloadRequest() <-chan *ReloadRequest {
+	return l.reloadCh
+}
+
+// OnlyChanged reports whether the new configuration differs from the
+// current configuration only in the given fields. Both of them must
+// be pointers to the same struct type.
+func OnlyChanged(current, new interface{}, fields ...string) bool {
+	c := reflect.ValueOf(current).Elem()
+	n := reflect.New(c.Type()).Elem()
+	n.Set(reflect.ValueOf(new).Elem())
+
+	for _, f := range fields {
+		n.FieldByName(f).Set(c.FieldByName(f))
+	}
+
+	return reflect.DeepEqual(c.Interface(), n.Interface())
+}
+
+// Health returns nil if the sink is running and the last write
+// succeeded, otherwise it returns the last error.
+func (l *Lifecycle) Health() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.err != nil {
+		return l.err
+	}
+
+	if l.done == nil {
+		return ErrNotRunning
+	}
+
+	return nil
+}
+
+// SetHealth sets the result of the last write.
+func (l *Lifecycle) SetHealth(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.err = err
+}
+
+// Stats returns the sink statistics.
+func (l *Lifecycle) Stats() Stats {
+	return Stats{
+		Sent:    atomic.LoadUint64(&l.sent),
+		Failed:  atomic.LoadUint64(&l.failed),
+		Dropped: atomic.LoadUint64(&l.dropped),
+	}
+}
+
+// AddSent adds n data points to the sent counter.
+func (l *Lifecycle) AddSent(n int) {
+	atomic.AddUint64(&l.sent, uint64(n))
+}
+
+// AddFailed adds n data points to the failed counter.
+func (l *Lifecycle) AddFailed(n int) {
+	atomic.AddUint64(&l.failed, uint64(n))
+}
+
+// AddDropped adds n data points to the dropped counter.
+func (l *Lifecycle) AddDropped(n int) {
+	atomic.AddUint64(&l.dropped, uint64(n))
+}
@@ -0,0 +1,159 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sink buffers the data and writes it on flush request or termination.
+type sink struct {
+	Lifecycle
+
+	ch      chan int
+	written []int
+	err     error
+}
+
+func (s *sink) Start(ctx context.Context) error {
+	return s.Run(ctx, func(ctx context.Context) error {
+		var batch []int
+
+		for {
+			select {
+			case v := <-s.ch:
+				batch = append(batch, v)
+			case req := <-s.FlushRequest():
+				req <- s.write(batch)
+				batch = batch[:0]
+			case <-ctx.Done():
+				s.write(batch)
+				return nil
+			}
+		}
+	})
+}
+
+func (s *sink) write(batch []int) error {
+	if s.err != nil {
+		s.AddFailed(len(batch))
+		s.SetHealth(s.err)
+		return s.err
+	}
+
+	s.written = append(s.written, batch...)
+	s.AddSent(len(batch))
+	s.SetHealth(nil)
+
+	return nil
+}
+
+func TestLifecycle(t *testing.T) {
+	s := &sink{ch: make(chan int)}
+	assert.Equal(t, ErrNotRunning, s.Health())
+	assert.Equal(t, ErrNotRunning, s.Flush())
+
+	done := make(chan error)
+	go func() {
+		done <- s.Start(context.Background())
+	}()
+
+	s.ch <- 1
+	s.ch <- 2
+	assert.NoError(t, s.Flush())
+	assert.NoError(t, s.Health())
+	assert.Equal(t, Stats{Sent: 2}, s.Stats())
+
+	s.err = errors.New("connection refused")
+	s.ch <- 3
+	assert.Equal(t, s.err, s.Flush())
+	assert.Equal(t, s.err, s.Health())
+
+	s.err = nil
+	s.ch <- 4
+	s.Stop()
+
+	assert.NoError(t, <-done)
+	assert.Equal(t, []int{1, 2, 4}, s.written)
+	assert.Equal(t, Stats{Sent: 3, Failed: 1}, s.Stats())
+	assert.Equal(t, ErrNotRunning, s.Health())
+
+	// a stopped sink doesn't run again
+	go func() {
+		done <- s.Start(context.Background())
+	}()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("time limit exceeded")
+	}
+}
+
+func TestRunError(t *testing.T) {
+	l := new(Lifecycle)
+	err := errors.New("invalid config")
+
+	assert.Equal(t, err, l.Run(context.Background(), func(context.Context) error {
+		return err
+	}))
+
+	assert.Equal(t, err, l.Health())
+}
+
+func TestReload(t *testing.T) {
+	l := new(Lifecycle)
+	assert.Equal(t, ErrNotRunning, l.RequestReload(1))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Run(context.Background(), func(ctx context.Context) error {
+			for {
+				select {
+				case req := <-l.ReloadRequest():
+					if req.Config.(int) < 0 {
+						req.Reply(ErrRestartRequired)
+						continue
+					}
+					req.Reply(nil)
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		})
+	}()
+
+	for i := 0; i < 10 && l.RequestReload(1) == ErrNotRunning; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.NoError(t, l.RequestReload(1))
+	assert.Equal(t, ErrRestartRequired, l.RequestReload(-1))
+
+	l.Stop()
+	<-done
+	assert.Equal(t, ErrNotRunning, l.RequestReload(1))
+}
+
+func TestOnlyChanged(t *testing.T) {
+	type config struct {
+		Server    string
+		BatchSize int
+		Topics    []string
+	}
+
+	current := &config{Server: "127.0.0.1", BatchSize: 10, Topics: []string{"a"}}
+
+	assert.True(t, OnlyChanged(current, &config{Server: "127.0.0.1", BatchSize: 100, Topics: []string{"a"}}, "BatchSize"))
+	assert.False(t, OnlyChanged(current, &config{Server: "127.0.0.2", BatchSize: 100, Topics: []string{"a"}}, "BatchSize"))
+	assert.False(t, OnlyChanged(current, &config{Server: "127.0.0.1", BatchSize: 10, Topics: []string{"b"}}, "BatchSize"))
+	assert.Equal(t, 10, current.BatchSize)
+}
@@ -5,6 +5,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -13,6 +14,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/yahoo/panoptes-stream/cache"
 	"github.com/yahoo/panoptes-stream/config"
 	"github.com/yahoo/panoptes-stream/database"
 	"github.com/yahoo/panoptes-stream/demux"
@@ -32,17 +34,21 @@ var (
 	producerRegistrar  *producer.Registrar
 	databaseRegistrar  *database.Registrar
 	telemetryRegistrar *telemetry.Registrar
+	dialoutRegistrar   *dialout.Registrar
 )
 
 func main() {
 	var (
 		discovery     discovery.Discovery
 		signalCh      = make(chan os.Signal, 1)
+		reloadCh      = make(chan os.Signal, 1)
 		updateRequest = make(chan struct{}, 1)
+		configState   = status.NewCheckState(nil)
 		ctx           = context.Background()
 	)
 
 	signal.Notify(signalCh, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(reloadCh, syscall.SIGHUP)
 
 	cfg, err := getConfig(os.Args)
 	if err != nil {
@@ -77,8 +83,28 @@ func main() {
 	telemetryRegistrar = telemetry.NewRegistrar(logger)
 	register.Telemetry(telemetryRegistrar)
 
+	// dial-out
+	dialoutRegistrar = dialout.NewRegistrar(logger)
+	register.Dialout(dialoutRegistrar)
+
+	if err := dialoutRegistrar.Validate(cfg.Global().Dialout.Services); err != nil {
+		logger.Fatal("dialout", zap.Error(err))
+	}
+
 	// start demux
 	d := demux.New(ctx, cfg, producerRegistrar, databaseRegistrar, outChan)
+
+	// latest value cache and its gNMI server
+	if cfg.Global().Cache.Enabled {
+		c := cache.New(cfg)
+		c.Start(ctx)
+		d.SetCache(c)
+
+		if err := cache.NewServer(cfg, c).Start(ctx); err != nil {
+			logger.Error("cache", zap.Error(err))
+		}
+	}
+
 	d.Start()
 
 	// start telemetry
@@ -88,27 +114,54 @@ func main() {
 	}
 
 	// start telemetry dialout
-	i := dialout.New(ctx, cfg, outChan)
+	i := dialout.New(ctx, cfg, dialoutRegistrar, outChan, t)
 	i.Start()
 
+	var shards *Shards
+	if cfg.Global().Shards.Enabled && discovery != nil {
+		shards = NewShards(cfg, t, discovery, updateRequest)
+		shards.SetReadiness(shardsReadiness(map[string]status.Checker{
+			"config":    configState.Check,
+			"demux":     d.Ready,
+			"discovery": discoveryCheck(discovery),
+		}))
+	}
+
 	// status
 	if !cfg.Global().Status.Disabled {
 		s := status.New(cfg)
+		s.SetSinkReporter(d)
+		s.SetDeviceReporter(t)
+		s.SetTapper(d)
+		s.AddCheck("config", configState.Check)
+		s.AddCheck("demux", d.Ready)
+		s.AddCheck("dialout", i.Check)
+		if discovery != nil {
+			s.AddCheck("discovery", discoveryCheck(discovery))
+		}
+		if shards != nil {
+			s.SetShardReporter(shards)
+			s.AddCheck("shards", shards.Ready)
+		}
 		s.Start()
 	}
 
-	go updateLoop(cfg, t, d, i, updateRequest)
+	go updateLoop(cfg, t, d, i, configState, updateRequest, reloadCh)
 
-	if cfg.Global().Shards.Enabled && discovery != nil {
-		shards := NewShards(cfg, t, discovery, updateRequest)
+	if shards != nil {
 		go shards.Start()
 	}
 
 	<-signalCh
+
+	i.Stop()
+
+	// write the buffered data before exit
+	d.Stop()
 }
 
-func updateLoop(cfg config.Config, t *telemetry.Telemetry, d *demux.Demux, i *dialout.Dialout, updateRequest chan struct{}) {
-	var informed bool
+func updateLoop(cfg config.Config, t *telemetry.Telemetry, d *demux.Demux, i *dialout.Dialout, configState *status.CheckState, updateRequest chan struct{}, reloadCh chan os.Signal) {
+	var informed, reload bool
 
 	for {
 		select {
@@ -118,6 +171,10 @@ func updateLoop(cfg config.Config, t *telemetry.Telemetry, d *demux.Demux, i *di
 
 		case <-updateRequest:
 
+		case <-reloadCh:
+			// SIGHUP reloads the configuration and the log levels
+			reload = true
+
 		case <-time.After(time.Second * 10):
 			if !informed {
 				continue
@@ -125,17 +182,52 @@ func updateLoop(cfg config.Config, t *telemetry.Telemetry, d *demux.Demux, i *di
 			informed = false
 		}
 
-		if err := cfg.Update(); err != nil {
+		// the unknown dial-out services reject the configuration
+		err := cfg.Update()
+		if err == nil {
+			err = i.Validate()
+		}
+
+		configState.Set(err)
+		if err != nil {
 			cfg.Logger().Error("update", zap.Error(err))
 			continue
 		}
 
+		if reload {
+			reload = false
+			if err := config.SetLogLevels(cfg.Global().Logger); err != nil {
+				cfg.Logger().Error("update", zap.String("event", "logger"), zap.Error(err))
+			}
+		}
+
 		d.Update()
 		t.Update()
 		i.Update()
 	}
 }
 
+// discoveryCheck returns a readiness check that fails if
+// this node isn't registered at the discovery service.
+func discoveryCheck(d discovery.Discovery) status.Checker {
+	return func() error {
+		hostname, _ := os.Hostname()
+
+		instances, err := d.GetInstances()
+		if err != nil {
+			return err
+		}
+
+		for _, instance := range instances {
+			if instance.Address == hostname {
+				return nil
+			}
+		}
+
+		return errors.New("not registered")
+	}
+}
+
 func discoveryRegister(cfg config.Config) (discovery.Discovery, error) {
 	var (
 		discovery discovery.Discovery
@@ -4,21 +4,28 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"hash/fnv"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/yahoo/panoptes-stream/config"
 	"github.com/yahoo/panoptes-stream/discovery"
+	"github.com/yahoo/panoptes-stream/status"
 	"github.com/yahoo/panoptes-stream/telemetry"
 )
 
 // Shards represents sharding service.
 // Panoptes shards devices for horizontal scaling and high availability.
 type Shards struct {
+	mu                 sync.RWMutex
 	cfg                config.Config
 	id                 string
 	logger             *zap.Logger
@@ -28,6 +35,8 @@ type Shards struct {
 	initializingShards int
 	minimumShards      int
 	isSuspension       bool
+	initialized        bool
+	readiness          func() error
 	updateRequest      chan struct{}
 }
 
@@ -38,7 +47,7 @@ func NewShards(cfg config.Config, telemetry *telemetry.Telemetry, discovery disc
 		discovery:          discovery,
 		telemetry:          telemetry,
 		updateRequest:      updateRequest,
-		logger:             cfg.Logger(),
+		logger:             config.NamedLogger(cfg.Logger(), "shards"),
 		numberOfNodes:      cfg.Global().Shards.NumberOfNodes,
 		initializingShards: cfg.Global().Shards.InitializingShards,
 		minimumShards:      cfg.Global().Shards.MinimumShards,
@@ -63,8 +72,7 @@ func (s *Shards) Start() {
 	// disconnected from targets that this node is responsible.
 	time.Sleep(35 * time.Second)
 
-	s.telemetry.AddFilterOpt("mainShard", mainShard(s.id, s.numberOfNodes))
-	s.updateRequest <- struct{}{}
+	s.claim()
 
 	// takeover if all nodes are not available
 	go func() {
@@ -76,8 +84,8 @@ func (s *Shards) Start() {
 		}
 
 		if !isAllNodesRunning(s.numberOfNodes, instances) &&
-			availableShards(instances) >= s.minimumShards {
-			s.telemetry.AddFilterOpt("extraShard", extraShards(s.id, s.numberOfNodes, instances))
+			availableShards(instances) >= s.minimumShards && s.ready() {
+			s.telemetry.AddFilterOpt("extraShard", extraShards(s.getID(), s.numberOfNodes, instances))
 			s.updateRequest <- struct{}{}
 		}
 	}()
@@ -101,9 +109,15 @@ func (s *Shards) Start() {
 
 			if availableShards(instances) >= s.minimumShards {
 				s.logger.Info("shards", zap.String("event", "shards has been changed"))
-				s.telemetry.AddFilterOpt("extraShard", extraShards(s.id, s.numberOfNodes, instances))
 
-				if s.isSuspension {
+				// a node that's not ready doesn't take over the failed nodes shards
+				if s.ready() {
+					s.telemetry.AddFilterOpt("extraShard", extraShards(s.getID(), s.numberOfNodes, instances))
+				} else {
+					s.telemetry.DelFilterOpt("extraShard")
+				}
+
+				if s.isSuspended() {
 					s.unsuspend()
 				}
 			} else {
@@ -208,7 +222,9 @@ func (s *Shards) waitForDiscoveryRegister() {
 
 		for _, instance := range instances {
 			if instance.Address == hostname && instance.Status == "passing" {
+				s.mu.Lock()
 				s.id = instance.ID
+				s.mu.Unlock()
 				return
 			}
 		}
@@ -250,21 +266,135 @@ func (s *Shards) waitForInitialShards() {
 	}
 }
 
+// SetReadiness sets the node readiness check, the node claims its
+// shard and takes over the failed nodes shards once it's ready.
+func (s *Shards) SetReadiness(readiness func() error) {
+	s.readiness = readiness
+}
+
+// shardsReadiness returns the readiness of the node to claim its shard, it
+// runs the given checks. The sinks health isn't one of them since an unhealthy
+// sink isn't specific to the node and it'd hold every node's shard.
+func shardsReadiness(checks map[string]status.Checker) func() error {
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return func() error {
+		var failing []string
+		for _, name := range names {
+			if err := checks[name](); err != nil {
+				failing = append(failing, name)
+			}
+		}
+
+		if len(failing) > 0 {
+			return fmt.Errorf("not ready: %s", strings.Join(failing, ", "))
+		}
+
+		return nil
+	}
+}
+
+func (s *Shards) ready() bool {
+	if s.readiness == nil {
+		return true
+	}
+
+	if err := s.readiness(); err != nil {
+		s.logger.Warn("shards", zap.String("event", "not ready"), zap.Error(err))
+		return false
+	}
+
+	return true
+}
+
+// claim claims the node shard once the node is ready.
+func (s *Shards) claim() {
+	s.waitForReadiness()
+
+	s.telemetry.AddFilterOpt("mainShard", mainShard(s.getID(), s.numberOfNodes))
+
+	s.mu.Lock()
+	s.initialized = true
+	s.mu.Unlock()
+
+	s.updateRequest <- struct{}{}
+}
+
+// waitForReadiness waits until the node is ready.
+func (s *Shards) waitForReadiness() {
+	for !s.ready() {
+		time.Sleep(time.Second * 5)
+	}
+}
+
+// Ready returns nil if the node has claimed its shard and
+// it's not suspended, it's a readiness check.
+func (s *Shards) Ready() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.initialized {
+		return errors.New("shards initializing")
+	}
+
+	if s.isSuspension {
+		return errors.New("shards suspended")
+	}
+
+	return nil
+}
+
 func (s *Shards) suspend() {
 	s.telemetry.DelFilterOpt("extraShard")
 	s.telemetry.DelFilterOpt("mainShard")
+
+	s.mu.Lock()
 	s.isSuspension = true
+	s.mu.Unlock()
 
 	s.logger.Warn("shards", zap.String("event", "node has been suspended"))
 }
 
 func (s *Shards) unsuspend() {
-	s.telemetry.AddFilterOpt("mainShard", mainShard(s.id, s.numberOfNodes))
+	s.telemetry.AddFilterOpt("mainShard", mainShard(s.getID(), s.numberOfNodes))
+
+	s.mu.Lock()
 	s.isSuspension = false
+	s.mu.Unlock()
 
 	s.logger.Warn("shards", zap.String("event", "node has been unsuspended"))
 }
 
+func (s *Shards) getID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.id
+}
+
+func (s *Shards) isSuspended() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.isSuspension
+}
+
+// ShardState returns the node shard assignment, it
+// implements status.ShardReporter for the admin API.
+func (s *Shards) ShardState() status.ShardState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return status.ShardState{
+		Enabled:       true,
+		NodeID:        s.id,
+		NumberOfNodes: s.numberOfNodes,
+		Suspended:     s.isSuspension,
+	}
+}
+
 func availableShards(instances []discovery.Instance) int {
 	var available int
 
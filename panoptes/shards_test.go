@@ -5,12 +5,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
 
 	"github.com/yahoo/panoptes-stream/config"
 	"github.com/yahoo/panoptes-stream/discovery"
+	"github.com/yahoo/panoptes-stream/status"
 	"github.com/yahoo/panoptes-stream/telemetry"
 )
 
@@ -194,3 +197,81 @@ func TestSuspendUnSuspend(t *testing.T) {
 	d = s.telemetry.GetDevices()
 	assert.Len(t, d, 0)
 }
+
+func TestShardsReady(t *testing.T) {
+	cfg := config.NewMockConfig()
+	tm := telemetry.New(context.Background(), cfg, nil, nil)
+
+	s := Shards{
+		cfg:           cfg,
+		id:            "0",
+		logger:        cfg.Logger(),
+		telemetry:     tm,
+		numberOfNodes: 2,
+	}
+
+	assert.EqualError(t, s.Ready(), "shards initializing")
+	assert.True(t, s.ready())
+
+	s.initialized = true
+	assert.NoError(t, s.Ready())
+
+	s.suspend()
+	assert.EqualError(t, s.Ready(), "shards suspended")
+
+	s.SetReadiness(func() error { return errors.New("not ready: sinks") })
+	assert.False(t, s.ready())
+}
+
+type sinkReporter []status.SinkHealth
+
+func (r sinkReporter) SinkHealth() []status.SinkHealth {
+	return r
+}
+
+func TestShardsClaimUnhealthySink(t *testing.T) {
+	devices := []config.Device{
+		{DeviceConfig: config.DeviceConfig{Host: "foo02.bar"}},
+	}
+	cfg := &config.MockConfig{MDevices: devices, MGlobal: &config.Global{}}
+	tm := telemetry.New(context.Background(), cfg, nil, nil)
+
+	st := status.New(cfg)
+	st.SetSinkReporter(sinkReporter{{Name: "influx1", Error: "connection refused"}})
+	assert.EqualError(t, st.Ready(), "not ready: sinks")
+
+	configState := status.NewCheckState(nil)
+	s := Shards{
+		cfg:           cfg,
+		id:            "0",
+		logger:        zap.NewNop(),
+		telemetry:     tm,
+		numberOfNodes: 2,
+		updateRequest: make(chan struct{}, 1),
+	}
+	s.SetReadiness(shardsReadiness(map[string]status.Checker{
+		"config":    configState.Check,
+		"demux":     func() error { return nil },
+		"discovery": func() error { return nil },
+	}))
+
+	s.claim()
+
+	assert.NoError(t, s.Ready())
+	assert.Len(t, s.telemetry.GetDevices(), 1)
+	assert.Len(t, s.updateRequest, 1)
+}
+
+func TestShardsReadiness(t *testing.T) {
+	configState := status.NewCheckState(errors.New("invalid config"))
+	readiness := shardsReadiness(map[string]status.Checker{
+		"discovery": func() error { return errors.New("not registered") },
+		"config":    configState.Check,
+		"demux":     func() error { return nil },
+	})
+
+	assert.EqualError(t, readiness(), "not ready: config, discovery")
+
+	configState.Set(nil)
+	assert.EqualError(t, readiness(), "not ready: discovery")
+}
@@ -12,38 +12,140 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/lifecycle"
 	"github.com/yahoo/panoptes-stream/producer"
+	"github.com/yahoo/panoptes-stream/serializer"
+	"github.com/yahoo/panoptes-stream/status"
 	"github.com/yahoo/panoptes-stream/telemetry"
 )
 
 // Console represents console
 // It's just print pretty metrics on the stdout or stderr for testing purpose
 type Console struct {
-	ch     telemetry.ExtDSChan
-	logger *zap.Logger
+	lifecycle.Lifecycle
+
+	cfg     config.Producer
+	ch      telemetry.ExtDSChan
+	logger  *zap.Logger
+	metrics *status.OutputMetrics
+}
+
+type consoleConfig struct {
+	Encoding string
 }
 
 // New returns a new console instance
-func New(ctx context.Context, cfg config.Producer, lg *zap.Logger, inChan telemetry.ExtDSChan) producer.Producer {
-	return &Console{ch: inChan, logger: lg}
+func New(cfg config.Producer, lg *zap.Logger, inChan telemetry.ExtDSChan) producer.Producer {
+	return &Console{cfg: cfg, ch: inChan, logger: lg, metrics: status.NewOutputMetrics(cfg.Name)}
 }
 
 // Start starts printing available metric
-func (c *Console) Start() {
+func (c *Console) Start(ctx context.Context) error {
+	return c.Run(ctx, c.start)
+}
+
+func (c *Console) start(ctx context.Context) error {
+	conf, err := c.getConfig()
+	if err != nil {
+		return err
+	}
+
+	s, err := getSerializer(conf)
+	if err != nil {
+		return err
+	}
+
 	for {
-		v, ok := <-c.ch
-		if !ok {
-			break
-		}
+		select {
+		case v, ok := <-c.ch:
+			if !ok {
+				return nil
+			}
+
+			c.metrics.DataIn.Inc()
+
+			out := strings.Split(v.Output, "::")
+			if len(out) < 2 {
+				c.logger.Error("wrong output", zap.String("output", v.Output))
+				c.AddDropped(1)
+				c.metrics.Error(status.ErrorInvalid)
+				continue
+			}
+
+			if s == nil {
+				PrettyPrint(v.DS, out[1])
+				c.AddSent(1)
+				c.metrics.DataOut.Inc()
+				continue
+			}
+
+			b, err := s.Marshal(out[1], v.DS)
+			if err != nil {
+				c.logger.Error("console", zap.Error(err))
+				c.AddDropped(1)
+				c.metrics.Error(status.ErrorInvalid)
+				continue
+			}
+
+			Print(append(b, '\n'), out[1])
+			c.AddSent(1)
+			c.metrics.DataOut.Inc()
+			c.metrics.Bytes.Add(float64(len(b) + 1))
+
+		case req := <-c.FlushRequest():
+			// nothing is buffered
+			req <- nil
 
-		out := strings.Split(v.Output, "::")
-		if len(out) < 2 {
-			c.logger.Error("wrong output", zap.String("output", v.Output))
-			continue
+		case req := <-c.ReloadRequest():
+			// there is no connection, all the changes apply in place
+			n := &Console{cfg: req.Config.(config.Producer)}
+			conf, err := n.getConfig()
+			if err != nil {
+				req.Reply(err)
+				continue
+			}
+
+			ns, err := getSerializer(conf)
+			if err == nil {
+				s, c.cfg = ns, n.cfg
+			}
+			req.Reply(err)
+
+		case <-ctx.Done():
+			return nil
 		}
+	}
+}
+
+// Reload applies the configuration changes.
+func (c *Console) Reload(cfg config.Producer) error {
+	return c.RequestReload(cfg)
+}
+
+func (c *Console) getConfig() (*consoleConfig, error) {
+	conf := new(consoleConfig)
+	if c.cfg.Config == nil {
+		return conf, nil
+	}
+
+	b, err := json.Marshal(c.cfg.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(b, conf)
 
-		PrettyPrint(v.DS, out[1])
+	return conf, err
+}
+
+// getSerializer returns the configured serializer or
+// nil for the pretty print.
+func getSerializer(conf *consoleConfig) (serializer.Serializer, error) {
+	if conf.Encoding == "" {
+		return nil, nil
 	}
+
+	return serializer.New(conf.Encoding, serializer.Config{})
 }
 
 // PrettyPrint prints metrics on the stdout or stderr in pretty format
@@ -53,12 +155,18 @@ func PrettyPrint(ds telemetry.DataStore, fdType string) error {
 		return err
 	}
 
+	Print(b, fdType)
+
+	return nil
+}
+
+// Print prints encoded metrics on the stdout or stderr
+func Print(b []byte, fdType string) {
 	if fdType == "stdout" {
 		os.Stdout.Write(b)
 	} else {
 		os.Stderr.Write(b)
 	}
-	return nil
 }
 
 // Register registers console as a producer at producer registrar
@@ -26,8 +26,8 @@ func TestConsoleStdout(t *testing.T) {
 	os.Stdout = w
 
 	ch := make(telemetry.ExtDSChan, 2)
-	p := New(context.Background(), config.Producer{}, cfg.Logger(), ch)
-	go p.Start()
+	p := New(config.Producer{}, cfg.Logger(), ch)
+	go p.Start(context.Background())
 
 	ch <- telemetry.ExtDataStore{
 		Output: "console::stdout",
@@ -55,8 +55,8 @@ func TestConsoleStderr(t *testing.T) {
 	os.Stderr = w
 
 	ch := make(telemetry.ExtDSChan, 2)
-	p := New(context.Background(), config.Producer{}, cfg.Logger(), ch)
-	go p.Start()
+	p := New(config.Producer{}, cfg.Logger(), ch)
+	go p.Start(context.Background())
 
 	ch <- telemetry.ExtDataStore{
 		Output: "console::stderr",
@@ -78,6 +78,35 @@ func TestConsoleStderr(t *testing.T) {
 	close(ch)
 }
 
+func TestConsoleEncoding(t *testing.T) {
+	stdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	ch := make(telemetry.ExtDSChan, 2)
+	p := New(config.Producer{Config: map[string]interface{}{"encoding": "influx"}}, cfg.Logger(), ch)
+	go p.Start(context.Background())
+
+	ch <- telemetry.ExtDataStore{
+		Output: "console::stdout",
+		DS: telemetry.DataStore{
+			"prefix":    "/tests/test",
+			"labels":    map[string]string{},
+			"system_id": "127.0.0.1",
+			"timestamp": 150000000,
+			"key":       "mykey",
+			"value":     0,
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	io.CopyN(buf, r, 64)
+	os.Stdout = stdout
+	assert.Equal(t, "stdout,_prefix_=/tests/test,_host_=127.0.0.1 mykey=0i 150000000\n", buf.String())
+
+	close(ch)
+}
+
 func TestRegister(t *testing.T) {
 	r := producer.NewRegistrar(cfg.Logger())
 	Register(r)
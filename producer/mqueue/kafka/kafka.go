@@ -4,29 +4,32 @@
 package kafka
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/golang/protobuf/ptypes"
-	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/segmentio/kafka-go"
 	"github.com/segmentio/kafka-go/gzip"
 	"github.com/segmentio/kafka-go/lz4"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
 	"github.com/segmentio/kafka-go/snappy"
 	"go.uber.org/zap"
-	"google.golang.org/protobuf/proto"
-	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/lifecycle"
 	"github.com/yahoo/panoptes-stream/producer"
 	"github.com/yahoo/panoptes-stream/secret"
+	"github.com/yahoo/panoptes-stream/serializer"
+	"github.com/yahoo/panoptes-stream/status"
 	"github.com/yahoo/panoptes-stream/telemetry"
-
-	pb "github.com/yahoo/panoptes-stream/producer/proto"
 )
 
 type kafkaConfig struct {
@@ -39,78 +42,163 @@ type kafkaConfig struct {
 	KeepAlive     int
 	IOTimeout     int
 	Compression   string
+	Encoding      string
 	Protobuf      bool
+	ProtoVersion  int
+	Avro          bool
+	Balancer      string
+	Key           string
+	TopicKeys     map[string]string
+
+	SASLMechanism string
+	Username      string
+	Password      string
+
+	SchemaRegistry serializer.SchemaRegistryConfig
 
 	TLSConfig config.TLSConfig
 }
 
 // Kafka represents Kafka Segment.io
 type Kafka struct {
-	ctx    context.Context
-	cfg    config.Producer
-	ch     telemetry.ExtDSChan
-	logger *zap.Logger
+	lifecycle.Lifecycle
+
+	cfg     config.Producer
+	ch      telemetry.ExtDSChan
+	logger  *zap.Logger
+	metrics *status.OutputMetrics
 }
 
-// New constructs an instance of kafka producer.
-func New(ctx context.Context, cfg config.Producer, lg *zap.Logger, inChan telemetry.ExtDSChan) producer.Producer {
+// topicWriter represents a topic writer that runs in its own goroutine.
+type topicWriter struct {
+	lifecycle.Lifecycle
 
+	ch chan telemetry.DataStore
+}
+
+// New constructs an instance of kafka producer.
+func New(cfg config.Producer, lg *zap.Logger, inChan telemetry.ExtDSChan) producer.Producer {
 	return &Kafka{
-		ctx:    ctx,
-		cfg:    cfg,
-		ch:     inChan,
-		logger: lg,
+		cfg:     cfg,
+		ch:      inChan,
+		logger:  lg,
+		metrics: status.NewOutputMetrics(cfg.Name),
 	}
 }
 
 // Start sends the data to the different topics (fan-out).
-func (k *Kafka) Start() {
-	chMap := make(map[string]chan telemetry.DataStore)
+func (k *Kafka) Start(ctx context.Context) error {
+	return k.Run(ctx, k.start)
+}
+
+func (k *Kafka) start(ctx context.Context) error {
+	var wg sync.WaitGroup
+
 	config, err := k.getConfig()
 	if err != nil {
-		k.logger.Fatal("kafka", zap.Error(err))
+		return err
 	}
 
+	// the topic writers write the buffered data before start returns
+	defer wg.Wait()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the reload replaces k.cfg, the topic writers take the name by value
+	name := k.cfg.Name
+	errCh := make(chan error, len(config.Topics))
+	writers := make(map[string]*topicWriter)
+
 	for _, topic := range config.Topics {
-		chMap[topic] = make(chan telemetry.DataStore, 1000)
+		writers[topic] = &topicWriter{ch: make(chan telemetry.DataStore, 1000)}
 
-		go func(topic string, ch chan telemetry.DataStore) {
-			err := k.start(config, ch, topic)
+		wg.Add(1)
+		go func(topic string, tw *topicWriter) {
+			defer wg.Done()
+
+			err := tw.Run(ctx, func(ctx context.Context) error {
+				return k.startTopic(ctx, name, config, tw, topic)
+			})
 			if err != nil {
-				k.logger.Error("kafka", zap.Error(err))
+				k.logger.Error("kafka", zap.String("topic", topic), zap.Error(err))
+				errCh <- fmt.Errorf("topic %s: %v", topic, err)
+				cancel()
 			}
-		}(topic, chMap[topic])
+		}(topic, writers[topic])
 	}
 
-L:
 	for {
 		select {
 		case v, ok := <-k.ch:
 			if !ok {
-				break L
+				// drain the topic writers
+				for _, tw := range writers {
+					close(tw.ch)
+				}
+				wg.Wait()
+				return nil
 			}
 
+			k.metrics.DataIn.Inc()
+
 			topic := strings.Split(v.Output, "::")
 			if len(topic) < 2 {
 				k.logger.Error("kafka", zap.String("msg", "topic not found"), zap.String("output", v.Output))
+				k.AddDropped(1)
+				k.metrics.Error(status.ErrorInvalid)
 				continue
 			}
 
-			if _, ok := chMap[topic[1]]; ok {
-				chMap[topic[1]] <- v.DS
-			} else {
+			tw, ok := writers[topic[1]]
+			if !ok {
 				k.logger.Error("kafka", zap.String("msg", "topic not found"), zap.String("name", topic[1]))
+				k.AddDropped(1)
+				k.metrics.Error(status.ErrorInvalid)
+				continue
+			}
+
+			select {
+			case tw.ch <- v.DS:
+			case <-ctx.Done():
+				k.AddDropped(1)
+				k.metrics.Error(status.ErrorCanceled)
+			}
+
+		case req := <-k.FlushRequest():
+			var err error
+			for _, tw := range writers {
+				if e := tw.Flush(); e != nil {
+					err = e
+				}
+			}
+			req <- err
+
+		case req := <-k.ReloadRequest():
+			conf, err := k.reload(config, req.Config)
+			if err == nil {
+				for _, tw := range writers {
+					if e := tw.RequestReload(conf); e != nil {
+						err = e
+					}
+				}
+				config = conf
 			}
+			req.Reply(err)
 
-		case <-k.ctx.Done():
+		case <-ctx.Done():
 			k.logger.Info("kafka", zap.String("event", "terminate"), zap.String("brokers", strings.Join(config.Brokers, ",")))
-			return
+			select {
+			case err := <-errCh:
+				return err
+			default:
+				return nil
+			}
 		}
 	}
-
 }
 
-func (k *Kafka) start(config *kafkaConfig, ch chan telemetry.DataStore, topic string) error {
+func (k *Kafka) startTopic(ctx context.Context, name string, config *kafkaConfig, tw *topicWriter, topic string) error {
 	var (
 		batch = make([]kafka.Message, 0, config.BatchSize)
 		flush = false
@@ -118,33 +206,56 @@ func (k *Kafka) start(config *kafkaConfig, ch chan telemetry.DataStore, topic st
 	)
 
 	flushTicker := time.NewTicker(time.Second * time.Duration(config.BatchTimeout))
+	defer flushTicker.Stop()
 
 	cfg, err := k.getWriterConfig(config, topic)
 	if err != nil {
 		return err
 	}
 
+	keyFuncs, err := getKeyFuncs(config.getKey(topic))
+	if err != nil {
+		return err
+	}
+
+	keyBuf := new(bytes.Buffer)
+
+	s, err := serializer.New(config.Encoding, serializer.Config{SchemaRegistry: config.SchemaRegistry})
+	if err != nil {
+		return err
+	}
+
+	add := func(v telemetry.DataStore) {
+		b, err := s.Marshal(topic, v)
+		if err != nil {
+			k.logger.Error("kafka", zap.Error(err))
+			k.AddDropped(1)
+			k.metrics.Error(status.ErrorInvalid)
+			return
+		}
+
+		batch = append(batch, kafka.Message{Key: getMessageKey(keyBuf, keyFuncs, v), Value: b})
+	}
+
 	w := kafka.NewWriter(cfg)
+	defer w.Close()
+
+	lw := k.writer(config, topic)
+	write := func(ctx context.Context) (int, error) {
+		return batchBytes(batch), w.WriteMessages(ctx, batch...)
+	}
 
-	k.logger.Info("kafka", zap.String("name", k.cfg.Name), zap.String("brokers", strings.Join(config.Brokers, ",")), zap.String("topic", topic))
+	k.logger.Info("kafka", zap.String("name", name), zap.String("brokers", strings.Join(config.Brokers, ",")), zap.String("topic", topic))
 
 	for {
 		select {
-		case v := <-ch:
-			var b []byte
-
-			if config.Protobuf {
-				b, err = pbMarshal(v)
-			} else {
-				b, err = json.Marshal(v)
-			}
-
-			if err != nil {
-				k.logger.Error("kafka", zap.Error(err))
-				continue
+		case v, ok := <-tw.ch:
+			if !ok {
+				lw.Write(ctx, len(batch), write)
+				return nil
 			}
 
-			batch = append(batch, kafka.Message{Value: b})
+			add(v)
 
 		case <-flushTicker.C:
 			if len(batch) > 0 {
@@ -153,34 +264,116 @@ func (k *Kafka) start(config *kafkaConfig, ch chan telemetry.DataStore, topic st
 				continue
 			}
 
-		case <-k.ctx.Done():
-			k.logger.Info("kafka", zap.String("event", "terminate"), zap.String("topic", topic))
-			w.WriteMessages(k.ctx, batch...)
-			w.Close()
-			return nil
+		case req := <-tw.ReloadRequest():
+			config = req.Config.(*kafkaConfig)
+			flushTicker.Reset(time.Second * time.Duration(config.BatchTimeout))
+			req.Reply(nil)
+			continue
 
-		}
+		case req := <-tw.FlushRequest():
+			req <- lw.Write(ctx, len(batch), write)
+			flush = false
+			if ctx.Err() == nil {
+				batch = batch[:0]
+			}
+			continue
 
-		if len(batch) == config.BatchSize || flush {
-			for k.ctx.Err() == nil {
-				err := w.WriteMessages(k.ctx, batch...)
-				if err != nil {
-					k.logger.Error("kafka", zap.String("event", "write"), zap.Error(err))
+		case <-ctx.Done():
+			k.logger.Info("kafka", zap.String("event", "terminate"), zap.String("topic", topic))
 
-					// extra backoff
-					time.Sleep(1 * time.Second)
-					continue
+			// the queued data points are written with the batch
+			for drained := false; !drained; {
+				select {
+				case v, ok := <-tw.ch:
+					if ok {
+						add(v)
+					} else {
+						drained = true
+					}
+				default:
+					drained = true
 				}
+			}
+
+			for len(batch) > 0 {
+				n := len(batch)
+				if n > config.BatchSize {
+					n = config.BatchSize
+				}
+
+				chunk := batch[:n]
+				lw.Final(n, func(ctx context.Context) (int, error) {
+					return batchBytes(chunk), w.WriteMessages(ctx, chunk...)
+				})
 
-				break
+				batch = batch[n:]
 			}
+			return nil
+		}
 
+		if len(batch) >= config.BatchSize || flush {
+			// the canceled batch is kept and written on terminate
+			lw.Write(ctx, len(batch), write)
 			flush = false
-			batch = batch[:0]
+			if ctx.Err() == nil {
+				batch = batch[:0]
+			}
 		}
 	}
 }
 
+// writer returns the batch writer of the topic, it retries
+// the failed writes until the context is canceled.
+func (k *Kafka) writer(config *kafkaConfig, topic string) *lifecycle.Writer {
+	return &lifecycle.Writer{
+		Lifecycle:  &k.Lifecycle,
+		Metrics:    k.metrics,
+		MaxRetries: -1,
+		OnError: func(err error) {
+			if isSASLError(err) {
+				k.logger.Error("kafka", zap.String("event", "sasl.auth"), zap.String("mechanism", config.SASLMechanism),
+					zap.String("topic", topic), zap.String("msg", "authentication failed"), zap.Error(err))
+			}
+
+			k.logger.Error("kafka", zap.String("event", "write"), zap.Error(err))
+		},
+	}
+}
+
+// Reload applies the batch size and batch timeout
+// changes without reconnecting.
+func (k *Kafka) Reload(cfg config.Producer) error {
+	return k.RequestReload(cfg)
+}
+
+// reload returns the new configuration if only the
+// batch size or batch timeout changed.
+func (k *Kafka) reload(current *kafkaConfig, cfg interface{}) (*kafkaConfig, error) {
+	n := &Kafka{cfg: cfg.(config.Producer)}
+	conf, err := n.getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if !lifecycle.OnlyChanged(current, conf, "BatchSize", "BatchTimeout") {
+		return nil, lifecycle.ErrRestartRequired
+	}
+
+	k.cfg = n.cfg
+
+	return conf, nil
+}
+
+// batchBytes returns the keys and values size of the batch.
+func batchBytes(batch []kafka.Message) int {
+	var n int
+	for _, m := range batch {
+		n += len(m.Key) + len(m.Value)
+	}
+
+	return n
+}
+
 func (k *Kafka) getConfig() (*kafkaConfig, error) {
 	conf := new(kafkaConfig)
 	b, err := json.Marshal(k.cfg.Config)
@@ -201,17 +394,58 @@ func (k *Kafka) getConfig() (*kafkaConfig, error) {
 
 	config.SetDefault(&conf.BatchSize, 1000)
 	config.SetDefault(&conf.BatchTimeout, 1)
+	config.SetDefault(&conf.ProtoVersion, 1)
+
+	if conf.Encoding == "" {
+		conf.Encoding = getEncoding(conf.Protobuf, conf.Avro, conf.ProtoVersion)
+	}
+
+	if err := serializer.Validate(conf.Encoding); err != nil {
+		return nil, err
+	}
+
+	if conf.Balancer == "" {
+		conf.Balancer = "leastbytes"
+		if conf.Key != "" || len(conf.TopicKeys) > 0 {
+			// compatible with the Java client default partitioner
+			conf.Balancer = "murmur2"
+		}
+	}
+
+	if _, err := getBalancer(conf.Balancer); err != nil {
+		return nil, err
+	}
+
+	switch strings.ToUpper(conf.SASLMechanism) {
+	case "", "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512":
+	default:
+		return nil, fmt.Errorf("sasl mechanism %s not supported", conf.SASLMechanism)
+	}
+
+	for _, topic := range conf.Topics {
+		if _, err := getKeyFuncs(conf.getKey(topic)); err != nil {
+			return nil, err
+		}
+	}
 
 	return conf, nil
 }
 
+// getKey returns the message key template of the topic.
+func (c *kafkaConfig) getKey(topic string) string {
+	if key, ok := c.TopicKeys[topic]; ok {
+		return key
+	}
+
+	return c.Key
+}
+
 func (k *Kafka) getWriterConfig(config *kafkaConfig, topic string) (kafka.WriterConfig, error) {
 	var err error
 
 	cfg := kafka.WriterConfig{
 		Brokers:       config.Brokers,
 		Topic:         topic,
-		Balancer:      &kafka.LeastBytes{},
 		MaxAttempts:   config.MaxAttempts,
 		QueueCapacity: config.QueueCapacity,
 		ReadTimeout:   time.Duration(config.IOTimeout) * time.Second,
@@ -224,6 +458,11 @@ func (k *Kafka) getWriterConfig(config *kafkaConfig, topic string) (kafka.Writer
 		},
 	}
 
+	cfg.Balancer, err = getBalancer(config.Balancer)
+	if err != nil {
+		return cfg, err
+	}
+
 	if config.TLSConfig.Enabled {
 		cfg.Dialer.TLS, err = secret.GetTLSConfig(&config.TLSConfig)
 		if err != nil {
@@ -231,6 +470,17 @@ func (k *Kafka) getWriterConfig(config *kafkaConfig, topic string) (kafka.Writer
 		}
 	}
 
+	if config.SASLMechanism != "" {
+		cfg.Dialer.SASLMechanism, err = getSASLMechanism(config)
+		if err != nil {
+			return cfg, err
+		}
+
+		if !config.TLSConfig.Enabled && strings.ToUpper(config.SASLMechanism) == "PLAIN" {
+			k.logger.Warn("kafka", zap.String("event", "sasl"), zap.String("msg", "PLAIN mechanism without TLS sends credentials in clear text"))
+		}
+	}
+
 	switch config.Compression {
 	case "gzip":
 		cfg.CompressionCodec = gzip.NewCompressionCodec()
@@ -245,60 +495,123 @@ func (k *Kafka) getWriterConfig(config *kafkaConfig, topic string) (kafka.Writer
 	return cfg, err
 }
 
-func pbMarshal(v telemetry.DataStore) ([]byte, error) {
-	var (
-		anypb *anypb.Any
-		err   error
-	)
+func getBalancer(name string) (kafka.Balancer, error) {
+	switch strings.ToLower(name) {
+	case "leastbytes", "":
+		return &kafka.LeastBytes{}, nil
+	case "roundrobin":
+		return &kafka.RoundRobin{}, nil
+	case "hash":
+		return &kafka.Hash{}, nil
+	case "crc32":
+		return kafka.CRC32Balancer{}, nil
+	case "murmur2":
+		return kafka.Murmur2Balancer{}, nil
+	}
 
-	pbMsg := pb.Panoptes{
-		Prefix:    v["prefix"].(string),
-		Labels:    v["labels"].(map[string]string),
-		SystemId:  v["system_id"].(string),
-		Key:       v["key"].(string),
-		Timestamp: v["timestamp"].(int64),
-	}
-
-	switch v["value"].(type) {
-	case string:
-		anypb, err = ptypes.MarshalAny(&wrappers.StringValue{
-			Value: v["value"].(string)})
-	case int:
-		anypb, err = ptypes.MarshalAny(&wrappers.Int64Value{
-			Value: int64(v["value"].(int))})
-	case int32:
-		anypb, err = ptypes.MarshalAny(&wrappers.Int32Value{
-			Value: v["value"].(int32)})
-	case int64:
-		anypb, err = ptypes.MarshalAny(&wrappers.Int64Value{
-			Value: v["value"].(int64)})
-	case uint:
-		anypb, err = ptypes.MarshalAny(&wrappers.UInt64Value{
-			Value: uint64(v["value"].(uint))})
-	case uint32:
-		anypb, err = ptypes.MarshalAny(&wrappers.UInt32Value{
-			Value: v["value"].(uint32)})
-	case uint64:
-		anypb, err = ptypes.MarshalAny(&wrappers.UInt64Value{
-			Value: v["value"].(uint64)})
-	case bool:
-		anypb, err = ptypes.MarshalAny(&wrappers.BoolValue{
-			Value: v["value"].(bool)})
-	case []byte:
-		anypb, err = ptypes.MarshalAny(&wrappers.BytesValue{
-			Value: v["value"].([]byte)})
+	return nil, fmt.Errorf("balancer %s not supported", name)
+}
 
-	default:
-		return nil, errors.New("unknown type")
+func getSASLMechanism(config *kafkaConfig) (sasl.Mechanism, error) {
+	username, password, err := secret.GetUsernamePassword(config.Username, config.Password)
+	if err != nil {
+		return nil, fmt.Errorf("sasl credentials: %v", err)
 	}
 
-	if err != nil {
-		return nil, err
+	switch strings.ToUpper(config.SASLMechanism) {
+	case "PLAIN":
+		return plain.Mechanism{Username: username, Password: password}, nil
+	case "SCRAM-SHA-256":
+		return scram.Mechanism(scram.SHA256, username, password)
+	case "SCRAM-SHA-512":
+		return scram.Mechanism(scram.SHA512, username, password)
+	}
+
+	return nil, fmt.Errorf("sasl mechanism %s not supported", config.SASLMechanism)
+}
+
+func isSASLError(err error) bool {
+	var kErr kafka.Error
+	if !errors.As(err, &kErr) {
+		return false
+	}
+
+	switch kErr {
+	case kafka.SASLAuthenticationFailed, kafka.UnsupportedSASLMechanism, kafka.IllegalSASLState:
+		return true
 	}
 
-	pbMsg.Value = anypb
+	return false
+}
+
+// keyFunc returns a part of the message key.
+type keyFunc func(telemetry.DataStore) string
 
-	b, err := proto.Marshal(&pbMsg)
+// getKeyFuncs parses the key template. The template is a list of
+// system_id, prefix, key and labels.<name> that joined by plus sign,
+// e.g. system_id+prefix or system_id+labels.name
+func getKeyFuncs(template string) ([]keyFunc, error) {
+	var keyFuncs []keyFunc
+
+	if template == "" {
+		return nil, nil
+	}
+
+	for _, part := range strings.Split(template, "+") {
+		part = strings.TrimSpace(part)
+
+		switch {
+		case part == "system_id", part == "prefix", part == "key":
+			name := part
+			keyFuncs = append(keyFuncs, func(ds telemetry.DataStore) string {
+				v, _ := ds[name].(string)
+				return v
+			})
+		case strings.HasPrefix(part, "labels.") && len(part) > len("labels."):
+			name := strings.TrimPrefix(part, "labels.")
+			keyFuncs = append(keyFuncs, func(ds telemetry.DataStore) string {
+				labels, _ := ds["labels"].(map[string]string)
+				return labels[name]
+			})
+		default:
+			return nil, fmt.Errorf("invalid key: %s", part)
+		}
+	}
+
+	return keyFuncs, nil
+}
+
+// getMessageKey returns the message key based on the key functions
+// or nil if the key doesn't configured.
+func getMessageKey(buf *bytes.Buffer, keyFuncs []keyFunc, ds telemetry.DataStore) []byte {
+	if len(keyFuncs) < 1 {
+		return nil
+	}
+
+	buf.Reset()
+	for i, f := range keyFuncs {
+		if i > 0 {
+			buf.WriteString("::")
+		}
+		buf.WriteString(f(ds))
+	}
+
+	key := make([]byte, buf.Len())
+	copy(key, buf.Bytes())
+
+	return key
+}
+
+// getEncoding returns encoding name based on the legacy
+// protobuf and avro options.
+func getEncoding(protobuf, avro bool, protoVersion int) string {
+	if avro {
+		return "avro"
+	}
+
+	if protobuf {
+		return fmt.Sprintf("protobuf-v%d", protoVersion)
+	}
 
-	return b, err
+	return "json"
 }
@@ -4,16 +4,18 @@
 package kafka
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Shopify/sarama"
+	"github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/yahoo/panoptes-stream/config"
-	pb "github.com/yahoo/panoptes-stream/producer/proto"
 	"github.com/yahoo/panoptes-stream/telemetry"
-	"google.golang.org/protobuf/proto"
 )
 
 var mockConfig = config.NewMockConfig()
@@ -37,8 +39,8 @@ func TestKafkaStart(t *testing.T) {
 
 	ch := make(telemetry.ExtDSChan)
 
-	producer := New(ctx, cfg, mockConfig.Logger(), ch)
-	go producer.Start()
+	producer := New(cfg, mockConfig.Logger(), ch)
+	go producer.Start(ctx)
 
 	time.Sleep(1 * time.Second)
 
@@ -67,8 +69,8 @@ func TestBatchRetry(t *testing.T) {
 	ch := make(telemetry.ExtDSChan)
 	mockConfig.LogOutput.Reset()
 
-	producer := New(ctx, cfg, mockConfig.Logger(), ch)
-	go producer.Start()
+	producer := New(cfg, mockConfig.Logger(), ch)
+	go producer.Start(ctx)
 
 	ch <- telemetry.ExtDataStore{Output: "kafka01::topic1", DS: telemetry.DataStore{"labels": "test"}}
 
@@ -83,46 +85,203 @@ func TestBatchRetry(t *testing.T) {
 	}
 
 	assert.Equal(t, 3, counter)
+	assert.Error(t, producer.Health())
 }
 
-func TestPBMarshal(t *testing.T) {
+func TestStopDrain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	conf := &kafkaConfig{
+		Brokers:      []string{"127.0.0.1:1"},
+		BatchSize:    2,
+		BatchTimeout: 1,
+		MaxAttempts:  1,
+		IOTimeout:    1,
+		Encoding:     "json",
+	}
+
+	k := New(config.Producer{Name: "kafka01"}, mockConfig.Logger(), nil).(*Kafka)
+
+	// the topic writer is stopped with the queued data points
+	tw := &topicWriter{ch: make(chan telemetry.DataStore, 10)}
+	for i := 0; i < 5; i++ {
+		tw.ch <- telemetry.DataStore{"key": "out-octets", "value": i}
+	}
+
+	assert.NoError(t, k.startTopic(ctx, "kafka01", conf, tw, "topic1"))
+	assert.Len(t, tw.ch, 0)
+
+	stats := k.Stats()
+	assert.Equal(t, uint64(5), stats.Sent+stats.Failed)
+}
+
+func TestWriteCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	conf := &kafkaConfig{Brokers: []string{"127.0.0.1:1"}, MaxAttempts: 1, IOTimeout: 1}
+	k := New(config.Producer{Name: "kafka01"}, mockConfig.Logger(), nil).(*Kafka)
+
+	w := kafka.NewWriter(kafka.WriterConfig{Brokers: conf.Brokers, Topic: "topic1", MaxAttempts: 1})
+	defer w.Close()
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	// the retry backoff doesn't hold the canceled write
+	batch := []kafka.Message{{Value: []byte("1")}}
+	start := time.Now()
+	err := k.writer(conf, "topic1").Write(ctx, len(batch), func(ctx context.Context) (int, error) {
+		return batchBytes(batch), w.WriteMessages(ctx, batch...)
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Less(t, int64(time.Since(start)), int64(900*time.Millisecond))
+
+	// the canceled batch isn't failed, it's kept for the final write
+	assert.Equal(t, uint64(0), k.Stats().Failed)
+}
+
+func TestMessageKey(t *testing.T) {
+	buf := new(bytes.Buffer)
 	ds := telemetry.DataStore{
-		"prefix":    "/foos/foo",
-		"labels":    map[string]string{"l1": "v1"},
-		"timestamp": int64(1610395484),
+		"prefix":    "/interfaces/interface/state/counters",
+		"labels":    map[string]string{"name": "et-0/0/0"},
 		"system_id": "core1.lax",
-		"key":       "counter1",
-		"value":     int(55),
+		"key":       "in-octets",
 	}
 
-	// general error
-	b, err := pbMarshal(ds)
+	keyFuncs, err := getKeyFuncs("system_id")
 	assert.NoError(t, err)
-	assert.NotZero(t, len(b))
-
-	// unmarshal
-	m := pb.Panoptes{}
-	proto.Unmarshal(b, &m)
-
-	assert.Equal(t, "counter1", m.Key)
-	assert.Equal(t, "core1.lax", m.SystemId)
-	assert.Equal(t, "/foos/foo", m.Prefix)
-	assert.Equal(t, int64(1610395484), m.Timestamp)
-	assert.Equal(t, map[string]string{"l1": "v1"}, m.Labels)
-	assert.Equal(t, "type.googleapis.com/google.protobuf.Int64Value", m.Value.TypeUrl)
-	assert.Equal(t, m.Value.Value, []uint8{0x8, 0x37})
-
-	// known types
-	tt := []interface{}{"foo", int(5), int32(5), int64(5), uint(5), uint32(5), uint64(5), true, []byte{0x8}}
-
-	for _, v := range tt {
-		ds["value"] = v
-		_, err = pbMarshal(ds)
+	assert.Equal(t, []byte("core1.lax"), getMessageKey(buf, keyFuncs, ds))
+
+	keyFuncs, err = getKeyFuncs("system_id+prefix")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("core1.lax::/interfaces/interface/state/counters"), getMessageKey(buf, keyFuncs, ds))
+
+	keyFuncs, err = getKeyFuncs("system_id + labels.name")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("core1.lax::et-0/0/0"), getMessageKey(buf, keyFuncs, ds))
+
+	// not configured
+	keyFuncs, err = getKeyFuncs("")
+	assert.NoError(t, err)
+	assert.Nil(t, getMessageKey(buf, keyFuncs, ds))
+
+	// invalid
+	_, err = getKeyFuncs("system_id+labels.")
+	assert.Error(t, err)
+	_, err = getKeyFuncs("hostname")
+	assert.Error(t, err)
+}
+
+func TestKeyConfig(t *testing.T) {
+	cfg := config.Producer{
+		Name:    "kafka01",
+		Service: "kafka",
+		Config: map[string]interface{}{
+			"brokers":   []string{"127.0.0.1:9092"},
+			"topics":    []string{"topic1", "topic2"},
+			"key":       "system_id",
+			"topicKeys": map[string]string{"topic2": "system_id+prefix"},
+		},
+	}
+
+	k := &Kafka{cfg: cfg}
+	conf, err := k.getConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "murmur2", conf.Balancer)
+	assert.Equal(t, "system_id", conf.getKey("topic1"))
+	assert.Equal(t, "system_id+prefix", conf.getKey("topic2"))
+
+	wCfg, err := k.getWriterConfig(conf, "topic1")
+	assert.NoError(t, err)
+	assert.IsType(t, kafka.Murmur2Balancer{}, wCfg.Balancer)
+
+	// default balancer without key
+	cfg.Config = map[string]interface{}{
+		"brokers": []string{"127.0.0.1:9092"},
+		"topics":  []string{"topic1"},
+	}
+	k = &Kafka{cfg: cfg}
+	conf, err = k.getConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "leastbytes", conf.Balancer)
+
+	// invalid balancer
+	cfg.Config = map[string]interface{}{
+		"brokers":  []string{"127.0.0.1:9092"},
+		"topics":   []string{"topic1"},
+		"balancer": "unknown",
+	}
+	k = &Kafka{cfg: cfg}
+	_, err = k.getConfig()
+	assert.Error(t, err)
+}
+
+func TestSASLMechanism(t *testing.T) {
+	conf := &kafkaConfig{Username: "panoptes", Password: "secret"}
+
+	for _, name := range []string{"PLAIN", "scram-sha-256", "SCRAM-SHA-512"} {
+		conf.SASLMechanism = name
+		m, err := getSASLMechanism(conf)
 		assert.NoError(t, err)
+		assert.Equal(t, strings.ToUpper(name), m.Name())
 	}
 
-	// unknown type
-	ds["value"] = make(chan int)
-	_, err = pbMarshal(ds)
+	conf.SASLMechanism = "GSSAPI"
+	_, err := getSASLMechanism(conf)
+	assert.Error(t, err)
+
+	// writer config
+	k := &Kafka{logger: mockConfig.Logger()}
+	conf = &kafkaConfig{
+		Brokers:       []string{"127.0.0.1:9092"},
+		SASLMechanism: "SCRAM-SHA-512",
+		Username:      "panoptes",
+		Password:      "secret",
+	}
+	wCfg, err := k.getWriterConfig(conf, "topic1")
+	assert.NoError(t, err)
+	assert.Equal(t, "SCRAM-SHA-512", wCfg.Dialer.SASLMechanism.Name())
+
+	// invalid mechanism at config
+	k.cfg = config.Producer{
+		Name: "kafka01",
+		Config: map[string]interface{}{
+			"brokers":       []string{"127.0.0.1:9092"},
+			"topics":        []string{"topic1"},
+			"saslMechanism": "GSSAPI",
+		},
+	}
+	_, err = k.getConfig()
+	assert.Error(t, err)
+
+	assert.True(t, isSASLError(kafka.SASLAuthenticationFailed))
+	assert.False(t, isSASLError(kafka.LeaderNotAvailable))
+	assert.False(t, isSASLError(errors.New("unknown")))
+}
+
+func TestEncoding(t *testing.T) {
+	assert.Equal(t, "json", getEncoding(false, false, 1))
+	assert.Equal(t, "protobuf-v1", getEncoding(true, false, 1))
+	assert.Equal(t, "protobuf-v2", getEncoding(true, false, 2))
+	assert.Equal(t, "avro", getEncoding(false, true, 1))
+
+	k := &Kafka{cfg: config.Producer{
+		Name: "kafka01",
+		Config: map[string]interface{}{
+			"brokers":  []string{"127.0.0.1:9092"},
+			"topics":   []string{"topic1"},
+			"encoding": "cbor",
+		},
+	}}
+	conf, err := k.getConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "cbor", conf.Encoding)
+
+	k.cfg.Config = map[string]interface{}{"encoding": "xml"}
+	_, err = k.getConfig()
 	assert.Error(t, err)
 }
@@ -6,7 +6,9 @@ package nsq
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
@@ -14,7 +16,10 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/lifecycle"
 	"github.com/yahoo/panoptes-stream/producer"
+	"github.com/yahoo/panoptes-stream/serializer"
+	"github.com/yahoo/panoptes-stream/status"
 	"github.com/yahoo/panoptes-stream/telemetry"
 )
 
@@ -23,75 +28,151 @@ type nsqConfig struct {
 	Topics       []string
 	BatchSize    int
 	BatchTimeout int
+	Encoding     string
+	Protobuf     bool
+	ProtoVersion int
+
+	SchemaRegistry serializer.SchemaRegistryConfig
 }
 
 type noLogger struct{}
 
 // NSQ represents nsq producer
 type NSQ struct {
-	ctx    context.Context
-	cfg    config.Producer
-	ch     telemetry.ExtDSChan
-	logger *zap.Logger
+	lifecycle.Lifecycle
+
+	cfg     config.Producer
+	ch      telemetry.ExtDSChan
+	logger  *zap.Logger
+	metrics *status.OutputMetrics
+}
+
+// topicWriter represents a topic publisher that runs in its own goroutine.
+type topicWriter struct {
+	lifecycle.Lifecycle
+
+	ch chan telemetry.DataStore
 }
 
 // New constructs an instance of NSQ producer.
-func New(ctx context.Context, cfg config.Producer, lg *zap.Logger, inChan telemetry.ExtDSChan) producer.Producer {
+func New(cfg config.Producer, lg *zap.Logger, inChan telemetry.ExtDSChan) producer.Producer {
 	return &NSQ{
-		ctx:    ctx,
-		cfg:    cfg,
-		ch:     inChan,
-		logger: lg,
+		cfg:     cfg,
+		ch:      inChan,
+		logger:  lg,
+		metrics: status.NewOutputMetrics(cfg.Name),
 	}
 }
 
 // Start sends the data to the different topics (fan-out).
-func (n *NSQ) Start() {
-	chMap := make(map[string]chan telemetry.DataStore)
+func (n *NSQ) Start(ctx context.Context) error {
+	return n.Run(ctx, n.start)
+}
+
+func (n *NSQ) start(ctx context.Context) error {
+	var wg sync.WaitGroup
+
 	config, err := n.getConfig()
 	if err != nil {
-		n.logger.Fatal("nsq", zap.Error(err))
+		return err
 	}
 
+	// the topic writers publish the buffered data before start returns
+	defer wg.Wait()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errCh := make(chan error, len(config.Topics))
+	writers := make(map[string]*topicWriter)
+
 	for _, topic := range config.Topics {
-		chMap[topic] = make(chan telemetry.DataStore, 1000)
+		writers[topic] = &topicWriter{ch: make(chan telemetry.DataStore, 1000)}
+
+		wg.Add(1)
+		go func(topic string, tw *topicWriter) {
+			defer wg.Done()
 
-		go func(topic string, ch chan telemetry.DataStore) {
-			err := n.start(config, ch, topic)
+			err := tw.Run(ctx, func(ctx context.Context) error {
+				return n.startTopic(ctx, config, tw, topic)
+			})
 			if err != nil {
-				n.logger.Error("nsq", zap.Error(err))
+				n.logger.Error("nsq", zap.String("topic", topic), zap.Error(err))
+				errCh <- fmt.Errorf("topic %s: %v", topic, err)
+				cancel()
 			}
-		}(topic, chMap[topic])
+		}(topic, writers[topic])
 	}
 
-L:
 	for {
 		select {
 		case v, ok := <-n.ch:
 			if !ok {
-				break L
+				// drain the topic writers
+				for _, tw := range writers {
+					close(tw.ch)
+				}
+				wg.Wait()
+				return nil
 			}
 
+			n.metrics.DataIn.Inc()
+
 			topic := strings.Split(v.Output, "::")
 			if len(topic) < 2 {
 				n.logger.Error("nsq", zap.String("msg", "topic not found"), zap.String("output", v.Output))
+				n.AddDropped(1)
+				n.metrics.Error(status.ErrorInvalid)
 				continue
 			}
 
-			if _, ok := chMap[topic[1]]; ok {
-				chMap[topic[1]] <- v.DS
-			} else {
+			tw, ok := writers[topic[1]]
+			if !ok {
 				n.logger.Error("nsq", zap.String("msg", "topic not found"), zap.String("name", topic[1]))
+				n.AddDropped(1)
+				n.metrics.Error(status.ErrorInvalid)
+				continue
 			}
 
-		case <-n.ctx.Done():
+			select {
+			case tw.ch <- v.DS:
+			case <-ctx.Done():
+			}
+
+		case req := <-n.FlushRequest():
+			var err error
+			for _, tw := range writers {
+				if e := tw.Flush(); e != nil {
+					err = e
+				}
+			}
+			req <- err
+
+		case req := <-n.ReloadRequest():
+			conf, err := n.reload(config, req.Config)
+			if err == nil {
+				for _, tw := range writers {
+					if e := tw.RequestReload(conf); e != nil {
+						err = e
+					}
+				}
+				config = conf
+			}
+			req.Reply(err)
+
+		case <-ctx.Done():
 			n.logger.Info("nsq", zap.String("event", "terminate"))
-			return
+			select {
+			case err := <-errCh:
+				return err
+			default:
+				return nil
+			}
 		}
 	}
 }
 
-func (n *NSQ) start(config *nsqConfig, ch chan telemetry.DataStore, topic string) error {
+func (n *NSQ) startTopic(ctx context.Context, config *nsqConfig, tw *topicWriter, topic string) error {
 	var (
 		batch = make([][]byte, 0)
 		flush = false
@@ -100,19 +181,53 @@ func (n *NSQ) start(config *nsqConfig, ch chan telemetry.DataStore, topic string
 	pConfig := gonsq.NewConfig()
 	pConfig.UserAgent = "panoptes"
 	pConfig.DialTimeout = 2 * time.Second
-	producer, _ := gonsq.NewProducer(config.Addr, pConfig)
+	producer, err := gonsq.NewProducer(config.Addr, pConfig)
+	if err != nil {
+		return err
+	}
 	producer.SetLogger(&noLogger{}, 0)
+	defer producer.Stop()
 
 	if err := producer.Ping(); err != nil {
 		return err
 	}
 
+	s, err := serializer.New(config.Encoding, serializer.Config{SchemaRegistry: config.SchemaRegistry})
+	if err != nil {
+		return err
+	}
+
+	w := &lifecycle.Writer{
+		Lifecycle:  &n.Lifecycle,
+		Metrics:    n.metrics,
+		MaxRetries: -1,
+		OnError: func(err error) {
+			n.logger.Error("nsq", zap.String("event", "publish"), zap.Error(err))
+		},
+	}
+	publish := func(context.Context) (int, error) {
+		return batchBytes(batch), producer.MultiPublish(topic, batch)
+	}
+
 	flushTicker := time.NewTicker(time.Second * time.Duration(config.BatchTimeout))
+	defer flushTicker.Stop()
 
 	for {
 		select {
-		case v := <-ch:
-			b, _ := json.Marshal(v)
+		case v, ok := <-tw.ch:
+			if !ok {
+				w.Write(ctx, len(batch), publish)
+				return nil
+			}
+
+			b, err := s.Marshal(topic, v)
+			if err != nil {
+				n.logger.Error("nsq", zap.Error(err))
+				n.AddDropped(1)
+				n.metrics.Error(status.ErrorInvalid)
+				continue
+			}
+
 			batch = append(batch, b)
 
 		case <-flushTicker.C:
@@ -122,33 +237,70 @@ func (n *NSQ) start(config *nsqConfig, ch chan telemetry.DataStore, topic string
 				continue
 			}
 
-		case <-n.ctx.Done():
+		case req := <-tw.ReloadRequest():
+			config = req.Config.(*nsqConfig)
+			flushTicker.Reset(time.Second * time.Duration(config.BatchTimeout))
+			req.Reply(nil)
+			continue
+
+		case req := <-tw.FlushRequest():
+			req <- w.Write(ctx, len(batch), publish)
+			flush = false
+			if ctx.Err() == nil {
+				batch = batch[:0]
+			}
+			continue
+
+		case <-ctx.Done():
 			n.logger.Info("nsq", zap.String("event", "terminate"), zap.String("topic", topic))
-			producer.MultiPublish(topic, batch)
-			producer.Stop()
+			w.Final(len(batch), publish)
 			return nil
 		}
 
 		if len(batch) == config.BatchSize || flush {
-			for n.ctx.Err() == nil {
-				err := producer.MultiPublish(topic, batch)
-				if err != nil {
-					n.logger.Error("nsq", zap.String("event", "publish"), zap.Error(err))
-
-					// backoff
-					time.Sleep(1 * time.Second)
-					continue
-				}
-
-				break
-			}
-
+			w.Write(ctx, len(batch), publish)
 			flush = false
-			batch = batch[:0]
+			if ctx.Err() == nil {
+				batch = batch[:0]
+			}
 		}
 	}
 }
 
+// Reload applies the batch size and batch timeout
+// changes without reconnecting.
+func (n *NSQ) Reload(cfg config.Producer) error {
+	return n.RequestReload(cfg)
+}
+
+// reload returns the new configuration if only the
+// batch size or batch timeout changed.
+func (n *NSQ) reload(current *nsqConfig, cfg interface{}) (*nsqConfig, error) {
+	r := &NSQ{cfg: cfg.(config.Producer)}
+	conf, err := r.getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if !lifecycle.OnlyChanged(current, conf, "BatchSize", "BatchTimeout") {
+		return nil, lifecycle.ErrRestartRequired
+	}
+
+	n.cfg = r.cfg
+
+	return conf, nil
+}
+
+// batchBytes returns the messages size of the batch.
+func batchBytes(batch [][]byte) int {
+	var n int
+	for _, b := range batch {
+		n += len(b)
+	}
+
+	return n
+}
+
 func (n *NSQ) getConfig() (*nsqConfig, error) {
 	conf := new(nsqConfig)
 	b, err := json.Marshal(n.cfg.Config)
@@ -169,6 +321,18 @@ func (n *NSQ) getConfig() (*nsqConfig, error) {
 
 	config.SetDefault(&conf.BatchSize, 1000)
 	config.SetDefault(&conf.BatchTimeout, 1)
+	config.SetDefault(&conf.ProtoVersion, 1)
+
+	if conf.Encoding == "" {
+		conf.Encoding = "json"
+		if conf.Protobuf {
+			conf.Encoding = fmt.Sprintf("protobuf-v%d", conf.ProtoVersion)
+		}
+	}
+
+	if err := serializer.Validate(conf.Encoding); err != nil {
+		return nil, err
+	}
 
 	return conf, nil
 }
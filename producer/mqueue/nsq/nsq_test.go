@@ -45,8 +45,8 @@ func TestStart(t *testing.T) {
 		},
 	}
 
-	p := New(ctx, cfg, mCfg.Logger(), ch)
-	go p.Start()
+	p := New(cfg, mCfg.Logger(), ch)
+	go p.Start(ctx)
 
 	ch <- telemetry.ExtDataStore{
 		Output: "nsq01::bgp",
@@ -112,3 +112,36 @@ func nsqServer(ctx context.Context, t *testing.T) (*exec.Cmd, string) {
 
 	return cmd, dir
 }
+
+func TestEncoding(t *testing.T) {
+	n := &NSQ{cfg: config.Producer{Name: "nsq01", Config: map[string]interface{}{
+		"addr":         "127.0.0.1:4150",
+		"topics":       []string{"topic1"},
+		"protobuf":     true,
+		"protoVersion": 2,
+	}}}
+
+	conf, err := n.getConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "protobuf-v2", conf.Encoding)
+
+	n.cfg.Config = map[string]interface{}{"encoding": "msgpack"}
+	conf, err = n.getConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "msgpack", conf.Encoding)
+
+	n.cfg.Config = map[string]interface{}{}
+	conf, err = n.getConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "json", conf.Encoding)
+
+	// unsupported version
+	n.cfg.Config = map[string]interface{}{"protobuf": true, "protoVersion": 3}
+	_, err = n.getConfig()
+	assert.Error(t, err)
+
+	// unsupported encoding
+	n.cfg.Config = map[string]interface{}{"encoding": "xml"}
+	_, err = n.getConfig()
+	assert.Error(t, err)
+}
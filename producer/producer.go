@@ -9,13 +9,31 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/lifecycle"
 	"github.com/yahoo/panoptes-stream/telemetry"
 )
 
 // Factory is a function that returns a new instance of producer
-type Factory func(context.Context, config.Producer, *zap.Logger, telemetry.ExtDSChan) Producer
+type Factory func(config.Producer, *zap.Logger, telemetry.ExtDSChan) Producer
 
 // Producer represents a producer
 type Producer interface {
-	Start()
+	// Start runs the producer until the context is canceled or
+	// Stop is called, it returns an error if the producer fails.
+	Start(context.Context) error
+	// Stop stops the producer after the buffered data is written.
+	Stop()
+	// Flush writes the buffered data.
+	Flush() error
+	// Health returns nil if the producer is healthy.
+	Health() error
+	// Stats returns the producer statistics.
+	Stats() lifecycle.Stats
+}
+
+// Reloader is implemented by the producers that apply a configuration
+// change without reconnecting, Reload returns an error if the change
+// requires a new instance.
+type Reloader interface {
+	Reload(config.Producer) error
 }
@@ -0,0 +1,75 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package panoptes
+
+import (
+	"errors"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+// Marshal returns the protobuf encoding of datastore (schema version 1).
+// the value wraps by google.protobuf.Any.
+func Marshal(v telemetry.DataStore) ([]byte, error) {
+	var (
+		anypb *anypb.Any
+		err   error
+	)
+
+	pbMsg := Panoptes{
+		Prefix:    v["prefix"].(string),
+		Labels:    v["labels"].(map[string]string),
+		SystemId:  v["system_id"].(string),
+		Key:       v["key"].(string),
+		Timestamp: v["timestamp"].(int64),
+	}
+
+	switch v["value"].(type) {
+	case string:
+		anypb, err = ptypes.MarshalAny(&wrappers.StringValue{
+			Value: v["value"].(string)})
+	case int:
+		anypb, err = ptypes.MarshalAny(&wrappers.Int64Value{
+			Value: int64(v["value"].(int))})
+	case int32:
+		anypb, err = ptypes.MarshalAny(&wrappers.Int32Value{
+			Value: v["value"].(int32)})
+	case int64:
+		anypb, err = ptypes.MarshalAny(&wrappers.Int64Value{
+			Value: v["value"].(int64)})
+	case uint:
+		anypb, err = ptypes.MarshalAny(&wrappers.UInt64Value{
+			Value: uint64(v["value"].(uint))})
+	case uint32:
+		anypb, err = ptypes.MarshalAny(&wrappers.UInt32Value{
+			Value: v["value"].(uint32)})
+	case uint64:
+		anypb, err = ptypes.MarshalAny(&wrappers.UInt64Value{
+			Value: v["value"].(uint64)})
+	case bool:
+		anypb, err = ptypes.MarshalAny(&wrappers.BoolValue{
+			Value: v["value"].(bool)})
+	case []byte:
+		anypb, err = ptypes.MarshalAny(&wrappers.BytesValue{
+			Value: v["value"].([]byte)})
+
+	default:
+		return nil, errors.New("unknown type")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	pbMsg.Value = anypb
+
+	b, err := proto.Marshal(&pbMsg)
+
+	return b, err
+}
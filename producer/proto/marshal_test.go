@@ -0,0 +1,55 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package panoptes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+func TestMarshal(t *testing.T) {
+	ds := telemetry.DataStore{
+		"prefix":    "/foos/foo",
+		"labels":    map[string]string{"l1": "v1"},
+		"timestamp": int64(1610395484),
+		"system_id": "core1.lax",
+		"key":       "counter1",
+		"value":     int(55),
+	}
+
+	// general error
+	b, err := Marshal(ds)
+	assert.NoError(t, err)
+	assert.NotZero(t, len(b))
+
+	// unmarshal
+	m := Panoptes{}
+	proto.Unmarshal(b, &m)
+
+	assert.Equal(t, "counter1", m.Key)
+	assert.Equal(t, "core1.lax", m.SystemId)
+	assert.Equal(t, "/foos/foo", m.Prefix)
+	assert.Equal(t, int64(1610395484), m.Timestamp)
+	assert.Equal(t, map[string]string{"l1": "v1"}, m.Labels)
+	assert.Equal(t, "type.googleapis.com/google.protobuf.Int64Value", m.Value.TypeUrl)
+	assert.Equal(t, m.Value.Value, []uint8{0x8, 0x37})
+
+	// known types
+	tt := []interface{}{"foo", int(5), int32(5), int64(5), uint(5), uint32(5), uint64(5), true, []byte{0x8}}
+
+	for _, v := range tt {
+		ds["value"] = v
+		_, err = Marshal(ds)
+		assert.NoError(t, err)
+	}
+
+	// unknown type
+	ds["value"] = make(chan int)
+	_, err = Marshal(ds)
+	assert.Error(t, err)
+}
@@ -0,0 +1,122 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package panoptes
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+// Marshal returns the protobuf encoding of datastore (schema version 2).
+func Marshal(v telemetry.DataStore) ([]byte, error) {
+	value, err := GetValue(v["value"])
+	if err != nil {
+		return nil, err
+	}
+
+	pbMsg := Panoptes{
+		Timestamp: getInt64(v["timestamp"]),
+		Value:     value,
+	}
+
+	if pbMsg.Prefix, err = getString(v, "prefix"); err != nil {
+		return nil, err
+	}
+
+	if pbMsg.SystemId, err = getString(v, "system_id"); err != nil {
+		return nil, err
+	}
+
+	if pbMsg.Key, err = getString(v, "key"); err != nil {
+		return nil, err
+	}
+
+	labels, ok := v["labels"].(map[string]string)
+	if !ok {
+		return nil, fmt.Errorf("invalid labels type %T", v["labels"])
+	}
+	pbMsg.Labels = labels
+
+	return proto.Marshal(&pbMsg)
+}
+
+// GetValue returns typed value.
+func GetValue(value interface{}) (*Value, error) {
+	switch v := value.(type) {
+	case int:
+		return &Value{Value: &Value_IntVal{IntVal: int64(v)}}, nil
+	case int8:
+		return &Value{Value: &Value_IntVal{IntVal: int64(v)}}, nil
+	case int16:
+		return &Value{Value: &Value_IntVal{IntVal: int64(v)}}, nil
+	case int32:
+		return &Value{Value: &Value_IntVal{IntVal: int64(v)}}, nil
+	case int64:
+		return &Value{Value: &Value_IntVal{IntVal: v}}, nil
+	case uint:
+		return &Value{Value: &Value_UintVal{UintVal: uint64(v)}}, nil
+	case uint8:
+		return &Value{Value: &Value_UintVal{UintVal: uint64(v)}}, nil
+	case uint16:
+		return &Value{Value: &Value_UintVal{UintVal: uint64(v)}}, nil
+	case uint32:
+		return &Value{Value: &Value_UintVal{UintVal: uint64(v)}}, nil
+	case uint64:
+		return &Value{Value: &Value_UintVal{UintVal: v}}, nil
+	case float32:
+		return &Value{Value: &Value_DoubleVal{DoubleVal: float64(v)}}, nil
+	case float64:
+		return &Value{Value: &Value_DoubleVal{DoubleVal: v}}, nil
+	case bool:
+		return &Value{Value: &Value_BoolVal{BoolVal: v}}, nil
+	case string:
+		return &Value{Value: &Value_StringVal{StringVal: v}}, nil
+	case []byte:
+		return &Value{Value: &Value_BytesVal{BytesVal: v}}, nil
+	case map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return &Value{Value: &Value_JsonVal{JsonVal: b}}, nil
+	case []interface{}:
+		leafList := &LeafList{}
+		for _, e := range v {
+			ev, err := GetValue(e)
+			if err != nil {
+				return nil, fmt.Errorf("leaflist error: %v", err)
+			}
+			leafList.Element = append(leafList.Element, ev)
+		}
+		return &Value{Value: &Value_LeaflistVal{LeaflistVal: leafList}}, nil
+	}
+
+	return nil, fmt.Errorf("unknown type %T", value)
+}
+
+func getString(v telemetry.DataStore, key string) (string, error) {
+	s, ok := v[key].(string)
+	if !ok {
+		return "", fmt.Errorf("invalid %s type %T", key, v[key])
+	}
+
+	return s, nil
+}
+
+func getInt64(v interface{}) int64 {
+	switch t := v.(type) {
+	case int64:
+		return t
+	case uint64:
+		return int64(t)
+	case int:
+		return int64(t)
+	}
+
+	return 0
+}
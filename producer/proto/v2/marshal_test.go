@@ -0,0 +1,87 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package panoptes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+func TestMarshal(t *testing.T) {
+	ds := telemetry.DataStore{
+		"prefix":    "/foos/foo",
+		"labels":    map[string]string{"l1": "v1"},
+		"timestamp": uint64(1610395484),
+		"system_id": "core1.lax",
+		"key":       "counter1",
+		"value":     int(55),
+	}
+
+	b, err := Marshal(ds)
+	require.NoError(t, err)
+
+	m := Panoptes{}
+	require.NoError(t, proto.Unmarshal(b, &m))
+
+	assert.Equal(t, "counter1", m.Key)
+	assert.Equal(t, "core1.lax", m.SystemId)
+	assert.Equal(t, "/foos/foo", m.Prefix)
+	assert.Equal(t, int64(1610395484), m.Timestamp)
+	assert.Equal(t, map[string]string{"l1": "v1"}, m.Labels)
+	assert.Equal(t, int64(55), m.Value.GetIntVal())
+
+	// invalid meta data
+	for _, key := range []string{"prefix", "labels", "system_id", "key"} {
+		v := ds[key]
+		ds[key] = nil
+		_, err = Marshal(ds)
+		assert.Error(t, err, key)
+		ds[key] = v
+	}
+
+	// unknown type
+	ds["value"] = make(chan int)
+	_, err = Marshal(ds)
+	assert.Error(t, err)
+}
+
+func TestGetValue(t *testing.T) {
+	v, err := GetValue(uint64(5))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), v.GetUintVal())
+
+	v, err = GetValue(1.5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, v.GetDoubleVal())
+
+	v, err = GetValue(true)
+	assert.NoError(t, err)
+	assert.True(t, v.GetBoolVal())
+
+	v, err = GetValue("up")
+	assert.NoError(t, err)
+	assert.Equal(t, "up", v.GetStringVal())
+
+	v, err = GetValue([]byte{0x8})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x8}, v.GetBytesVal())
+
+	v, err = GetValue(map[string]interface{}{"name": "foo"})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"name":"foo"}`), v.GetJsonVal())
+
+	v, err = GetValue([]interface{}{int64(1), "foo"})
+	assert.NoError(t, err)
+	assert.Len(t, v.GetLeaflistVal().Element, 2)
+	assert.Equal(t, int64(1), v.GetLeaflistVal().Element[0].GetIntVal())
+	assert.Equal(t, "foo", v.GetLeaflistVal().Element[1].GetStringVal())
+
+	_, err = GetValue([]interface{}{make(chan int)})
+	assert.Error(t, err)
+}
@@ -0,0 +1,479 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.23.0
+// 	protoc        v3.12.4
+// source: v2/panoptes.proto
+
+package panoptes
+
+import (
+	proto "github.com/golang/protobuf/proto"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type Panoptes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SystemId  string            `protobuf:"bytes,1,opt,name=system_id,json=systemId,proto3" json:"system_id,omitempty"`
+	Prefix    string            `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Labels    map[string]string `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Timestamp int64             `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Key       string            `protobuf:"bytes,5,opt,name=key,proto3" json:"key,omitempty"`
+	Value     *Value            `protobuf:"bytes,6,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *Panoptes) Reset() {
+	*x = Panoptes{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_panoptes_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Panoptes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Panoptes) ProtoMessage() {}
+
+func (x *Panoptes) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_panoptes_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Panoptes.ProtoReflect.Descriptor instead.
+func (*Panoptes) Descriptor() ([]byte, []int) {
+	return file_v2_panoptes_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Panoptes) GetSystemId() string {
+	if x != nil {
+		return x.SystemId
+	}
+	return ""
+}
+
+func (x *Panoptes) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *Panoptes) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Panoptes) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Panoptes) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Panoptes) GetValue() *Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type Value struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Value:
+	//	*Value_IntVal
+	//	*Value_UintVal
+	//	*Value_DoubleVal
+	//	*Value_BoolVal
+	//	*Value_StringVal
+	//	*Value_BytesVal
+	//	*Value_JsonVal
+	//	*Value_LeaflistVal
+	Value isValue_Value `protobuf_oneof:"value"`
+}
+
+func (x *Value) Reset() {
+	*x = Value{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_panoptes_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Value) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Value) ProtoMessage() {}
+
+func (x *Value) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_panoptes_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Value.ProtoReflect.Descriptor instead.
+func (*Value) Descriptor() ([]byte, []int) {
+	return file_v2_panoptes_proto_rawDescGZIP(), []int{1}
+}
+
+func (m *Value) GetValue() isValue_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (x *Value) GetIntVal() int64 {
+	if x, ok := x.GetValue().(*Value_IntVal); ok {
+		return x.IntVal
+	}
+	return 0
+}
+
+func (x *Value) GetUintVal() uint64 {
+	if x, ok := x.GetValue().(*Value_UintVal); ok {
+		return x.UintVal
+	}
+	return 0
+}
+
+func (x *Value) GetDoubleVal() float64 {
+	if x, ok := x.GetValue().(*Value_DoubleVal); ok {
+		return x.DoubleVal
+	}
+	return 0
+}
+
+func (x *Value) GetBoolVal() bool {
+	if x, ok := x.GetValue().(*Value_BoolVal); ok {
+		return x.BoolVal
+	}
+	return false
+}
+
+func (x *Value) GetStringVal() string {
+	if x, ok := x.GetValue().(*Value_StringVal); ok {
+		return x.StringVal
+	}
+	return ""
+}
+
+func (x *Value) GetBytesVal() []byte {
+	if x, ok := x.GetValue().(*Value_BytesVal); ok {
+		return x.BytesVal
+	}
+	return nil
+}
+
+func (x *Value) GetJsonVal() []byte {
+	if x, ok := x.GetValue().(*Value_JsonVal); ok {
+		return x.JsonVal
+	}
+	return nil
+}
+
+func (x *Value) GetLeaflistVal() *LeafList {
+	if x, ok := x.GetValue().(*Value_LeaflistVal); ok {
+		return x.LeaflistVal
+	}
+	return nil
+}
+
+type isValue_Value interface {
+	isValue_Value()
+}
+
+type Value_IntVal struct {
+	IntVal int64 `protobuf:"varint,1,opt,name=int_val,json=intVal,proto3,oneof"`
+}
+
+type Value_UintVal struct {
+	UintVal uint64 `protobuf:"varint,2,opt,name=uint_val,json=uintVal,proto3,oneof"`
+}
+
+type Value_DoubleVal struct {
+	DoubleVal float64 `protobuf:"fixed64,3,opt,name=double_val,json=doubleVal,proto3,oneof"`
+}
+
+type Value_BoolVal struct {
+	BoolVal bool `protobuf:"varint,4,opt,name=bool_val,json=boolVal,proto3,oneof"`
+}
+
+type Value_StringVal struct {
+	StringVal string `protobuf:"bytes,5,opt,name=string_val,json=stringVal,proto3,oneof"`
+}
+
+type Value_BytesVal struct {
+	BytesVal []byte `protobuf:"bytes,6,opt,name=bytes_val,json=bytesVal,proto3,oneof"`
+}
+
+type Value_JsonVal struct {
+	JsonVal []byte `protobuf:"bytes,7,opt,name=json_val,json=jsonVal,proto3,oneof"`
+}
+
+type Value_LeaflistVal struct {
+	LeaflistVal *LeafList `protobuf:"bytes,8,opt,name=leaflist_val,json=leaflistVal,proto3,oneof"`
+}
+
+func (*Value_IntVal) isValue_Value() {}
+
+func (*Value_UintVal) isValue_Value() {}
+
+func (*Value_DoubleVal) isValue_Value() {}
+
+func (*Value_BoolVal) isValue_Value() {}
+
+func (*Value_StringVal) isValue_Value() {}
+
+func (*Value_BytesVal) isValue_Value() {}
+
+func (*Value_JsonVal) isValue_Value() {}
+
+func (*Value_LeaflistVal) isValue_Value() {}
+
+type LeafList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Element []*Value `protobuf:"bytes,1,rep,name=element,proto3" json:"element,omitempty"`
+}
+
+func (x *LeafList) Reset() {
+	*x = LeafList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_panoptes_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LeafList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeafList) ProtoMessage() {}
+
+func (x *LeafList) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_panoptes_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeafList.ProtoReflect.Descriptor instead.
+func (*LeafList) Descriptor() ([]byte, []int) {
+	return file_v2_panoptes_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LeafList) GetElement() []*Value {
+	if x != nil {
+		return x.Element
+	}
+	return nil
+}
+
+var File_v2_panoptes_proto protoreflect.FileDescriptor
+
+var file_v2_panoptes_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x76, 0x32, 0x2f, 0x70, 0x61, 0x6e, 0x6f, 0x70, 0x74, 0x65, 0x73, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x70, 0x61, 0x6e, 0x6f, 0x70, 0x74, 0x65, 0x73, 0x2e, 0x76, 0x32,
+	0x22, 0x8f, 0x02, 0x0a, 0x08, 0x70, 0x61, 0x6e, 0x6f, 0x70, 0x74, 0x65, 0x73, 0x12, 0x1b, 0x0a,
+	0x09, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72,
+	0x65, 0x66, 0x69, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66,
+	0x69, 0x78, 0x12, 0x39, 0x0a, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x21, 0x2e, 0x70, 0x61, 0x6e, 0x6f, 0x70, 0x74, 0x65, 0x73, 0x2e, 0x76, 0x32,
+	0x2e, 0x70, 0x61, 0x6e, 0x6f, 0x70, 0x74, 0x65, 0x73, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x1c, 0x0a,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x28, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x70,
+	0x61, 0x6e, 0x6f, 0x70, 0x74, 0x65, 0x73, 0x2e, 0x76, 0x32, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x1a, 0x39, 0x0a, 0x0b, 0x4c, 0x61, 0x62, 0x65, 0x6c,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x22, 0x9f, 0x02, 0x0a, 0x05, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x19, 0x0a, 0x07,
+	0x69, 0x6e, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52,
+	0x06, 0x69, 0x6e, 0x74, 0x56, 0x61, 0x6c, 0x12, 0x1b, 0x0a, 0x08, 0x75, 0x69, 0x6e, 0x74, 0x5f,
+	0x76, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x48, 0x00, 0x52, 0x07, 0x75, 0x69, 0x6e,
+	0x74, 0x56, 0x61, 0x6c, 0x12, 0x1f, 0x0a, 0x0a, 0x64, 0x6f, 0x75, 0x62, 0x6c, 0x65, 0x5f, 0x76,
+	0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x09, 0x64, 0x6f, 0x75, 0x62,
+	0x6c, 0x65, 0x56, 0x61, 0x6c, 0x12, 0x1b, 0x0a, 0x08, 0x62, 0x6f, 0x6f, 0x6c, 0x5f, 0x76, 0x61,
+	0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x07, 0x62, 0x6f, 0x6f, 0x6c, 0x56,
+	0x61, 0x6c, 0x12, 0x1f, 0x0a, 0x0a, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x76, 0x61, 0x6c,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x09, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67,
+	0x56, 0x61, 0x6c, 0x12, 0x1d, 0x0a, 0x09, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x76, 0x61, 0x6c,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x08, 0x62, 0x79, 0x74, 0x65, 0x73, 0x56,
+	0x61, 0x6c, 0x12, 0x1b, 0x0a, 0x08, 0x6a, 0x73, 0x6f, 0x6e, 0x5f, 0x76, 0x61, 0x6c, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x07, 0x6a, 0x73, 0x6f, 0x6e, 0x56, 0x61, 0x6c, 0x12,
+	0x3a, 0x0a, 0x0c, 0x6c, 0x65, 0x61, 0x66, 0x6c, 0x69, 0x73, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x70, 0x61, 0x6e, 0x6f, 0x70, 0x74, 0x65, 0x73,
+	0x2e, 0x76, 0x32, 0x2e, 0x4c, 0x65, 0x61, 0x66, 0x4c, 0x69, 0x73, 0x74, 0x48, 0x00, 0x52, 0x0b,
+	0x6c, 0x65, 0x61, 0x66, 0x6c, 0x69, 0x73, 0x74, 0x56, 0x61, 0x6c, 0x42, 0x07, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x22, 0x38, 0x0a, 0x08, 0x4c, 0x65, 0x61, 0x66, 0x4c, 0x69, 0x73, 0x74,
+	0x12, 0x2c, 0x0a, 0x07, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x12, 0x2e, 0x70, 0x61, 0x6e, 0x6f, 0x70, 0x74, 0x65, 0x73, 0x2e, 0x76, 0x32, 0x2e,
+	0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x07, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x42, 0x3d,
+	0x5a, 0x3b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x79, 0x61, 0x68,
+	0x6f, 0x6f, 0x2f, 0x70, 0x61, 0x6e, 0x6f, 0x70, 0x74, 0x65, 0x73, 0x2d, 0x73, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x2f, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2f, 0x76, 0x32, 0x3b, 0x70, 0x61, 0x6e, 0x6f, 0x70, 0x74, 0x65, 0x73, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_v2_panoptes_proto_rawDescOnce sync.Once
+	file_v2_panoptes_proto_rawDescData = file_v2_panoptes_proto_rawDesc
+)
+
+func file_v2_panoptes_proto_rawDescGZIP() []byte {
+	file_v2_panoptes_proto_rawDescOnce.Do(func() {
+		file_v2_panoptes_proto_rawDescData = protoimpl.X.CompressGZIP(file_v2_panoptes_proto_rawDescData)
+	})
+	return file_v2_panoptes_proto_rawDescData
+}
+
+var file_v2_panoptes_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_v2_panoptes_proto_goTypes = []interface{}{
+	(*Panoptes)(nil), // 0: panoptes.v2.panoptes
+	(*Value)(nil),    // 1: panoptes.v2.Value
+	(*LeafList)(nil), // 2: panoptes.v2.LeafList
+	nil,              // 3: panoptes.v2.panoptes.LabelsEntry
+}
+var file_v2_panoptes_proto_depIdxs = []int32{
+	3, // 0: panoptes.v2.panoptes.labels:type_name -> panoptes.v2.panoptes.LabelsEntry
+	1, // 1: panoptes.v2.panoptes.value:type_name -> panoptes.v2.Value
+	2, // 2: panoptes.v2.Value.leaflist_val:type_name -> panoptes.v2.LeafList
+	1, // 3: panoptes.v2.LeafList.element:type_name -> panoptes.v2.Value
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_v2_panoptes_proto_init() }
+func file_v2_panoptes_proto_init() {
+	if File_v2_panoptes_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_v2_panoptes_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Panoptes); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_panoptes_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Value); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_panoptes_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LeafList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_v2_panoptes_proto_msgTypes[1].OneofWrappers = []interface{}{
+		(*Value_IntVal)(nil),
+		(*Value_UintVal)(nil),
+		(*Value_DoubleVal)(nil),
+		(*Value_BoolVal)(nil),
+		(*Value_StringVal)(nil),
+		(*Value_BytesVal)(nil),
+		(*Value_JsonVal)(nil),
+		(*Value_LeaflistVal)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_v2_panoptes_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_v2_panoptes_proto_goTypes,
+		DependencyIndexes: file_v2_panoptes_proto_depIdxs,
+		MessageInfos:      file_v2_panoptes_proto_msgTypes,
+	}.Build()
+	File_v2_panoptes_proto = out.File
+	file_v2_panoptes_proto_rawDesc = nil
+	file_v2_panoptes_proto_goTypes = nil
+	file_v2_panoptes_proto_depIdxs = nil
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/yahoo/panoptes-stream/telemetry"
 	"github.com/yahoo/panoptes-stream/telemetry/arista"
 	"github.com/yahoo/panoptes-stream/telemetry/cisco"
+	"github.com/yahoo/panoptes-stream/telemetry/dialout"
+	"github.com/yahoo/panoptes-stream/telemetry/dialout/gnmi"
 	"github.com/yahoo/panoptes-stream/telemetry/juniper"
 )
 
@@ -22,6 +24,13 @@ func Telemetry(telemetryRegistrar *telemetry.Registrar) {
 	arista.Register(telemetryRegistrar)
 }
 
+// Dialout registers all available dial-out services
+func Dialout(dialoutRegistrar *dialout.Registrar) {
+	cisco.RegisterDialout(dialoutRegistrar)
+	juniper.RegisterDialout(dialoutRegistrar)
+	gnmi.Register(dialoutRegistrar)
+}
+
 // Producer registers all available producers
 func Producer(producerRegistrar *producer.Registrar) {
 	mqueue.Register(producerRegistrar)
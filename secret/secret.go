@@ -74,6 +74,31 @@ func GetCredentials(sType, path string) (map[string]string, error) {
 	return result, nil
 }
 
+// GetUsernamePassword returns username and password. the username can be
+// a remote secret (e.g. __vault::path) that it has username and password
+// keys or a single username as key and password as value.
+func GetUsernamePassword(username, password string) (string, string, error) {
+	sType, path, ok := ParseRemoteSecretInfo(username)
+	if !ok {
+		return username, password, nil
+	}
+
+	secrets, err := GetCredentials(sType, path)
+	if err != nil {
+		return "", "", err
+	}
+
+	if u, ok := secrets["username"]; ok {
+		return u, secrets["password"], nil
+	}
+
+	for u, p := range secrets {
+		return u, p, nil
+	}
+
+	return "", "", errors.New("credentials are not available at remote host")
+}
+
 // ParseRemoteSecretInfo returns secret type and path.
 func ParseRemoteSecretInfo(key string) (string, string, bool) {
 	re := regexp.MustCompile(`__([a-zA-Z0-9]*)::(.*)`)
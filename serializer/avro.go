@@ -0,0 +1,349 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package serializer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/secret"
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+// avroSchema represents panoptes record.
+const avroSchema = `{
+  "type": "record",
+  "name": "panoptes",
+  "namespace": "panoptes",
+  "fields": [
+    {"name": "system_id", "type": "string"},
+    {"name": "prefix", "type": "string"},
+    {"name": "labels", "type": {"type": "map", "values": "string"}},
+    {"name": "timestamp", "type": "long"},
+    {"name": "key", "type": "string"},
+    {"name": "value", "type": ["null", "long", "double", "boolean", "string", "bytes"]}
+  ]
+}`
+
+// avroMagicByte is the first byte of the Confluent wire format.
+const avroMagicByte = 0x0
+
+// the failed schema registry requests are retried with exponential backoff.
+var (
+	registryMinBackoff = time.Second
+	registryMaxBackoff = time.Minute
+)
+
+// SchemaRegistryConfig represents Confluent compatible schema registry configuration.
+type SchemaRegistryConfig struct {
+	URL        string
+	Username   string
+	Password   string
+	Timeout    int
+	LookupOnly bool
+
+	TLSConfig config.TLSConfig
+}
+
+// schemaRegistry represents Confluent compatible schema registry client.
+type schemaRegistry struct {
+	url        string
+	username   string
+	password   string
+	lookupOnly bool
+	client     *http.Client
+	ids        map[string]uint32
+	failures   map[string]*registryFailure
+
+	sync.RWMutex
+}
+
+// registryFailure represents the last failed request of a subject,
+// the data points fail with its error until the retry time.
+type registryFailure struct {
+	err     error
+	retry   time.Time
+	backoff time.Duration
+}
+
+// avroSerializer encodes datastore to Avro in Confluent wire format.
+type avroSerializer struct {
+	codec    *goavro.Codec
+	registry *schemaRegistry
+	schema   []byte
+}
+
+func newAvro(cfg Config) (Serializer, error) {
+	return newAvroSerializer(cfg.SchemaRegistry)
+}
+
+func newAvroSerializer(conf SchemaRegistryConfig) (*avroSerializer, error) {
+	codec, err := goavro.NewCodec(avroSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	registry, err := newSchemaRegistry(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := json.Marshal(map[string]string{"schema": codec.CanonicalSchema()})
+	if err != nil {
+		return nil, err
+	}
+
+	return &avroSerializer{
+		codec:    codec,
+		registry: registry,
+		schema:   schema,
+	}, nil
+}
+
+func newSchemaRegistry(conf SchemaRegistryConfig) (*schemaRegistry, error) {
+	if conf.URL == "" {
+		return nil, fmt.Errorf("schema registry url is empty")
+	}
+
+	config.SetDefault(&conf.Timeout, 5)
+
+	client := &http.Client{
+		Timeout: time.Duration(conf.Timeout) * time.Second,
+	}
+
+	if conf.TLSConfig.Enabled {
+		tlsConfig, err := secret.GetTLSConfig(&conf.TLSConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	}
+
+	username, password, err := secret.GetUsernamePassword(conf.Username, conf.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemaRegistry{
+		url:        strings.TrimSuffix(conf.URL, "/"),
+		username:   username,
+		password:   password,
+		lookupOnly: conf.LookupOnly,
+		client:     client,
+		ids:        make(map[string]uint32),
+		failures:   make(map[string]*registryFailure),
+	}, nil
+}
+
+// Marshal returns the Avro binary of datastore that prefixed by
+// magic byte and schema ID. the subject name strategy is topic name.
+func (a *avroSerializer) Marshal(topic string, v telemetry.DataStore) ([]byte, error) {
+	native, err := avroNative(v)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := a.registry.getID(topic+"-value", a.schema)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 5, 64)
+	b[0] = avroMagicByte
+	binary.BigEndian.PutUint32(b[1:], id)
+
+	return a.codec.BinaryFromNative(b, native)
+}
+
+// getID returns the schema ID of the subject from the cache
+// or registers/looks up the schema at schema registry. the failed
+// request isn't retried until its backoff is passed.
+func (s *schemaRegistry) getID(subject string, schema []byte) (uint32, error) {
+	s.RLock()
+	id, ok := s.ids[subject]
+	s.RUnlock()
+
+	if ok {
+		return id, nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if id, ok := s.ids[subject]; ok {
+		return id, nil
+	}
+
+	failure, ok := s.failures[subject]
+	if ok && time.Now().Before(failure.retry) {
+		return 0, failure.err
+	}
+
+	url := fmt.Sprintf("%s/subjects/%s/versions", s.url, subject)
+	if s.lookupOnly {
+		url = fmt.Sprintf("%s/subjects/%s", s.url, subject)
+	}
+
+	id, err := s.request(url, schema)
+	if err != nil {
+		backoff := registryMinBackoff
+		if ok {
+			backoff = failure.backoff * 2
+			if backoff > registryMaxBackoff {
+				backoff = registryMaxBackoff
+			}
+		}
+
+		s.failures[subject] = &registryFailure{err: err, retry: time.Now().Add(backoff), backoff: backoff}
+
+		return 0, err
+	}
+
+	s.ids[subject] = id
+	delete(s.failures, subject)
+
+	return id, nil
+}
+
+func (s *schemaRegistry) request(url string, schema []byte) (uint32, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(schema))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	if s.username != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("schema registry: %s %s", resp.Status, body)
+	}
+
+	r := struct {
+		ID uint32 `json:"id"`
+	}{}
+
+	if err := json.Unmarshal(body, &r); err != nil {
+		return 0, err
+	}
+
+	return r.ID, nil
+}
+
+func avroNative(v telemetry.DataStore) (map[string]interface{}, error) {
+	var (
+		labels    = make(map[string]interface{})
+		timestamp int64
+	)
+
+	l, ok := v["labels"].(map[string]string)
+	if !ok {
+		return nil, fmt.Errorf("invalid labels type %T", v["labels"])
+	}
+
+	for k, v := range l {
+		labels[k] = v
+	}
+
+	switch t := v["timestamp"].(type) {
+	case int64:
+		timestamp = t
+	case uint64:
+		timestamp = int64(t)
+	case int:
+		timestamp = int64(t)
+	}
+
+	value, err := avroValue(v["value"])
+	if err != nil {
+		return nil, err
+	}
+
+	native := map[string]interface{}{
+		"labels":    labels,
+		"timestamp": timestamp,
+		"value":     value,
+	}
+
+	for _, name := range []string{"system_id", "prefix", "key"} {
+		s, ok := v[name].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s type %T", name, v[name])
+		}
+		native[name] = s
+	}
+
+	return native, nil
+}
+
+func avroValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case int:
+		return goavro.Union("long", int64(v)), nil
+	case int32:
+		return goavro.Union("long", int64(v)), nil
+	case int64:
+		return goavro.Union("long", v), nil
+	case uint:
+		return avroUint(uint64(v)), nil
+	case uint32:
+		return goavro.Union("long", int64(v)), nil
+	case uint64:
+		return avroUint(v), nil
+	case float32:
+		return goavro.Union("double", float64(v)), nil
+	case float64:
+		return goavro.Union("double", v), nil
+	case bool:
+		return goavro.Union("boolean", v), nil
+	case string:
+		return goavro.Union("string", v), nil
+	case []byte:
+		return goavro.Union("bytes", v), nil
+	case []interface{}, map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return goavro.Union("string", string(b)), nil
+	}
+
+	return nil, fmt.Errorf("unknown type %T", value)
+}
+
+// avroUint returns the unsigned integer as long, the values greater
+// than max int64 don't fit in a long and they're encoded as double.
+func avroUint(v uint64) interface{} {
+	if v > math.MaxInt64 {
+		return goavro.Union("double", float64(v))
+	}
+
+	return goavro.Union("long", int64(v))
+}
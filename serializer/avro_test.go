@@ -0,0 +1,182 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package serializer
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+func TestAvroMarshal(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		assert.Equal(t, "/subjects/topic1-value/versions", r.URL.Path)
+		assert.Equal(t, "application/vnd.schemaregistry.v1+json", r.Header.Get("Content-Type"))
+
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "panoptes", username)
+		assert.Equal(t, "secret", password)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		req := map[string]string{}
+		json.Unmarshal(body, &req)
+		_, err := goavro.NewCodec(req["schema"])
+		assert.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+		w.Write([]byte(`{"id":7}`))
+	}))
+	defer server.Close()
+
+	encoder, err := newAvroSerializer(SchemaRegistryConfig{
+		URL:      server.URL,
+		Username: "panoptes",
+		Password: "secret",
+	})
+	require.NoError(t, err)
+
+	ds := telemetry.DataStore{
+		"prefix":    "/interfaces/interface/state/counters",
+		"labels":    map[string]string{"name": "et-0/0/0"},
+		"timestamp": int64(1610395484),
+		"system_id": "core1.lax",
+		"key":       "in-octets",
+		"value":     uint64(55),
+	}
+
+	b, err := encoder.Marshal("topic1", ds)
+	require.NoError(t, err)
+
+	// wire format
+	assert.Equal(t, byte(0x0), b[0])
+	assert.Equal(t, uint32(7), binary.BigEndian.Uint32(b[1:5]))
+
+	native, _, err := encoder.codec.NativeFromBinary(b[5:])
+	require.NoError(t, err)
+
+	record := native.(map[string]interface{})
+	assert.Equal(t, "core1.lax", record["system_id"])
+	assert.Equal(t, "in-octets", record["key"])
+	assert.Equal(t, int64(1610395484), record["timestamp"])
+	assert.Equal(t, map[string]interface{}{"name": "et-0/0/0"}, record["labels"])
+	assert.Equal(t, map[string]interface{}{"long": int64(55)}, record["value"])
+
+	// cached schema ID
+	for _, v := range []interface{}{"foo", 5.5, true, []byte{0x8}, []interface{}{"a", "b"}, nil} {
+		ds["value"] = v
+		_, err = encoder.Marshal("topic1", ds)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// greater than max int64
+	ds["value"] = uint64(math.MaxUint64)
+	b, err = encoder.Marshal("topic1", ds)
+	require.NoError(t, err)
+	native, _, err = encoder.codec.NativeFromBinary(b[5:])
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"double": float64(math.MaxUint64)}, native.(map[string]interface{})["value"])
+
+	// unknown type
+	ds["value"] = make(chan int)
+	_, err = encoder.Marshal("topic1", ds)
+	assert.Error(t, err)
+
+	// invalid meta data
+	ds["value"] = 5
+	for _, key := range []string{"prefix", "labels", "system_id", "key"} {
+		v := ds[key]
+		ds[key] = nil
+		_, err = encoder.Marshal("topic1", ds)
+		assert.Error(t, err, key)
+		ds[key] = v
+	}
+}
+
+func TestAvroSchemaRegistryBackoff(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Write([]byte(`{"id":9}`))
+	}))
+	defer server.Close()
+
+	encoder, err := newAvroSerializer(SchemaRegistryConfig{URL: server.URL})
+	require.NoError(t, err)
+
+	registry := encoder.registry
+
+	// the failed subject isn't requested until the backoff passed
+	for i := 0; i < 10; i++ {
+		_, err = registry.getID("topic1-value", encoder.schema)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.Equal(t, registryMinBackoff, registry.failures["topic1-value"].backoff)
+
+	registry.failures["topic1-value"].retry = time.Now()
+	_, err = registry.getID("topic1-value", encoder.schema)
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Equal(t, 2*registryMinBackoff, registry.failures["topic1-value"].backoff)
+
+	registry.failures["topic1-value"].retry = time.Now()
+	id, err := registry.getID("topic1-value", encoder.schema)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(9), id)
+	assert.Len(t, registry.failures, 0)
+}
+
+func TestAvroSchemaRegistryLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/subjects/topic1-value" {
+			w.Write([]byte(`{"subject":"topic1-value","id":3,"version":1}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error_code":40401,"message":"Subject not found"}`))
+	}))
+	defer server.Close()
+
+	encoder, err := newAvroSerializer(SchemaRegistryConfig{
+		URL:        server.URL + "/",
+		LookupOnly: true,
+	})
+	require.NoError(t, err)
+
+	id, err := encoder.registry.getID("topic1-value", encoder.schema)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(3), id)
+
+	_, err = encoder.registry.getID("topic2-value", encoder.schema)
+	assert.Error(t, err)
+
+	// url not configured
+	_, err = newAvroSerializer(SchemaRegistryConfig{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,41 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package serializer
+
+import (
+	"github.com/ugorji/go/codec"
+
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+// codecSerializer encodes datastore by ugorji codec handles.
+type codecSerializer struct {
+	handle codec.Handle
+	buf    []byte
+}
+
+func newMsgpack(Config) (Serializer, error) {
+	h := &codec.MsgpackHandle{}
+	h.WriteExt = true
+
+	return &codecSerializer{handle: h}, nil
+}
+
+func newCBOR(Config) (Serializer, error) {
+	return &codecSerializer{handle: &codec.CborHandle{}}, nil
+}
+
+// Marshal returns the msgpack or CBOR encoding of datastore.
+func (c *codecSerializer) Marshal(_ string, ds telemetry.DataStore) ([]byte, error) {
+	c.buf = c.buf[:0]
+	err := codec.NewEncoderBytes(&c.buf, c.handle).Encode(map[string]interface{}(ds))
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, len(c.buf))
+	copy(b, c.buf)
+
+	return b, nil
+}
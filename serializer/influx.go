@@ -0,0 +1,363 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package serializer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+var (
+	errEmptyMeasurement = errors.New("line protocol: empty measurement")
+	errCommentLine      = errors.New("line protocol: measurement starts with #")
+	errNoFields         = errors.New("line protocol: no valid field")
+
+	// measurement escaper
+	nameEscaper = strings.NewReplacer(
+		"\t", `\t`,
+		"\n", `\n`,
+		"\f", `\f`,
+		"\r", `\r`,
+		`,`, `\,`,
+		` `, `\ `,
+	)
+
+	// tag key, tag value and field key escaper
+	keyEscaper = strings.NewReplacer(
+		"\t", `\t`,
+		"\n", `\n`,
+		"\f", `\f`,
+		"\r", `\r`,
+		`,`, `\,`,
+		` `, `\ `,
+		`=`, `\=`,
+	)
+
+	// string field value escaper
+	stringEscaper = strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+	)
+)
+
+type influxSerializer struct {
+	buf *bytes.Buffer
+}
+
+func newInflux(Config) (Serializer, error) {
+	return &influxSerializer{buf: new(bytes.Buffer)}, nil
+}
+
+// Marshal returns the InfluxDB line protocol of datastore.
+// the topic is the measurement.
+func (i *influxSerializer) Marshal(topic string, ds telemetry.DataStore) ([]byte, error) {
+	i.buf.Reset()
+	if err := (LineProtocol{}).Write(i.buf, topic, ds); err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, i.buf.Len())
+	copy(b, i.buf.Bytes())
+
+	return b, nil
+}
+
+// Field represents a line protocol field.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// GetPrecision returns the line protocol timestamp precision
+// by name: ns (default), us, ms and s.
+func GetPrecision(name string) (time.Duration, error) {
+	switch name {
+	case "", "ns":
+		return time.Nanosecond, nil
+	case "us":
+		return time.Microsecond, nil
+	case "ms":
+		return time.Millisecond, nil
+	case "s":
+		return time.Second, nil
+	}
+
+	return 0, fmt.Errorf("precision %s not supported", name)
+}
+
+// LineProtocol represents InfluxDB line protocol encoder.
+type LineProtocol struct {
+	// Precision is the timestamp precision.
+	Precision time.Duration
+	// NoUint encodes unsigned integers as integers for the servers without
+	// unsigned integer support (InfluxDB 1.x), values greater than max int64 are skipped.
+	NoUint bool
+}
+
+// Write writes the InfluxDB line protocol of datastore to the buffer.
+// the buffer content is not valid if it returns error.
+func (l LineProtocol) Write(buf *bytes.Buffer, measurement string, ds telemetry.DataStore) error {
+	key, _ := ds["key"].(string)
+	return l.WriteFields(buf, measurement, ds, []Field{{Key: key, Value: ds["value"]}})
+}
+
+// WriteFields writes the InfluxDB line protocol of datastore
+// with the given fields to the buffer, the datastore key and value are ignored.
+// a field with empty key or unsupported value is skipped.
+func (l LineProtocol) WriteFields(buf *bytes.Buffer, measurement string, ds telemetry.DataStore, fields []Field) error {
+	if err := writeSeries(buf, measurement, ds); err != nil {
+		return err
+	}
+
+	var (
+		n       int
+		err     error
+		scratch = make([]byte, 0, 32)
+	)
+
+	for _, field := range fields {
+		key := escapeKey(field.Key)
+		if key == "" {
+			continue
+		}
+
+		scratch, err = appendFieldValue(scratch[:0], field.Value, l.NoUint)
+		if err != nil {
+			continue
+		}
+
+		if n == 0 {
+			buf.WriteByte(' ')
+		} else {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		buf.Write(scratch)
+		n++
+	}
+
+	if n == 0 {
+		return errNoFields
+	}
+
+	// the server assigns the timestamp if it's not available
+	if ts, ok := toInt64(ds["timestamp"]); ok {
+		precision := int64(l.Precision)
+		if precision < 1 {
+			precision = 1
+		}
+		buf.WriteByte(' ')
+		buf.Write(strconv.AppendInt(scratch[:0], ts/precision, 10))
+	}
+
+	return nil
+}
+
+// writeSeries writes the measurement and the tags sorted by key,
+// a tag with empty key or value is skipped.
+func writeSeries(buf *bytes.Buffer, measurement string, ds telemetry.DataStore) error {
+	measurement = escapeName(measurement)
+	if measurement == "" {
+		return errEmptyMeasurement
+	}
+
+	// a line starts with # is a comment
+	if measurement[0] == '#' {
+		return errCommentLine
+	}
+
+	buf.WriteString(measurement)
+
+	prefix, _ := ds["prefix"].(string)
+	systemID, _ := ds["system_id"].(string)
+	writeTag(buf, "_prefix_", prefix)
+	writeTag(buf, "_host_", systemID)
+
+	labels, _ := ds["labels"].(map[string]string)
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		writeTag(buf, k, labels[k])
+	}
+
+	return nil
+}
+
+func writeTag(buf *bytes.Buffer, key, value string) {
+	key = escapeKey(key)
+	value = escapeKey(value)
+	if key == "" || value == "" {
+		return
+	}
+
+	buf.WriteByte(',')
+	buf.WriteString(key)
+	buf.WriteByte('=')
+	buf.WriteString(value)
+}
+
+// FieldValue returns the line protocol field value,
+// integers have i suffix and unsigned integers have u suffix.
+func FieldValue(value interface{}) (string, error) {
+	b, err := appendFieldValue(nil, value, false)
+	return string(b), err
+}
+
+func appendFieldValue(b []byte, value interface{}, noUint bool) ([]byte, error) {
+	if noUint {
+		if v, ok := toUint64(value); ok {
+			if v > math.MaxInt64 {
+				return b, fmt.Errorf("line protocol: integer value %d overflows", v)
+			}
+			return append(strconv.AppendInt(b, int64(v), 10), 'i'), nil
+		}
+	}
+
+	switch v := value.(type) {
+	case int64:
+		return append(strconv.AppendInt(b, v, 10), 'i'), nil
+	case int32:
+		return append(strconv.AppendInt(b, int64(v), 10), 'i'), nil
+	case int16:
+		return append(strconv.AppendInt(b, int64(v), 10), 'i'), nil
+	case int8:
+		return append(strconv.AppendInt(b, int64(v), 10), 'i'), nil
+	case int:
+		return append(strconv.AppendInt(b, int64(v), 10), 'i'), nil
+	case uint64:
+		return append(strconv.AppendUint(b, v, 10), 'u'), nil
+	case uint32:
+		return append(strconv.AppendUint(b, uint64(v), 10), 'u'), nil
+	case uint16:
+		return append(strconv.AppendUint(b, uint64(v), 10), 'u'), nil
+	case uint8:
+		return append(strconv.AppendUint(b, uint64(v), 10), 'u'), nil
+	case uint:
+		return append(strconv.AppendUint(b, uint64(v), 10), 'u'), nil
+	case float64:
+		return appendFloat(b, v, 64)
+	case float32:
+		return appendFloat(b, float64(v), 32)
+	case bool:
+		return strconv.AppendBool(b, v), nil
+	case string:
+		return appendString(b, v), nil
+	case []byte:
+		return appendString(b, string(v)), nil
+	case []interface{}, map[string]interface{}:
+		j, err := json.Marshal(v)
+		if err != nil {
+			return b, err
+		}
+		return appendString(b, string(j)), nil
+	}
+
+	return b, fmt.Errorf("line protocol: unsupported field value type %T", value)
+}
+
+func appendFloat(b []byte, v float64, bitSize int) ([]byte, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return b, fmt.Errorf("line protocol: unsupported field value %v", v)
+	}
+
+	return strconv.AppendFloat(b, v, 'g', -1, bitSize), nil
+}
+
+func appendString(b []byte, v string) []byte {
+	b = append(b, '"')
+	b = append(b, stringEscaper.Replace(v)...)
+	return append(b, '"')
+}
+
+// escapeName escapes the measurement.
+func escapeName(s string) string {
+	return nameEscaper.Replace(sanitize(s))
+}
+
+// escapeKey escapes the tag key, tag value and field key.
+func escapeKey(s string) string {
+	return keyEscaper.Replace(sanitize(s))
+}
+
+// sanitize removes the invalid UTF-8 and the non-printable characters
+// except the escapable ones as InfluxDB rejects them, also a trailing
+// backslash is removed as it escapes the next delimiter.
+func sanitize(s string) string {
+	for _, r := range s {
+		if !isPrint(r) {
+			s = strings.Map(func(r rune) rune {
+				if isPrint(r) {
+					return r
+				}
+				return -1
+			}, s)
+			break
+		}
+	}
+
+	return strings.TrimRight(s, `\`)
+}
+
+func isPrint(r rune) bool {
+	if r == utf8.RuneError {
+		return false
+	}
+
+	switch r {
+	case '\t', '\n', '\f', '\r':
+		return true
+	}
+
+	return unicode.IsPrint(r)
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch t := v.(type) {
+	case int64:
+		return t, true
+	case int:
+		return int64(t), true
+	case uint64:
+		return int64(t), true
+	case int32:
+		return int64(t), true
+	case uint32:
+		return int64(t), true
+	}
+
+	return 0, false
+}
+
+func toUint64(v interface{}) (uint64, bool) {
+	switch t := v.(type) {
+	case uint64:
+		return t, true
+	case uint32:
+		return uint64(t), true
+	case uint16:
+		return uint64(t), true
+	case uint8:
+		return uint64(t), true
+	case uint:
+		return uint64(t), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,106 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+//go:build go1.18
+// +build go1.18
+
+package serializer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/influxdata/influxdb/models"
+
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+func init() {
+	models.EnableUintSupport()
+}
+
+// FuzzLineProtocol checks that the encoded line protocol is parsable by
+// InfluxDB as exactly one point and the values round trip when they don't
+// include backslash or control characters that are escaped lossy.
+func FuzzLineProtocol(f *testing.F) {
+	f.Add("ifcounters", "name", "Ethernet3", "in-octets", "up", int64(10), uint64(10), 1.5, true)
+	f.Add("if counters", "desc", "to core1, port=1", "in octets", `say "hi"`, int64(-1), uint64(0), 0.1, false)
+	f.Add(`if\`, `na\me`, `a\,b`, `f\`, `\"`, int64(0), uint64(1), 1e21, true)
+	f.Add("m", "k", "v", "f", "line1\nline2", int64(1), uint64(1), -1e-21, true)
+
+	f.Fuzz(func(t *testing.T, measurement, tagKey, tagValue, fieldKey, str string, i int64, u uint64, fl float64, b bool) {
+		buf := new(bytes.Buffer)
+		ds := telemetry.DataStore{
+			"prefix":    "/interfaces/",
+			"labels":    map[string]string{tagKey: tagValue},
+			"system_id": "core1",
+			"timestamp": int64(1595768623436661269),
+		}
+		fields := []Field{
+			{Key: fieldKey + "_s", Value: str},
+			{Key: fieldKey + "_i", Value: i},
+			{Key: fieldKey + "_u", Value: u},
+			{Key: fieldKey + "_f", Value: fl},
+			{Key: fieldKey + "_b", Value: b},
+		}
+
+		if err := (LineProtocol{}).WriteFields(buf, measurement, ds, fields); err != nil {
+			return
+		}
+
+		points, err := models.ParsePointsString(buf.String())
+		if err != nil {
+			t.Fatalf("parse %q: %v", buf.String(), err)
+		}
+		if len(points) != 1 {
+			t.Fatalf("parse %q: got %d points", buf.String(), len(points))
+		}
+
+		p := points[0]
+		if !models.ValidKeyTokens(string(p.Name()), p.Tags()) {
+			t.Fatalf("invalid key tokens %q", buf.String())
+		}
+
+		if !isLossless(measurement, tagKey, tagValue, fieldKey, str) {
+			return
+		}
+
+		if string(p.Name()) != measurement {
+			t.Errorf("measurement: got %q, want %q", p.Name(), measurement)
+		}
+
+		if tagKey != "" && tagValue != "" && p.Tags().GetString(tagKey) != tagValue {
+			t.Errorf("tag: got %q, want %q", p.Tags().GetString(tagKey), tagValue)
+		}
+
+		values, err := p.Fields()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, field := range fields {
+			if values[field.Key] != field.Value {
+				t.Errorf("field %q: got %#v, want %#v", field.Key, values[field.Key], field.Value)
+			}
+		}
+	})
+}
+
+func isLossless(s ...string) bool {
+	for _, v := range s {
+		if strings.ContainsAny(v, "\\\t\n\f\r") || !utf8.ValidString(v) {
+			return false
+		}
+
+		for _, r := range v {
+			if !unicode.IsPrint(r) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
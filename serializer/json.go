@@ -0,0 +1,21 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package serializer
+
+import (
+	"encoding/json"
+
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+type jsonSerializer struct{}
+
+func newJSON(Config) (Serializer, error) {
+	return jsonSerializer{}, nil
+}
+
+// Marshal returns the JSON encoding of datastore.
+func (jsonSerializer) Marshal(_ string, ds telemetry.DataStore) ([]byte, error) {
+	return json.Marshal(ds)
+}
@@ -0,0 +1,33 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package serializer
+
+import (
+	"github.com/yahoo/panoptes-stream/telemetry"
+
+	pb "github.com/yahoo/panoptes-stream/producer/proto"
+	pbv2 "github.com/yahoo/panoptes-stream/producer/proto/v2"
+)
+
+type protobufV1 struct{}
+
+type protobufV2 struct{}
+
+func newProtobufV1(Config) (Serializer, error) {
+	return protobufV1{}, nil
+}
+
+func newProtobufV2(Config) (Serializer, error) {
+	return protobufV2{}, nil
+}
+
+// Marshal returns the protobuf encoding of datastore (schema version 1).
+func (protobufV1) Marshal(_ string, ds telemetry.DataStore) ([]byte, error) {
+	return pb.Marshal(ds)
+}
+
+// Marshal returns the protobuf encoding of datastore (schema version 2).
+func (protobufV2) Marshal(_ string, ds telemetry.DataStore) ([]byte, error) {
+	return pbv2.Marshal(ds)
+}
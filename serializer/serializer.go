@@ -0,0 +1,112 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package serializer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+// Serializer represents a datastore encoder.
+// a serializer instance is not safe for concurrent use.
+type Serializer interface {
+	Marshal(topic string, ds telemetry.DataStore) ([]byte, error)
+}
+
+// Factory is a function that returns a new instance of serializer.
+type Factory func(Config) (Serializer, error)
+
+// Config represents serializer configuration.
+type Config struct {
+	SchemaRegistry SchemaRegistryConfig
+}
+
+// Registrar represents serializer factory registration.
+type Registrar struct {
+	s map[string]Factory
+	sync.RWMutex
+}
+
+var registrar = NewRegistrar()
+
+func init() {
+	registrar.Register("json", newJSON)
+	registrar.Register("protobuf-v1", newProtobufV1)
+	registrar.Register("protobuf-v2", newProtobufV2)
+	registrar.Register("influx", newInflux)
+	registrar.Register("msgpack", newMsgpack)
+	registrar.Register("cbor", newCBOR)
+	registrar.Register("avro", newAvro)
+}
+
+// NewRegistrar creates new registrar.
+func NewRegistrar() *Registrar {
+	return &Registrar{
+		s: make(map[string]Factory),
+	}
+}
+
+// Register adds new serializer factory.
+func (r *Registrar) Register(name string, sf Factory) {
+	r.Lock()
+	defer r.Unlock()
+	r.s[name] = sf
+}
+
+// GetSerializerFactory returns requested serializer factory.
+func (r *Registrar) GetSerializerFactory(name string) (Factory, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	v, ok := r.s[name]
+
+	return v, ok
+}
+
+// Names returns registered serializer names.
+func (r *Registrar) Names() []string {
+	var names []string
+
+	r.RLock()
+	defer r.RUnlock()
+
+	for name := range r.s {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Register adds new serializer factory to the default registrar.
+func Register(name string, sf Factory) {
+	registrar.Register(name, sf)
+}
+
+// Names returns available serializer names.
+func Names() []string {
+	return registrar.Names()
+}
+
+// Validate returns error if the serializer is not available.
+func Validate(name string) error {
+	if _, ok := registrar.GetSerializerFactory(name); !ok {
+		return fmt.Errorf("encoding %s not supported", name)
+	}
+
+	return nil
+}
+
+// New returns a new instance of requested serializer.
+func New(name string, cfg Config) (Serializer, error) {
+	new, ok := registrar.GetSerializerFactory(name)
+	if !ok {
+		return nil, fmt.Errorf("encoding %s not supported", name)
+	}
+
+	return new(cfg)
+}
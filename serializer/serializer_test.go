@@ -0,0 +1,213 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package serializer
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yahoo/panoptes-stream/telemetry"
+
+	pb "github.com/yahoo/panoptes-stream/producer/proto"
+	pbv2 "github.com/yahoo/panoptes-stream/producer/proto/v2"
+)
+
+var ds = telemetry.DataStore{
+	"prefix":    "/interfaces/interface/state/counters/",
+	"labels":    map[string]string{"name": "Ethernet3"},
+	"timestamp": int64(1595768623436661269),
+	"system_id": "core1.bur",
+	"key":       "out-octets",
+	"value":     int64(5587651),
+}
+
+func TestRegistrar(t *testing.T) {
+	assert.Equal(t, []string{"avro", "cbor", "influx", "json", "msgpack", "protobuf-v1", "protobuf-v2"}, Names())
+
+	for _, name := range []string{"json", "protobuf-v1", "protobuf-v2", "influx", "msgpack", "cbor"} {
+		assert.NoError(t, Validate(name))
+
+		s, err := New(name, Config{})
+		require.NoError(t, err)
+
+		b, err := s.Marshal("ifcounters", ds)
+		assert.NoError(t, err, name)
+		assert.NotZero(t, len(b), name)
+	}
+
+	assert.Error(t, Validate("xml"))
+	_, err := New("xml", Config{})
+	assert.Error(t, err)
+
+	r := NewRegistrar()
+	r.Register("xml", newJSON)
+	_, ok := r.GetSerializerFactory("xml")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"xml"}, r.Names())
+}
+
+func TestJSON(t *testing.T) {
+	s, _ := New("json", Config{})
+	b, err := s.Marshal("", ds)
+	require.NoError(t, err)
+
+	v := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(b, &v))
+	assert.Equal(t, "core1.bur", v["system_id"])
+}
+
+func TestProtobuf(t *testing.T) {
+	s, _ := New("protobuf-v1", Config{})
+	b, err := s.Marshal("", ds)
+	require.NoError(t, err)
+
+	m := pb.Panoptes{}
+	require.NoError(t, proto.Unmarshal(b, &m))
+	assert.Equal(t, "out-octets", m.Key)
+
+	s, _ = New("protobuf-v2", Config{})
+	b, err = s.Marshal("", ds)
+	require.NoError(t, err)
+
+	m2 := pbv2.Panoptes{}
+	require.NoError(t, proto.Unmarshal(b, &m2))
+	assert.Equal(t, int64(5587651), m2.Value.GetIntVal())
+}
+
+func TestCodec(t *testing.T) {
+	handles := map[string]codec.Handle{
+		"msgpack": &codec.MsgpackHandle{},
+		"cbor":    &codec.CborHandle{},
+	}
+
+	for name, h := range handles {
+		s, _ := New(name, Config{})
+		b, err := s.Marshal("", ds)
+		require.NoError(t, err)
+
+		// reuse internal buffer
+		_, err = s.Marshal("", ds)
+		require.NoError(t, err)
+
+		v := map[string]interface{}{}
+		require.NoError(t, codec.NewDecoderBytes(b, h).Decode(&v), name)
+		assert.Equal(t, "core1.bur", string(toBytes(v["system_id"])), name)
+		assert.Equal(t, "out-octets", string(toBytes(v["key"])), name)
+	}
+}
+
+func TestInflux(t *testing.T) {
+	s, _ := New("influx", Config{})
+	b, err := s.Marshal("ifcounters", ds)
+	require.NoError(t, err)
+	assert.Equal(t, "ifcounters,_prefix_=/interfaces/interface/state/counters/,_host_=core1.bur,name=Ethernet3 out-octets=5587651i 1595768623436661269", string(b))
+}
+
+func TestFieldValue(t *testing.T) {
+	testCases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{1, "1i"},
+		{int64(-1), "-1i"},
+		{uint64(18446744073709551615), "18446744073709551615u"},
+		{uint32(1), "1u"},
+		{1.5, "1.5"},
+		{float32(0.1), "0.1"},
+		{1e21, "1e+21"},
+		{true, "true"},
+		{"test", `"test"`},
+		{`say "hi" \o/`, `"say \"hi\" \\o/"`},
+		{[]byte("test"), `"test"`},
+		{map[string]interface{}{"name": "foo"}, `"{\"name\":\"foo\"}"`},
+	}
+
+	for _, tc := range testCases {
+		v, err := FieldValue(tc.value)
+		assert.NoError(t, err, tc.value)
+		assert.Equal(t, tc.expected, v, tc.value)
+	}
+
+	for _, value := range []interface{}{nil, math.NaN(), math.Inf(1), map[string]string{"a": "b"}} {
+		_, err := FieldValue(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestLineProtocolEscape(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ds := telemetry.DataStore{
+		"prefix":    "/interfaces/interface/state/",
+		"labels":    map[string]string{"name": "Ethernet3", "description": `to core1, "port=1"`, "empty": ""},
+		"system_id": "core1.bur",
+		"timestamp": int64(1595768623436661269),
+	}
+
+	fields := []Field{
+		{Key: "in octets", Value: uint64(10)},
+		{Key: "description", Value: `to core1, "port=1"`},
+		{Key: "", Value: 1},
+		{Key: "nan", Value: math.NaN()},
+	}
+
+	lp := LineProtocol{Precision: time.Millisecond}
+	err := lp.WriteFields(buf, "if,state", ds, fields)
+	require.NoError(t, err)
+	assert.Equal(t, `if\,state,_prefix_=/interfaces/interface/state/,_host_=core1.bur,description=to\ core1\,\ "port\=1",name=Ethernet3 `+
+		`in\ octets=10u,description="to core1, \"port=1\"" 1595768623436`, buf.String())
+
+	// no valid field
+	buf.Reset()
+	err = lp.WriteFields(buf, "if", ds, fields[2:])
+	assert.Error(t, err)
+
+	// empty measurement
+	buf.Reset()
+	err = lp.WriteFields(buf, "", ds, fields)
+	assert.Error(t, err)
+
+	// without timestamp and unsigned integer
+	buf.Reset()
+	delete(ds, "timestamp")
+	lp = LineProtocol{NoUint: true}
+	err = lp.WriteFields(buf, "if", ds, fields[:1])
+	require.NoError(t, err)
+	assert.Equal(t, `if,_prefix_=/interfaces/interface/state/,_host_=core1.bur,description=to\ core1\,\ "port\=1",name=Ethernet3 in\ octets=10i`, buf.String())
+
+	// unsigned integer overflow
+	buf.Reset()
+	err = lp.WriteFields(buf, "if", ds, []Field{{Key: "in", Value: uint64(math.MaxUint64)}})
+	assert.Error(t, err)
+}
+
+func TestGetPrecision(t *testing.T) {
+	for name, expected := range map[string]time.Duration{"": time.Nanosecond, "ns": time.Nanosecond,
+		"us": time.Microsecond, "ms": time.Millisecond, "s": time.Second} {
+		p, err := GetPrecision(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, p)
+	}
+
+	_, err := GetPrecision("m")
+	assert.Error(t, err)
+}
+
+func toBytes(v interface{}) []byte {
+	switch t := v.(type) {
+	case string:
+		return []byte(t)
+	case []byte:
+		return t
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package status
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yahoo/panoptes-stream/config"
+)
+
+// redacted replaces the secret values of the admin config.
+const redacted = "<redacted>"
+
+// secretKeys represents the config keys that hold secrets.
+var secretKeys = []string{"password", "token", "secret"}
+
+// DeviceState represents a subscribed device state.
+type DeviceState struct {
+	Host    string `json:"host"`
+	Port    int    `json:"port"`
+	GroupID int    `json:"groupID,omitempty"`
+	Dialout bool   `json:"dialout,omitempty"`
+	// Filters are the filter options that selected the device.
+	Filters  []string       `json:"filters,omitempty"`
+	Services []ServiceState `json:"services"`
+}
+
+// ServiceState represents a device telemetry service state.
+type ServiceState struct {
+	Service     string        `json:"service"`
+	Sensors     []SensorState `json:"sensors"`
+	Connected   bool          `json:"connected"`
+	ConnectedAt *time.Time    `json:"connectedAt,omitempty"`
+	LastData    *time.Time    `json:"lastData,omitempty"`
+	Backoff     string        `json:"backoff"`
+	Reconnects  uint64        `json:"reconnects"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// SensorState represents a subscribed sensor.
+type SensorState struct {
+	Origin         string `json:"origin,omitempty"`
+	Path           string `json:"path,omitempty"`
+	Subscription   string `json:"subscription,omitempty"`
+	Mode           string `json:"mode,omitempty"`
+	SampleInterval int    `json:"sampleInterval,omitempty"`
+	Output         string `json:"output"`
+}
+
+// ShardState represents the node shard assignment.
+type ShardState struct {
+	Enabled       bool   `json:"enabled"`
+	NodeID        string `json:"nodeID,omitempty"`
+	NumberOfNodes int    `json:"numberOfNodes,omitempty"`
+	Suspended     bool   `json:"suspended"`
+	// Filters are the active device filter options.
+	Filters []string `json:"filters"`
+}
+
+// DeviceReporter reports the subscribed devices state.
+type DeviceReporter interface {
+	DeviceStates() []DeviceState
+	FilterOpts() []string
+}
+
+// ShardReporter reports the node shard assignment.
+type ShardReporter interface {
+	ShardState() ShardState
+}
+
+// admin serves the admin API in JSON format: /admin/devices,
+// /admin/devices/{host}, /admin/shards, /admin/config and
+// /admin/loggers. The loggers levels are changeable through
+// PUT /admin/loggers/{name}?level=debug and DELETE /admin/loggers/{name}
+// by the authenticated clients.
+// The /admin/tap streams the live data points as server-sent events
+// to the authenticated clients.
+type admin struct {
+	cfg            config.Config
+	logger         *zap.Logger
+	deviceReporter DeviceReporter
+	shardReporter  ShardReporter
+	tapper         Tapper
+}
+
+func (a *admin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin"), "/")
+
+	if strings.HasPrefix(path, "loggers/") && r.Method != http.MethodGet {
+		if !authenticated(r) {
+			http.Error(w, "client certificate required", http.StatusForbidden)
+			return
+		}
+		a.setLogLevel(w, r, strings.TrimPrefix(path, "loggers/"))
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch {
+	case path == "devices":
+		writeJSON(w, a.devices())
+	case strings.HasPrefix(path, "devices/"):
+		host := strings.TrimPrefix(path, "devices/")
+		for _, d := range a.devices() {
+			if d.Host == host {
+				writeJSON(w, d)
+				return
+			}
+		}
+		http.Error(w, "device not found", http.StatusNotFound)
+	case path == "shards":
+		writeJSON(w, a.shards())
+	case path == "config":
+		writeJSON(w, a.config())
+	case path == "loggers":
+		writeJSON(w, config.LogLevels())
+	case path == "tap":
+		a.tap(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// authenticated reports whether the client presented a TLS
+// certificate that's verified by the status CA.
+func authenticated(r *http.Request) bool {
+	return r.TLS != nil && len(r.TLS.VerifiedChains) > 0
+}
+
+// setLogLevel sets or resets the logger level, the name is root,
+// a component logger name, e.g. telemetry.juniper.gnmi, or device:host.
+func (a *admin) setLogLevel(w http.ResponseWriter, r *http.Request, name string) {
+	switch r.Method {
+	case http.MethodPut:
+		if err := config.SetLogLevel(name, r.URL.Query().Get("level")); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	case http.MethodDelete:
+		if name == config.RootLogger {
+			http.Error(w, "root logger level can't be reset", http.StatusBadRequest)
+			return
+		}
+		config.ResetLogLevel(name)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	a.logger.Info("admin", zap.String("event", "logger"), zap.String("name", name), zap.String("method", r.Method))
+
+	writeJSON(w, config.LogLevels())
+}
+
+func (a *admin) devices() []DeviceState {
+	devices := []DeviceState{}
+	if a.deviceReporter != nil {
+		devices = append(devices, a.deviceReporter.DeviceStates()...)
+	}
+
+	return devices
+}
+
+func (a *admin) shards() ShardState {
+	state := ShardState{}
+	if a.shardReporter != nil {
+		state = a.shardReporter.ShardState()
+	}
+
+	state.Filters = []string{}
+	if a.deviceReporter != nil {
+		state.Filters = append(state.Filters, a.deviceReporter.FilterOpts()...)
+	}
+
+	return state
+}
+
+// config returns the loaded configuration without the secrets.
+func (a *admin) config() interface{} {
+	cfg := map[string]interface{}{
+		"global":    a.cfg.Global(),
+		"devices":   a.cfg.Devices(),
+		"sensors":   a.cfg.Sensors(),
+		"producers": a.cfg.Producers(),
+		"databases": a.cfg.Databases(),
+	}
+
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return map[string]string{"error": err.Error()}
+	}
+
+	var v interface{}
+	json.Unmarshal(b, &v)
+
+	return redact(v)
+}
+
+// redact replaces the non-empty secret values recursively.
+func redact(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for key, value := range t {
+			if s, ok := value.(string); ok && s != "" && isSecretKey(key) {
+				t[key] = redacted
+				continue
+			}
+			t[key] = redact(value)
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = redact(t[i])
+		}
+	}
+
+	return v
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range secretKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,202 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package status
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yahoo/panoptes-stream/config"
+)
+
+type deviceReporter []DeviceState
+
+func (r deviceReporter) DeviceStates() []DeviceState {
+	return r
+}
+
+func (r deviceReporter) FilterOpts() []string {
+	return []string{"mainShard"}
+}
+
+type shardReporter ShardState
+
+func (r shardReporter) ShardState() ShardState {
+	return ShardState(r)
+}
+
+// withClientCert serves the requests as they have a verified client certificate.
+func withClientCert(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func getJSON(t *testing.T, url string, v interface{}) int {
+	res, err := http.Get(url)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	if v != nil {
+		assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(v))
+	}
+
+	return res.StatusCode
+}
+
+func TestAdminDevices(t *testing.T) {
+	reporter := deviceReporter{
+		{
+			Host:    "core1.lax",
+			Port:    50051,
+			Filters: []string{"mainShard"},
+			Services: []ServiceState{
+				{
+					Service:   "juniper.gnmi",
+					Sensors:   []SensorState{{Path: "/interfaces/interface/state/counters", Mode: "sample", Output: "console::stdout"}},
+					Connected: true,
+					Backoff:   "0s",
+				},
+			},
+		},
+	}
+
+	ts := httptest.NewServer(&admin{deviceReporter: reporter})
+	defer ts.Close()
+
+	devices := []DeviceState{}
+	assert.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/admin/devices", &devices))
+	assert.Equal(t, []DeviceState(reporter), devices)
+
+	device := DeviceState{}
+	assert.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/admin/devices/core1.lax", &device))
+	assert.Equal(t, reporter[0], device)
+
+	assert.Equal(t, http.StatusNotFound, getJSON(t, ts.URL+"/admin/devices/core1.lhr", nil))
+	assert.Equal(t, http.StatusNotFound, getJSON(t, ts.URL+"/admin/unknown", nil))
+
+	res, err := http.Post(ts.URL+"/admin/devices", "application/json", nil)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+
+	// no reporter
+	ts.Config.Handler = &admin{}
+	assert.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/admin/devices", &devices))
+	assert.Len(t, devices, 0)
+}
+
+func TestAdminShards(t *testing.T) {
+	ts := httptest.NewServer(&admin{})
+	defer ts.Close()
+
+	shards := ShardState{}
+	assert.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/admin/shards", &shards))
+	assert.Equal(t, ShardState{Filters: []string{}}, shards)
+
+	ts.Config.Handler = &admin{
+		deviceReporter: deviceReporter{},
+		shardReporter:  shardReporter{Enabled: true, NodeID: "1", NumberOfNodes: 3},
+	}
+
+	assert.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/admin/shards", &shards))
+	assert.Equal(t, ShardState{Enabled: true, NodeID: "1", NumberOfNodes: 3, Filters: []string{"mainShard"}}, shards)
+}
+
+func TestAdminConfig(t *testing.T) {
+	cfg := &config.MockConfig{
+		MGlobal: &config.Global{
+			DeviceOptions: config.DeviceOptions{
+				Username: "panoptes",
+				Password: "secret-password",
+			},
+		},
+		MDevices: []config.Device{
+			{DeviceConfig: config.DeviceConfig{Host: "core1.lax", DeviceOptions: config.DeviceOptions{Password: "device-password"}}},
+		},
+		MDatabases: []config.Database{
+			{Name: "influx1", Service: "influxdb", Config: map[string]interface{}{"server": "http://127.0.0.1:8086", "token": "influx-token"}},
+		},
+	}
+
+	ts := httptest.NewServer(&admin{cfg: cfg})
+	defer ts.Close()
+
+	c := map[string]interface{}{}
+	assert.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/admin/config", &c))
+
+	options := c["global"].(map[string]interface{})["DeviceOptions"].(map[string]interface{})
+	assert.Equal(t, "panoptes", options["Username"])
+	assert.Equal(t, redacted, options["Password"])
+
+	device := c["devices"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "core1.lax", device["Host"])
+	assert.Equal(t, redacted, device["Password"])
+
+	database := c["databases"].([]interface{})[0].(map[string]interface{})["Config"].(map[string]interface{})
+	assert.Equal(t, "http://127.0.0.1:8086", database["server"])
+	assert.Equal(t, redacted, database["token"])
+}
+
+func TestRedact(t *testing.T) {
+	v := map[string]interface{}{
+		"password":   "",
+		"apiToken":   "abc",
+		"certFile":   "/etc/cert.pem",
+		"secretKeys": []interface{}{map[string]interface{}{"secret": "xyz"}},
+	}
+
+	redact(v)
+
+	assert.Equal(t, "", v["password"])
+	assert.Equal(t, redacted, v["apiToken"])
+	assert.Equal(t, "/etc/cert.pem", v["certFile"])
+	assert.Equal(t, redacted, v["secretKeys"].([]interface{})[0].(map[string]interface{})["secret"])
+}
+
+func TestAdminLoggers(t *testing.T) {
+	defer config.SetLogLevels(map[string]interface{}{"level": "info"})
+
+	cfg := config.NewMockConfig()
+	ts := httptest.NewServer(withClientCert(&admin{cfg: cfg, logger: cfg.Logger()}))
+	defer ts.Close()
+
+	request := func(method, url string) int {
+		req, _ := http.NewRequest(method, url, nil)
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, request(http.MethodPut, ts.URL+"/admin/loggers/device:core1.lax?level=debug"))
+	assert.Equal(t, http.StatusOK, request(http.MethodPut, ts.URL+"/admin/loggers/root?level=warn"))
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPut, ts.URL+"/admin/loggers/demux?level=verbose"))
+
+	levels := map[string]string{}
+	assert.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/admin/loggers", &levels))
+	assert.Equal(t, map[string]string{"root": "warn", "device:core1.lax": "debug"}, levels)
+
+	assert.Equal(t, http.StatusOK, request(http.MethodDelete, ts.URL+"/admin/loggers/device:core1.lax"))
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodDelete, ts.URL+"/admin/loggers/root"))
+	assert.Equal(t, http.StatusMethodNotAllowed, request(http.MethodPost, ts.URL+"/admin/loggers/demux"))
+
+	levels = map[string]string{}
+	assert.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/admin/loggers", &levels))
+	assert.Equal(t, map[string]string{"root": "warn"}, levels)
+
+	// without client certificate
+	ts.Config.Handler = &admin{cfg: cfg, logger: cfg.Logger()}
+	assert.Equal(t, http.StatusForbidden, request(http.MethodPut, ts.URL+"/admin/loggers/root?level=debug"))
+	assert.Equal(t, http.StatusForbidden, request(http.MethodDelete, ts.URL+"/admin/loggers/demux"))
+	assert.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/admin/loggers", nil))
+}
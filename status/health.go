@@ -0,0 +1,170 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Checker represents a readiness check, it returns nil if the dependency is ready.
+type Checker func() error
+
+// CheckResult represents a readiness check result.
+type CheckResult struct {
+	Name  string `json:"name"`
+	Ready bool   `json:"ready"`
+	Error string `json:"error,omitempty"`
+}
+
+// Readiness represents the readiness checks results.
+type Readiness struct {
+	Ready   bool          `json:"ready"`
+	Checks  []CheckResult `json:"checks"`
+	Failing []string      `json:"failing"`
+}
+
+// CheckState represents a check that's set by its dependency,
+// e.g. the result of the last configuration update.
+type CheckState struct {
+	mu  sync.Mutex
+	err error
+}
+
+type checks struct {
+	sync.RWMutex
+	checkers map[string]Checker
+}
+
+// livez responds if the process is alive, it doesn't check the dependencies.
+type livez struct{}
+
+// readyz responds the readiness checks in JSON format, the status code
+// is 503 if any of them is failing. The exclude query parameter skips
+// the comma separated checks, e.g. /readyz?exclude=shards.
+type readyz struct {
+	checks *checks
+}
+
+// NewCheckState creates a check state with the initial result.
+func NewCheckState(err error) *CheckState {
+	return &CheckState{err: err}
+}
+
+// Set sets the check result.
+func (c *CheckState) Set(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+// Check returns the check result, it's a Checker.
+func (c *CheckState) Check() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// AddCheck adds a readiness check, the check with the same name is replaced.
+func (s *Status) AddCheck(name string, check Checker) {
+	s.checks.Lock()
+	defer s.checks.Unlock()
+	s.checks.checkers[name] = check
+}
+
+// Ready returns nil if all of the readiness checks except
+// the excluded checks are ready, otherwise it returns the
+// failing checks.
+func (s *Status) Ready(exclude ...string) error {
+	r := s.checks.run(exclude)
+	if r.Ready {
+		return nil
+	}
+
+	return fmt.Errorf("not ready: %s", strings.Join(r.Failing, ", "))
+}
+
+func (c *checks) run(exclude []string) Readiness {
+	r := Readiness{
+		Ready:   true,
+		Checks:  []CheckResult{},
+		Failing: []string{},
+	}
+
+	excluded := make(map[string]bool)
+	for _, name := range exclude {
+		excluded[name] = true
+	}
+
+	c.RLock()
+	defer c.RUnlock()
+
+	for name, check := range c.checkers {
+		if excluded[name] {
+			continue
+		}
+
+		result := CheckResult{Name: name, Ready: true}
+		if err := check(); err != nil {
+			result.Ready = false
+			result.Error = err.Error()
+			r.Ready = false
+			r.Failing = append(r.Failing, name)
+		}
+
+		r.Checks = append(r.Checks, result)
+	}
+
+	sort.Slice(r.Checks, func(i, j int) bool {
+		return r.Checks[i].Name < r.Checks[j].Name
+	})
+	sort.Strings(r.Failing)
+
+	return r
+}
+
+// sinksCheck returns a check that fails if any of the producers or databases is unhealthy.
+func sinksCheck(r SinkReporter) Checker {
+	return func() error {
+		var unhealthy []string
+
+		for _, h := range r.SinkHealth() {
+			if !h.Healthy {
+				unhealthy = append(unhealthy, h.Name)
+			}
+		}
+
+		if len(unhealthy) > 0 {
+			return errors.New("unhealthy: " + strings.Join(unhealthy, ", "))
+		}
+
+		return nil
+	}
+}
+
+func (l *livez) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]string{"status": "alive"})
+}
+
+func (h *readyz) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var exclude []string
+
+	if e := r.URL.Query().Get("exclude"); e != "" {
+		exclude = strings.Split(e, ",")
+	}
+
+	readiness := h.checks.run(exclude)
+
+	w.Header().Set("Content-Type", "application/json")
+	if !readiness.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	json.NewEncoder(w).Encode(readiness)
+}
@@ -0,0 +1,63 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package status
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yahoo/panoptes-stream/config"
+)
+
+func TestLivez(t *testing.T) {
+	ts := httptest.NewServer(new(livez))
+	defer ts.Close()
+
+	v := map[string]string{}
+	assert.Equal(t, http.StatusOK, getJSON(t, ts.URL, &v))
+	assert.Equal(t, "alive", v["status"])
+}
+
+func TestReadyz(t *testing.T) {
+	s := New(&config.MockConfig{})
+	configState := NewCheckState(nil)
+
+	s.AddCheck("config", configState.Check)
+	s.AddCheck("shards", func() error { return errors.New("shards initializing") })
+	s.SetSinkReporter(sinkReporter{
+		{Name: "kafka1", Healthy: true},
+		{Name: "influx1", Error: "connection refused"},
+	})
+
+	ts := httptest.NewServer(&readyz{checks: s.checks})
+	defer ts.Close()
+
+	readiness := Readiness{}
+	assert.Equal(t, http.StatusServiceUnavailable, getJSON(t, ts.URL, &readiness))
+	assert.False(t, readiness.Ready)
+	assert.Equal(t, []string{"shards", "sinks"}, readiness.Failing)
+	assert.Equal(t, []CheckResult{
+		{Name: "config", Ready: true},
+		{Name: "shards", Error: "shards initializing"},
+		{Name: "sinks", Error: "unhealthy: influx1"},
+	}, readiness.Checks)
+
+	assert.EqualError(t, s.Ready("shards"), "not ready: sinks")
+
+	s.SetSinkReporter(sinkReporter{{Name: "kafka1", Healthy: true}})
+	assert.NoError(t, s.Ready("shards"))
+
+	readiness = Readiness{}
+	assert.Equal(t, http.StatusOK, getJSON(t, ts.URL+"?exclude=shards", &readiness))
+	assert.True(t, readiness.Ready)
+	assert.Len(t, readiness.Checks, 2)
+	assert.Len(t, readiness.Failing, 0)
+
+	configState.Set(errors.New("yaml: invalid"))
+	assert.EqualError(t, s.Ready("shards"), "not ready: config")
+}
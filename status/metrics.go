@@ -0,0 +1,383 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package status
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// DefObjectives represents the default summary objectives.
+	DefObjectives = map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001}
+	// LatencyBuckets represents the processing latency buckets from 1µs to 0.26s.
+	LatencyBuckets = prometheus.ExponentialBuckets(0.000001, 4, 10)
+)
+
+// MetricHistogram represents histogram metric
+type MetricHistogram struct {
+	Metric
+	Buckets []float64
+
+	count     uint64
+	histogram prometheus.Histogram
+}
+
+// MetricSummary represents summary metric
+type MetricSummary struct {
+	Metric
+	Objectives map[float64]float64
+
+	count   uint64
+	summary prometheus.Summary
+}
+
+// MetricVec represents a counter, gauge, histogram or summary metric
+// that takes the variable label values at observe time.
+type MetricVec struct {
+	Metric
+	LabelNames []string
+	Buckets    []float64
+	Objectives map[float64]float64
+
+	kind      string
+	counter   *prometheus.CounterVec
+	gauge     *prometheus.GaugeVec
+	histogram *prometheus.HistogramVec
+	summary   *prometheus.SummaryVec
+}
+
+// metricChild represents a vector metric with the label values.
+type metricChild struct {
+	counter  prometheus.Counter
+	gauge    prometheus.Gauge
+	observer prometheus.Observer
+}
+
+// NewHistogram creates a histogram metric, the buckets are
+// prometheus default buckets if they're not provided.
+func NewHistogram(name, help string, buckets []float64) *MetricHistogram {
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+
+	return &MetricHistogram{
+		Metric: Metric{
+			Name: name,
+			Help: help,
+		},
+		Buckets: buckets,
+	}
+}
+
+// NewSummary creates a summary metric, the objectives are
+// DefObjectives if they're not provided.
+func NewSummary(name, help string, objectives map[float64]float64) *MetricSummary {
+	if objectives == nil {
+		objectives = DefObjectives
+	}
+
+	return &MetricSummary{
+		Metric: Metric{
+			Name: name,
+			Help: help,
+		},
+		Objectives: objectives,
+	}
+}
+
+// NewCounterVec creates a counter metric with variable labels
+func NewCounterVec(name, help string, labelNames ...string) *MetricVec {
+	return newMetricVec("counter", name, help, labelNames)
+}
+
+// NewGaugeVec creates a gauge metric with variable labels
+func NewGaugeVec(name, help string, labelNames ...string) *MetricVec {
+	return newMetricVec("gauge", name, help, labelNames)
+}
+
+// NewHistogramVec creates a histogram metric with variable labels
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *MetricVec {
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+
+	m := newMetricVec("histogram", name, help, labelNames)
+	m.Buckets = buckets
+
+	return m
+}
+
+// NewSummaryVec creates a summary metric with variable labels
+func NewSummaryVec(name, help string, objectives map[float64]float64, labelNames ...string) *MetricVec {
+	if objectives == nil {
+		objectives = DefObjectives
+	}
+
+	m := newMetricVec("summary", name, help, labelNames)
+	m.Objectives = objectives
+
+	return m
+}
+
+func newMetricVec(kind, name, help string, labelNames []string) *MetricVec {
+	return &MetricVec{
+		Metric: Metric{
+			Name: name,
+			Help: help,
+		},
+		LabelNames: labelNames,
+		kind:       kind,
+	}
+}
+
+// collector creates the prometheus collector with the constant labels.
+func (m *MetricHistogram) collector(prefix string, labels Labels) prometheus.Collector {
+	if m.histogram == nil {
+		m.histogram = prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:        prefix + m.Name,
+			Help:        m.Help,
+			ConstLabels: labels,
+			Buckets:     m.Buckets,
+		})
+	}
+
+	return m.histogram
+}
+
+// Observe adds a sample to histogram metric
+func (m *MetricHistogram) Observe(v float64) {
+	if m.histogram == nil {
+		return
+	}
+
+	m.histogram.Observe(v)
+	atomic.AddUint64(&m.count, 1)
+}
+
+// Get returns the number of histogram samples
+func (m *MetricHistogram) Get() uint64 {
+	return atomic.LoadUint64(&m.count)
+}
+
+// Inc is not available for histogram metric
+func (m *MetricHistogram) Inc() {
+	// doesn't support
+}
+
+// Dec is not available for histogram metric
+func (m *MetricHistogram) Dec() {
+	// doesn't support
+}
+
+// Set is not available for histogram metric
+func (m *MetricHistogram) Set(i uint64) {
+	// doesn't support
+}
+
+// With returns the histogram metric, it doesn't have variable labels
+func (m *MetricHistogram) With(labelValues ...string) Metrics {
+	return m
+}
+
+// collector creates the prometheus collector with the constant labels.
+func (m *MetricSummary) collector(prefix string, labels Labels) prometheus.Collector {
+	if m.summary == nil {
+		m.summary = prometheus.NewSummary(prometheus.SummaryOpts{
+			Name:        prefix + m.Name,
+			Help:        m.Help,
+			ConstLabels: labels,
+			Objectives:  m.Objectives,
+		})
+	}
+
+	return m.summary
+}
+
+// Observe adds a sample to summary metric
+func (m *MetricSummary) Observe(v float64) {
+	if m.summary == nil {
+		return
+	}
+
+	m.summary.Observe(v)
+	atomic.AddUint64(&m.count, 1)
+}
+
+// Get returns the number of summary samples
+func (m *MetricSummary) Get() uint64 {
+	return atomic.LoadUint64(&m.count)
+}
+
+// Inc is not available for summary metric
+func (m *MetricSummary) Inc() {
+	// doesn't support
+}
+
+// Dec is not available for summary metric
+func (m *MetricSummary) Dec() {
+	// doesn't support
+}
+
+// Set is not available for summary metric
+func (m *MetricSummary) Set(i uint64) {
+	// doesn't support
+}
+
+// With returns the summary metric, it doesn't have variable labels
+func (m *MetricSummary) With(labelValues ...string) Metrics {
+	return m
+}
+
+// collector creates the prometheus collector with the constant labels.
+func (m *MetricVec) collector(prefix string, labels Labels) prometheus.Collector {
+	switch m.kind {
+	case "counter":
+		if m.counter == nil {
+			m.counter = prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name:        prefix + m.Name,
+				Help:        m.Help,
+				ConstLabels: labels,
+			}, m.LabelNames)
+		}
+		return m.counter
+	case "gauge":
+		if m.gauge == nil {
+			m.gauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name:        prefix + m.Name,
+				Help:        m.Help,
+				ConstLabels: labels,
+			}, m.LabelNames)
+		}
+		return m.gauge
+	case "histogram":
+		if m.histogram == nil {
+			m.histogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Name:        prefix + m.Name,
+				Help:        m.Help,
+				ConstLabels: labels,
+				Buckets:     m.Buckets,
+			}, m.LabelNames)
+		}
+		return m.histogram
+	default:
+		if m.summary == nil {
+			m.summary = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+				Name:        prefix + m.Name,
+				Help:        m.Help,
+				ConstLabels: labels,
+				Objectives:  m.Objectives,
+			}, m.LabelNames)
+		}
+		return m.summary
+	}
+}
+
+// With returns the metric with the given label values, the
+// metric doesn't record anything if it's not registered or
+// the number of label values doesn't match the label names.
+func (m *MetricVec) With(labelValues ...string) Metrics {
+	var (
+		c   = new(metricChild)
+		err error
+	)
+
+	switch {
+	case m.counter != nil:
+		c.counter, err = m.counter.GetMetricWithLabelValues(labelValues...)
+	case m.gauge != nil:
+		c.gauge, err = m.gauge.GetMetricWithLabelValues(labelValues...)
+	case m.histogram != nil:
+		c.observer, err = m.histogram.GetMetricWithLabelValues(labelValues...)
+	case m.summary != nil:
+		c.observer, err = m.summary.GetMetricWithLabelValues(labelValues...)
+	}
+
+	if err != nil {
+		return new(metricChild)
+	}
+
+	return c
+}
+
+// Delete deletes the metric with the given label values
+func (m *MetricVec) Delete(labelValues ...string) {
+	switch {
+	case m.counter != nil:
+		m.counter.DeleteLabelValues(labelValues...)
+	case m.gauge != nil:
+		m.gauge.DeleteLabelValues(labelValues...)
+	case m.histogram != nil:
+		m.histogram.DeleteLabelValues(labelValues...)
+	case m.summary != nil:
+		m.summary.DeleteLabelValues(labelValues...)
+	}
+}
+
+// Inc is not available for vector metric, use With
+func (m *MetricVec) Inc() {
+	// doesn't support
+}
+
+// Dec is not available for vector metric, use With
+func (m *MetricVec) Dec() {
+	// doesn't support
+}
+
+// Set is not available for vector metric, use With
+func (m *MetricVec) Set(i uint64) {
+	// doesn't support
+}
+
+// Observe is not available for vector metric, use With
+func (m *MetricVec) Observe(v float64) {
+	// doesn't support
+}
+
+// Get is not available for vector metric
+func (m *MetricVec) Get() uint64 {
+	return 0
+}
+
+// Inc increases one unit counter or gauge metric
+func (c *metricChild) Inc() {
+	if c.counter != nil {
+		c.counter.Inc()
+	}
+	if c.gauge != nil {
+		c.gauge.Inc()
+	}
+}
+
+// Dec decreases one unit from gauge metric
+func (c *metricChild) Dec() {
+	if c.gauge != nil {
+		c.gauge.Dec()
+	}
+}
+
+// Set sets gauge metric value
+func (c *metricChild) Set(i uint64) {
+	if c.gauge != nil {
+		c.gauge.Set(float64(i))
+	}
+}
+
+// Observe adds a sample to histogram or summary metric
+func (c *metricChild) Observe(v float64) {
+	if c.observer != nil {
+		c.observer.Observe(v)
+	}
+}
+
+// Get is not available for vector metric
+func (c *metricChild) Get() uint64 {
+	return 0
+}
+
+// With returns the metric itself, the label values are already set
+func (c *metricChild) With(labelValues ...string) Metrics {
+	return c
+}
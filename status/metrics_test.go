@@ -0,0 +1,97 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package status
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram(t *testing.T) {
+	metrics := map[string]Metrics{"processSeconds": NewHistogram("test_process_seconds", "Process latency", []float64{0.1, 1})}
+
+	// not registered
+	metrics["processSeconds"].Observe(0.5)
+	assert.Equal(t, uint64(0), metrics["processSeconds"].Get())
+
+	Register(Labels{"host": "127.0.0.1"}, metrics)
+	defer Unregister(Labels{"host": "127.0.0.1"}, metrics)
+
+	metrics["processSeconds"].Observe(0.05)
+	metrics["processSeconds"].With("ignored").Observe(0.5)
+	assert.Equal(t, uint64(2), metrics["processSeconds"].Get())
+
+	expected := `
+		# HELP panoptes_test_process_seconds Process latency
+		# TYPE panoptes_test_process_seconds histogram
+		panoptes_test_process_seconds_bucket{host="127.0.0.1",le="0.1"} 1
+		panoptes_test_process_seconds_bucket{host="127.0.0.1",le="1"} 2
+		panoptes_test_process_seconds_bucket{host="127.0.0.1",le="+Inf"} 2
+		panoptes_test_process_seconds_sum{host="127.0.0.1"} 0.55
+		panoptes_test_process_seconds_count{host="127.0.0.1"} 2
+	`
+	assert.NoError(t, testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "panoptes_test_process_seconds"))
+}
+
+func TestSummary(t *testing.T) {
+	metrics := map[string]Metrics{"latency": NewSummary("test_latency_seconds", "", nil)}
+	Register(nil, metrics)
+
+	metrics["latency"].Observe(1)
+	metrics["latency"].Observe(3)
+	assert.Equal(t, uint64(2), metrics["latency"].Get())
+
+	Unregister(nil, metrics)
+	count, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "panoptes_test_latency_seconds")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestMetricVec(t *testing.T) {
+	metrics := map[string]Metrics{
+		"processSeconds": NewHistogramVec("test_vec_process_seconds", "", LatencyBuckets, "path"),
+		"dropsTotal":     NewCounterVec("test_vec_drops_total", "Drops", "path"),
+		"sessions":       NewGaugeVec("test_vec_sessions", "Sessions", "peer"),
+		"latency":        NewSummaryVec("test_vec_latency_seconds", "", nil, "path"),
+	}
+
+	Register(Labels{"host": "127.0.0.1"}, metrics)
+	defer Unregister(Labels{"host": "127.0.0.1"}, metrics)
+
+	metrics["processSeconds"].With("/interfaces").Observe(0.001)
+	metrics["processSeconds"].With("/bgp").Observe(0.002)
+	metrics["dropsTotal"].With("/interfaces").Inc()
+	metrics["sessions"].With("10.0.0.1").Set(3)
+	metrics["sessions"].With("10.0.0.1").Dec()
+	metrics["latency"].With("/bgp").Observe(1)
+
+	// wrong number of label values
+	metrics["dropsTotal"].With("/interfaces", "extra").Inc()
+	// not available without label values
+	metrics["dropsTotal"].Inc()
+
+	count, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "panoptes_test_vec_process_seconds")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	expected := `
+		# HELP panoptes_test_vec_drops_total Drops
+		# TYPE panoptes_test_vec_drops_total counter
+		panoptes_test_vec_drops_total{host="127.0.0.1",path="/interfaces"} 1
+		# HELP panoptes_test_vec_sessions Sessions
+		# TYPE panoptes_test_vec_sessions gauge
+		panoptes_test_vec_sessions{host="127.0.0.1",peer="10.0.0.1"} 2
+	`
+	assert.NoError(t, testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected),
+		"panoptes_test_vec_drops_total", "panoptes_test_vec_sessions"))
+
+	metrics["processSeconds"].(*MetricVec).Delete("/bgp")
+	count, err = testutil.GatherAndCount(prometheus.DefaultGatherer, "panoptes_test_vec_process_seconds")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,217 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package status
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Output error classes
+const (
+	// ErrorInvalid represents a data point that couldn't be encoded or routed.
+	ErrorInvalid = "invalid"
+	// ErrorOverflow represents a data point that dropped by demux since the output channel was full.
+	ErrorOverflow = "overflow"
+	// ErrorTimeout represents a write that timed out.
+	ErrorTimeout = "timeout"
+	// ErrorConnection represents a write that couldn't connect or lost the connection.
+	ErrorConnection = "connection"
+	// ErrorCanceled represents a write that canceled by termination.
+	ErrorCanceled = "canceled"
+	// ErrorWrite represents the other write errors.
+	ErrorWrite = "write"
+)
+
+var errorClasses = []string{ErrorInvalid, ErrorOverflow, ErrorTimeout, ErrorConnection, ErrorCanceled, ErrorWrite}
+
+// OutputMetrics represents a producer or database metrics,
+// the metrics are labeled by the output name.
+type OutputMetrics struct {
+	name string
+
+	// DataIn is the number of data points received from demux.
+	DataIn prometheus.Counter
+	// DataOut is the number of data points written.
+	DataOut prometheus.Counter
+	// Bytes is the number of bytes written.
+	Bytes prometheus.Counter
+
+	batchSize     prometheus.Observer
+	writeDuration prometheus.Observer
+}
+
+var (
+	outputOnce sync.Once
+
+	outputDataIn = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "panoptes_output_data_in_total",
+		Help: "Number of data points received by the output",
+	}, []string{"output"})
+
+	outputDataOut = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "panoptes_output_data_out_total",
+		Help: "Number of data points written by the output",
+	}, []string{"output"})
+
+	outputBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "panoptes_output_bytes_total",
+		Help: "Number of bytes written by the output",
+	}, []string{"output"})
+
+	outputErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "panoptes_output_errors_total",
+		Help: "Number of output errors by class",
+	}, []string{"output", "class"})
+
+	outputBatchSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "panoptes_output_batch_size",
+		Help:    "Number of data points per write",
+		Buckets: prometheus.ExponentialBuckets(1, 4, 8),
+	}, []string{"output"})
+
+	outputWriteDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "panoptes_output_write_duration_seconds",
+		Help:    "Write latency of the output",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"output"})
+
+	outputChannels = &channelCollector{
+		channels: make(map[string]outputChannel),
+		length: prometheus.NewDesc("panoptes_output_channel_length",
+			"Number of data points in the output channel", []string{"output"}, nil),
+		capacity: prometheus.NewDesc("panoptes_output_channel_capacity",
+			"Capacity of the output channel", []string{"output"}, nil),
+	}
+)
+
+// channelCollector collects the output channels occupancy at scrape time.
+type channelCollector struct {
+	sync.RWMutex
+	channels map[string]outputChannel
+	length   *prometheus.Desc
+	capacity *prometheus.Desc
+}
+
+type outputChannel struct {
+	length   func() int
+	capacity int
+}
+
+func registerOutput() {
+	outputOnce.Do(func() {
+		prometheus.Register(outputDataIn)
+		prometheus.Register(outputDataOut)
+		prometheus.Register(outputBytes)
+		prometheus.Register(outputErrors)
+		prometheus.Register(outputBatchSize)
+		prometheus.Register(outputWriteDuration)
+		prometheus.Register(outputChannels)
+	})
+}
+
+// NewOutputMetrics returns the metrics of the given output name
+// (producer or database name), the metrics are registered once.
+func NewOutputMetrics(name string) *OutputMetrics {
+	registerOutput()
+
+	return &OutputMetrics{
+		name:          name,
+		DataIn:        outputDataIn.WithLabelValues(name),
+		DataOut:       outputDataOut.WithLabelValues(name),
+		Bytes:         outputBytes.WithLabelValues(name),
+		batchSize:     outputBatchSize.WithLabelValues(name),
+		writeDuration: outputWriteDuration.WithLabelValues(name),
+	}
+}
+
+// ObserveWrite records a write of the batch that started at start,
+// bytes is zero if the output doesn't know the written bytes.
+func (m *OutputMetrics) ObserveWrite(start time.Time, points, bytes int, err error) {
+	m.writeDuration.Observe(time.Since(start).Seconds())
+	m.batchSize.Observe(float64(points))
+
+	if err != nil {
+		m.Error(errorClass(err))
+		return
+	}
+
+	m.DataOut.Add(float64(points))
+	m.Bytes.Add(float64(bytes))
+}
+
+// Error increases the errors of the given class.
+func (m *OutputMetrics) Error(class string) {
+	OutputError(m.name, class)
+}
+
+// OutputError increases the errors of the given output name and class.
+func OutputError(name, class string) {
+	registerOutput()
+	outputErrors.WithLabelValues(name, class).Inc()
+}
+
+// SetOutputChannel sets the output channel to report its occupancy.
+func SetOutputChannel(name string, length func() int, capacity int) {
+	registerOutput()
+
+	outputChannels.Lock()
+	defer outputChannels.Unlock()
+	outputChannels.channels[name] = outputChannel{length: length, capacity: capacity}
+}
+
+// DeleteOutputMetrics deletes the metrics of the given output name.
+func DeleteOutputMetrics(name string) {
+	outputDataIn.DeleteLabelValues(name)
+	outputDataOut.DeleteLabelValues(name)
+	outputBytes.DeleteLabelValues(name)
+	outputBatchSize.DeleteLabelValues(name)
+	outputWriteDuration.DeleteLabelValues(name)
+
+	for _, class := range errorClasses {
+		outputErrors.DeleteLabelValues(name, class)
+	}
+
+	outputChannels.Lock()
+	defer outputChannels.Unlock()
+	delete(outputChannels.channels, name)
+}
+
+func (c *channelCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.length
+	ch <- c.capacity
+}
+
+func (c *channelCollector) Collect(ch chan<- prometheus.Metric) {
+	c.RLock()
+	defer c.RUnlock()
+
+	for name, oc := range c.channels {
+		ch <- prometheus.MustNewConstMetric(c.length, prometheus.GaugeValue, float64(oc.length()), name)
+		ch <- prometheus.MustNewConstMetric(c.capacity, prometheus.GaugeValue, float64(oc.capacity), name)
+	}
+}
+
+// errorClass returns the class of the write error.
+func errorClass(err error) string {
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTimeout
+	case errors.As(err, &netErr):
+		return ErrorConnection
+	}
+
+	return ErrorWrite
+}
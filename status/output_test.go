@@ -0,0 +1,76 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package status
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputMetrics(t *testing.T) {
+	m := NewOutputMetrics("kafka1")
+
+	m.DataIn.Add(3)
+	m.ObserveWrite(time.Now(), 2, 100, nil)
+	m.ObserveWrite(time.Now(), 1, 50, context.DeadlineExceeded)
+	m.Error(ErrorInvalid)
+	OutputError("kafka1", ErrorOverflow)
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(outputDataIn.WithLabelValues("kafka1")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(outputDataOut.WithLabelValues("kafka1")))
+	assert.Equal(t, float64(100), testutil.ToFloat64(outputBytes.WithLabelValues("kafka1")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(outputErrors.WithLabelValues("kafka1", ErrorTimeout)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(outputErrors.WithLabelValues("kafka1", ErrorInvalid)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(outputErrors.WithLabelValues("kafka1", ErrorOverflow)))
+
+	expected := `
+		# HELP panoptes_output_batch_size Number of data points per write
+		# TYPE panoptes_output_batch_size histogram
+		panoptes_output_batch_size_bucket{output="kafka1",le="1"} 1
+		panoptes_output_batch_size_bucket{output="kafka1",le="4"} 2
+		panoptes_output_batch_size_bucket{output="kafka1",le="16"} 2
+		panoptes_output_batch_size_bucket{output="kafka1",le="64"} 2
+		panoptes_output_batch_size_bucket{output="kafka1",le="256"} 2
+		panoptes_output_batch_size_bucket{output="kafka1",le="1024"} 2
+		panoptes_output_batch_size_bucket{output="kafka1",le="4096"} 2
+		panoptes_output_batch_size_bucket{output="kafka1",le="16384"} 2
+		panoptes_output_batch_size_bucket{output="kafka1",le="+Inf"} 2
+		panoptes_output_batch_size_sum{output="kafka1"} 3
+		panoptes_output_batch_size_count{output="kafka1"} 2
+	`
+	assert.NoError(t, testutil.CollectAndCompare(outputBatchSize, strings.NewReader(expected)))
+
+	ch := make(chan int, 10)
+	ch <- 1
+	SetOutputChannel("kafka1", func() int { return len(ch) }, cap(ch))
+
+	expected = `
+		# HELP panoptes_output_channel_capacity Capacity of the output channel
+		# TYPE panoptes_output_channel_capacity gauge
+		panoptes_output_channel_capacity{output="kafka1"} 10
+		# HELP panoptes_output_channel_length Number of data points in the output channel
+		# TYPE panoptes_output_channel_length gauge
+		panoptes_output_channel_length{output="kafka1"} 1
+	`
+	assert.NoError(t, testutil.CollectAndCompare(outputChannels, strings.NewReader(expected)))
+
+	DeleteOutputMetrics("kafka1")
+	assert.Equal(t, 0, testutil.CollectAndCount(outputDataIn))
+	assert.Equal(t, 0, testutil.CollectAndCount(outputErrors))
+	assert.Equal(t, 0, testutil.CollectAndCount(outputChannels))
+}
+
+func TestErrorClass(t *testing.T) {
+	assert.Equal(t, ErrorCanceled, errorClass(context.Canceled))
+	assert.Equal(t, ErrorTimeout, errorClass(context.DeadlineExceeded))
+	assert.Equal(t, ErrorConnection, errorClass(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.Equal(t, ErrorWrite, errorClass(errors.New("bad request")))
+}
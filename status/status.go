@@ -4,6 +4,8 @@
 package status
 
 import (
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"sync/atomic"
@@ -13,21 +15,51 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/lifecycle"
 	"github.com/yahoo/panoptes-stream/secret"
 )
 
-// Metrics represents counter and gauge metrics.
+// Metrics represents counter, gauge, histogram and summary metrics.
+// The vector metrics take the label values through With.
 type Metrics interface {
 	Dec()
 	Inc()
 	Get() uint64
 	Set(uint64)
+	Observe(float64)
+	With(...string) Metrics
+}
+
+// metricCollector represents a metric that's backed by a prometheus collector.
+type metricCollector interface {
+	collector(prefix string, labels Labels) prometheus.Collector
 }
 
 // Status represents Panoptes status and healthcheck
 type Status struct {
-	cfg    config.Config
-	logger *zap.Logger
+	cfg            config.Config
+	logger         *zap.Logger
+	sinkReporter   SinkReporter
+	deviceReporter DeviceReporter
+	shardReporter  ShardReporter
+	tapper         Tapper
+	checks         *checks
+}
+
+// SinkHealth represents a producer or database health and statistics.
+type SinkHealth struct {
+	Name     string          `json:"name"`
+	Service  string          `json:"service"`
+	Type     string          `json:"type"`
+	Healthy  bool            `json:"healthy"`
+	Error    string          `json:"error,omitempty"`
+	Restarts uint64          `json:"restarts"`
+	Stats    lifecycle.Stats `json:"stats"`
+}
+
+// SinkReporter reports the producers and databases health.
+type SinkReporter interface {
+	SinkHealth() []SinkHealth
 }
 
 // Metric represents a metric
@@ -57,14 +89,58 @@ func (h *healthcheck) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprint(w, "panoptes alive and reachable")
 }
 
+// sinks responds the producers and databases health in JSON format,
+// the status code is 503 if any of them is unhealthy.
+type sinks struct {
+	reporter SinkReporter
+}
+
+func (s *sinks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	health := []SinkHealth{}
+	if s.reporter != nil {
+		health = append(health, s.reporter.SinkHealth()...)
+	}
+
+	code := http.StatusOK
+	for _, h := range health {
+		if !h.Healthy {
+			code = http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(health)
+}
+
 // New constructs a new status
 func New(cfg config.Config) *Status {
 	return &Status{
 		cfg:    cfg,
-		logger: cfg.Logger(),
+		logger: config.NamedLogger(cfg.Logger(), "status"),
+		checks: &checks{checkers: make(map[string]Checker)},
 	}
 }
 
+// SetSinkReporter sets the producers and databases health reporter,
+// it should be called before Start. It adds the sinks readiness check.
+func (s *Status) SetSinkReporter(r SinkReporter) {
+	s.sinkReporter = r
+	s.AddCheck("sinks", sinksCheck(r))
+}
+
+// SetDeviceReporter sets the subscribed devices reporter of
+// the admin API, it should be called before Start.
+func (s *Status) SetDeviceReporter(r DeviceReporter) {
+	s.deviceReporter = r
+}
+
+// SetShardReporter sets the shard assignment reporter of
+// the admin API, it should be called before Start.
+func (s *Status) SetShardReporter(r ShardReporter) {
+	s.shardReporter = r
+}
+
 // Start starts status web service and exposes
 // panoptes metrics and healthcheck
 func (s *Status) Start() {
@@ -82,10 +158,18 @@ func (s *Status) start() error {
 		config.Addr = ":8081"
 	}
 
-	s.logger.Info("status", zap.String("address", config.Addr), zap.Bool("tls", config.TLSConfig.Enabled))
+	s.logger.Info("status", zap.String("address", config.Addr), zap.Bool("tls", config.TLSConfig.Enabled), zap.Bool("admin", config.Admin))
 
 	http.Handle("/metrics", promhttp.Handler())
 	http.Handle("/healthcheck", new(healthcheck))
+	http.Handle("/livez", new(livez))
+	http.Handle("/readyz", &readyz{checks: s.checks})
+	http.Handle("/sinks", &sinks{reporter: s.sinkReporter})
+
+	// the admin API is opt-in since it exposes the devices and the configuration
+	if config.Admin {
+		http.Handle("/admin/", &admin{cfg: s.cfg, logger: s.logger, deviceReporter: s.deviceReporter, shardReporter: s.shardReporter, tapper: s.tapper})
+	}
 
 	if !config.TLSConfig.Enabled {
 		return http.ListenAndServe(config.Addr, nil)
@@ -96,6 +180,13 @@ func (s *Status) start() error {
 		return err
 	}
 
+	// the admin clients authenticate by a certificate of the status CA,
+	// the other endpoints don't require a client certificate
+	if config.Admin && tlsConfig.RootCAs != nil {
+		tlsConfig.ClientCAs = tlsConfig.RootCAs
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
 	srv := http.Server{
 		Addr:      config.Addr,
 		TLSConfig: tlsConfig,
@@ -128,6 +219,8 @@ func Register(labels Labels, metrics map[string]Metrics) {
 				func() float64 {
 					return float64(atomic.LoadUint64(&v.Value))
 				}))
+		case metricCollector:
+			prometheus.Register(v.collector(prefix, labels))
 		}
 	}
 }
@@ -154,6 +247,8 @@ func Unregister(labels Labels, metrics map[string]Metrics) {
 				func() float64 {
 					return float64(v.Value)
 				}))
+		case metricCollector:
+			prometheus.Unregister(v.collector(prefix, labels))
 		}
 	}
 
@@ -199,6 +294,16 @@ func (m *MetricCounter) Get() uint64 {
 	return atomic.LoadUint64(&m.Value)
 }
 
+// Observe is not available for counter metric
+func (m *MetricCounter) Observe(v float64) {
+	// doesn't support
+}
+
+// With returns the counter metric, it doesn't have variable labels
+func (m *MetricCounter) With(labelValues ...string) Metrics {
+	return m
+}
+
 // Inc increases one unit to gauge metric
 func (m *MetricGauge) Inc() {
 	atomic.AddUint64(&m.Value, 1)
@@ -218,3 +323,13 @@ func (m *MetricGauge) Set(i uint64) {
 func (m *MetricGauge) Get() uint64 {
 	return atomic.LoadUint64(&m.Value)
 }
+
+// Observe is not available for gauge metric
+func (m *MetricGauge) Observe(v float64) {
+	// doesn't support
+}
+
+// With returns the gauge metric, it doesn't have variable labels
+func (m *MetricGauge) With(labelValues ...string) Metrics {
+	return m
+}
@@ -4,6 +4,7 @@
 package status
 
 import (
+	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"net/http"
@@ -31,6 +32,37 @@ func TestHealthcheck(t *testing.T) {
 	assert.Equal(t, "panoptes alive and reachable", string(hcMsg))
 }
 
+type sinkReporter []SinkHealth
+
+func (r sinkReporter) SinkHealth() []SinkHealth {
+	return r
+}
+
+func TestSinks(t *testing.T) {
+	reporter := sinkReporter{
+		{Name: "kafka1", Service: "kafka", Type: "producer", Healthy: true},
+		{Name: "influx1", Service: "influxdb", Type: "database", Error: "connection refused", Restarts: 2},
+	}
+
+	ts := httptest.NewServer(&sinks{reporter: reporter})
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	health := []SinkHealth{}
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&health))
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Equal(t, []SinkHealth(reporter), health)
+
+	ts.Config.Handler = &sinks{reporter: reporter[:1]}
+	res, err = http.Get(ts.URL)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
 func TestDuplicateRegisterMetrics(t *testing.T) {
 	var (
 		metrics         = make(map[string]Metrics)
@@ -130,4 +162,10 @@ func TestStart(t *testing.T) {
 	assert.NoError(t, err)
 	defer resp.Body.Close()
 	assert.Equal(t, 200, resp.StatusCode)
+
+	// the admin API is disabled by default
+	resp, err = http.Get("http://localhost:8081/admin/config")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, 404, resp.StatusCode)
 }
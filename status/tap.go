@@ -0,0 +1,151 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// tapKeepalive is the interval of the tap keepalive comments,
+// it keeps the idle stream open through the proxies.
+var tapKeepalive = 15 * time.Second
+
+// ErrTapLimit is returned by the tapper if the maximum number of taps are attached.
+var ErrTapLimit = errors.New("too many taps")
+
+// TapFilter represents the live data tap filter, the empty fields match
+// all of the data points. Prefix matches the beginning of the data point
+// path prefix (the subscribed sensor path), Rate is the maximum data points
+// per second and Sample takes every nth matched data point.
+type TapFilter struct {
+	SystemID string
+	Prefix   string
+	Key      string
+	Output   string
+	Rate     int
+	Sample   int
+}
+
+// TapEvent represents a mirrored data point.
+type TapEvent struct {
+	Output string                 `json:"output"`
+	Data   map[string]interface{} `json:"data"`
+}
+
+// Tapper mirrors the data points that match the filter until the context
+// is canceled, the channel is closed once the tap is detached.
+type Tapper interface {
+	Tap(ctx context.Context, filter TapFilter) (<-chan TapEvent, error)
+}
+
+// SetTapper sets the live data tapper of the admin API,
+// it should be called before Start.
+func (s *Status) SetTapper(t Tapper) {
+	s.tapper = t
+}
+
+// tap streams the data points as server-sent events:
+// /admin/tap?system_id=core1.lax&prefix=/interfaces&key=^out-&output=kafka1&rate=10&sample=1
+// The raw telemetry is available to the authenticated clients only.
+func (a *admin) tap(w http.ResponseWriter, r *http.Request) {
+	if !authenticated(r) {
+		http.Error(w, "client certificate required", http.StatusForbidden)
+		return
+	}
+
+	if a.tapper == nil {
+		http.Error(w, "tap not available", http.StatusNotFound)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	filter, err := tapFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ch, err := a.tapper.Tap(r.Context(), filter)
+	if err == ErrTapLimit {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	a.logger.Info("admin", zap.String("event", "tap attached"), zap.String("remote", r.RemoteAddr))
+	defer a.logger.Info("admin", zap.String("event", "tap detached"), zap.String("remote", r.RemoteAddr))
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(tapKeepalive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			b, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", b); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		}
+
+		flusher.Flush()
+	}
+}
+
+func tapFilter(r *http.Request) (TapFilter, error) {
+	var err error
+
+	q := r.URL.Query()
+	filter := TapFilter{
+		SystemID: q.Get("system_id"),
+		Prefix:   q.Get("prefix"),
+		Key:      q.Get("key"),
+		Output:   q.Get("output"),
+	}
+
+	if v := q.Get("rate"); v != "" {
+		if filter.Rate, err = strconv.Atoi(v); err != nil {
+			return filter, fmt.Errorf("invalid rate: %s", v)
+		}
+	}
+
+	if v := q.Get("sample"); v != "" {
+		if filter.Sample, err = strconv.Atoi(v); err != nil {
+			return filter, fmt.Errorf("invalid sample: %s", v)
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,65 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package status
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type tapper struct {
+	filter TapFilter
+	err    error
+}
+
+func (t *tapper) Tap(ctx context.Context, filter TapFilter) (<-chan TapEvent, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	t.filter = filter
+
+	ch := make(chan TapEvent, 1)
+	ch <- TapEvent{Output: "console::stdout", Data: map[string]interface{}{"key": "out-octets"}}
+	close(ch)
+
+	return ch, nil
+}
+
+func TestAdminTap(t *testing.T) {
+	tp := &tapper{}
+	ts := httptest.NewServer(withClientCert(&admin{logger: zap.NewNop(), tapper: tp}))
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/admin/tap?system_id=core1.lax&prefix=/interfaces&key=^out-&output=console&rate=5&sample=2")
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+	assert.Equal(t, TapFilter{SystemID: "core1.lax", Prefix: "/interfaces", Key: "^out-", Output: "console", Rate: 5, Sample: 2}, tp.filter)
+
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, `data: {"output":"console::stdout","data":{"key":"out-octets"}}`+"\n", line)
+
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, ts.URL+"/admin/tap?rate=fast", nil))
+
+	tp.err = ErrTapLimit
+	assert.Equal(t, http.StatusServiceUnavailable, getJSON(t, ts.URL+"/admin/tap", nil))
+
+	ts2 := httptest.NewServer(withClientCert(&admin{logger: zap.NewNop()}))
+	defer ts2.Close()
+	assert.Equal(t, http.StatusNotFound, getJSON(t, ts2.URL+"/admin/tap", nil))
+
+	// without client certificate
+	ts2.Config.Handler = &admin{logger: zap.NewNop(), tapper: tp}
+	assert.Equal(t, http.StatusForbidden, getJSON(t, ts2.URL+"/admin/tap", nil))
+}
@@ -44,7 +44,7 @@ func TestAristaSimplePath(t *testing.T) {
 		Path:    "/interfaces/interface/state/counters",
 	})
 
-	g := New(cfg.Logger(), conn, sensors, ch)
+	g := New(cfg.Logger(), conn, sensors, ch, nil)
 	g.Start(ctx)
 
 	resp := <-ch
@@ -86,7 +86,7 @@ func TestAristaBGPSimplePath(t *testing.T) {
 		Path:    "/network-instances/network-instance",
 	})
 
-	g := New(cfg.Logger(), conn, sensors, ch)
+	g := New(cfg.Logger(), conn, sensors, ch, nil)
 	g.Start(ctx)
 
 	resp := <-ch
@@ -131,7 +131,7 @@ func TestAristaKVPath(t *testing.T) {
 		Path:    "/interfaces/interface[name=Ethernet1]/state/counters",
 	})
 
-	g := New(cfg.Logger(), conn, sensors, ch)
+	g := New(cfg.Logger(), conn, sensors, ch, nil)
 	g.Start(ctx)
 
 	resp := <-ch
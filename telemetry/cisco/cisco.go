@@ -4,9 +4,13 @@
 package cisco
 
 import (
+	"context"
+
+	"github.com/yahoo/panoptes-stream/config"
 	"github.com/yahoo/panoptes-stream/telemetry"
 	"github.com/yahoo/panoptes-stream/telemetry/cisco/gnmi"
 	"github.com/yahoo/panoptes-stream/telemetry/cisco/mdt"
+	"github.com/yahoo/panoptes-stream/telemetry/dialout"
 )
 
 // Register Cisco telemetries
@@ -14,3 +18,10 @@ func Register(telemetryRegistrar *telemetry.Registrar) {
 	telemetryRegistrar.Register("cisco.gnmi", gnmi.Version(), gnmi.New)
 	telemetryRegistrar.Register("cisco.mdt", mdt.Version(), mdt.New)
 }
+
+// RegisterDialout registers Cisco dial-out telemetries
+func RegisterDialout(dialoutRegistrar *dialout.Registrar) {
+	dialoutRegistrar.Register("cisco.mdt", mdt.Version(), func(ctx context.Context, cfg config.Config, outChan telemetry.ExtDSChan, subscriber dialout.Subscriber) dialout.Service {
+		return mdt.NewDialout(ctx, cfg, outChan, subscriber)
+	})
+}
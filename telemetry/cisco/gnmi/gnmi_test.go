@@ -104,7 +104,7 @@ func TestWithMockServer(t *testing.T) {
 		Path:   "/interfaces/interface/state/counters",
 	})
 
-	g := New(cfg.Logger(), conn, sensors, ch)
+	g := New(cfg.Logger(), conn, sensors, ch, nil)
 	g.Start(ctx)
 	for i := 0; i < 12+1; i++ {
 		select {
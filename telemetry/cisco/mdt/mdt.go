@@ -9,6 +9,7 @@ import (
 	"net"
 	"os"
 	"strconv"
+	"time"
 
 	mdt "github.com/cisco-ie/nx-telemetry-proto/telemetry_bis"
 	"github.com/golang/protobuf/proto"
@@ -44,15 +45,8 @@ type MDT struct {
 }
 
 // New returns new instance of NMI.
-func New(logger *zap.Logger, conn *grpc.ClientConn, sensors []*config.Sensor, outChan telemetry.ExtDSChan) telemetry.NMI {
-	var metrics = make(map[string]status.Metrics)
-
-	metrics["gRPCDataTotal"] = status.NewCounter("cisco_mdt_grpc_data_total", "")
-	metrics["dropsTotal"] = status.NewCounter("cisco_mdt_drops_total", "")
-	metrics["errorsTotal"] = status.NewCounter("cisco_mdt_errors_total", "")
-	metrics["processNSecond"] = status.NewGauge("cisco_mdt_process_nanosecond", "")
-
-	status.Register(status.Labels{"host": conn.Target()}, metrics)
+func New(logger *zap.Logger, conn *grpc.ClientConn, sensors []*config.Sensor, outChan telemetry.ExtDSChan, deviceMetrics *telemetry.DeviceMetrics) telemetry.NMI {
+	metrics := deviceMetrics.Get(newMetrics)
 
 	m := &MDT{
 		conn:       conn,
@@ -73,6 +67,18 @@ func New(logger *zap.Logger, conn *grpc.ClientConn, sensors []*config.Sensor, ou
 	return m
 }
 
+// newMetrics creates the NMI metrics.
+func newMetrics() map[string]status.Metrics {
+	var metrics = make(map[string]status.Metrics)
+
+	metrics["gRPCDataTotal"] = status.NewCounter("cisco_mdt_grpc_data_total", "")
+	metrics["dropsTotal"] = status.NewCounter("cisco_mdt_drops_total", "")
+	metrics["errorsTotal"] = status.NewCounter("cisco_mdt_errors_total", "")
+	metrics["processSeconds"] = status.NewHistogramVec("cisco_mdt_process_seconds", "", status.LatencyBuckets, "path")
+
+	return metrics
+}
+
 // Start gets stream metrics and fan-out to workers.
 func (m *MDT) Start(ctx context.Context) error {
 
@@ -131,6 +137,8 @@ func (m *MDT) worker(ctx context.Context) {
 }
 
 func (m *MDT) datastore(buf *bytes.Buffer, data []byte) error {
+	start := time.Now()
+
 	tm := &mdt.Telemetry{}
 	err := proto.Unmarshal(data, tm)
 	if err != nil {
@@ -139,6 +147,8 @@ func (m *MDT) datastore(buf *bytes.Buffer, data []byte) error {
 
 	m.handler(buf, tm)
 
+	m.metrics["processSeconds"].With(tm.GetEncodingPath()).Observe(time.Since(start).Seconds())
+
 	return nil
 }
 
@@ -6,15 +6,17 @@ package mdt
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"net"
-	"sync"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/peer"
+	grpcstatus "google.golang.org/grpc/status"
 
-	dialout "github.com/cisco-ie/nx-telemetry-proto/mdt_dialout"
+	mdtDialout "github.com/cisco-ie/nx-telemetry-proto/mdt_dialout"
 	mdt "github.com/cisco-ie/nx-telemetry-proto/telemetry_bis"
 	"github.com/golang/protobuf/proto"
 	"go.uber.org/zap"
@@ -23,42 +25,52 @@ import (
 	"github.com/yahoo/panoptes-stream/secret"
 	"github.com/yahoo/panoptes-stream/status"
 	"github.com/yahoo/panoptes-stream/telemetry"
+	"github.com/yahoo/panoptes-stream/telemetry/dialout"
 )
 
-// Dialout represents MDT dial-out.
+// dialoutService is the service name of the MDT dial-out sensors.
+const dialoutService = "cisco.mdt.dialout"
+
+// Dialout represents MDT dial-out. The peer is mapped to a configured
+// dial-out device, the device's cisco.mdt.dialout sensors route the data
+// by their subscription and the device host is the data system_id.
 type Dialout struct {
-	ctx        context.Context
-	cfg        config.Config
-	dataChan   chan []byte
-	outChan    telemetry.ExtDSChan
-	logger     *zap.Logger
-	metrics    map[string]status.Metrics
-	pathOutput map[string]string
-
-	sync.RWMutex
+	ctx      context.Context
+	cancel   context.CancelFunc
+	cfg      config.Config
+	dataChan chan dialoutData
+	outChan  telemetry.ExtDSChan
+	logger   *zap.Logger
+	metrics  map[string]status.Metrics
+	health   *status.CheckState
+	peers    *dialout.Peers
+}
+
+type dialoutData struct {
+	device config.Device
+	data   []byte
 }
 
 // NewDialout returns a new instance of MDT dial-out.
-func NewDialout(ctx context.Context, cfg config.Config, outChan telemetry.ExtDSChan) *Dialout {
+func NewDialout(ctx context.Context, cfg config.Config, outChan telemetry.ExtDSChan, subscriber dialout.Subscriber) *Dialout {
 	var metrics = make(map[string]status.Metrics)
 
 	metrics["dropsTotal"] = status.NewCounter("cisco_mdt_drops_total", "")
+	metrics["rejectsTotal"] = status.NewCounter("cisco_mdt_dialout_rejects_total", "Rejected MDT dial-out peers")
 
 	status.Register(status.Labels{}, metrics)
 
 	m := &Dialout{
-		ctx:        ctx,
-		cfg:        cfg,
-		outChan:    outChan,
-		logger:     cfg.Logger(),
-		dataChan:   make(chan []byte, 1000),
-		pathOutput: make(map[string]string),
-		metrics:    metrics,
+		cfg:      cfg,
+		outChan:  outChan,
+		logger:   config.NamedLogger(cfg.Logger(), "dialout.cisco.mdt"),
+		dataChan: make(chan dialoutData, 1000),
+		metrics:  metrics,
+		health:   status.NewCheckState(errors.New("not started")),
+		peers:    dialout.NewPeers(cfg, subscriber),
 	}
 
-	for _, sensor := range cfg.Sensors() {
-		m.pathOutput[sensor.Subscription] = sensor.Output
-	}
+	m.ctx, m.cancel = context.WithCancel(ctx)
 
 	return m
 }
@@ -71,7 +83,14 @@ func (m *Dialout) Start() error {
 	conf := m.cfg.Global().Dialout.Services["cisco.mdt"]
 
 	if conf.Addr == "" {
-		return errors.New("address is empty")
+		err := errors.New("address is empty")
+		m.health.Set(err)
+		return err
+	}
+
+	if err := m.peers.Update(); err != nil {
+		m.health.Set(err)
+		return err
 	}
 
 	if conf.Workers < 1 {
@@ -84,12 +103,15 @@ func (m *Dialout) Start() error {
 
 	ln, err := net.Listen("tcp", conf.Addr)
 	if err != nil {
+		m.health.Set(err)
 		return err
 	}
 
 	if tlsConf.Enabled {
 		tlsConfig, err := secret.GetTLSServerConfig(&tlsConf)
 		if err != nil {
+			ln.Close()
+			m.health.Set(err)
 			return err
 		}
 
@@ -98,56 +120,106 @@ func (m *Dialout) Start() error {
 	}
 
 	srv := grpc.NewServer(grpcSrvOpts...)
-	dialout.RegisterGRPCMdtDialoutServer(srv, m)
-	go srv.Serve(ln)
+	mdtDialout.RegisterGRPCMdtDialoutServer(srv, m)
+
+	go func() {
+		if err := srv.Serve(ln); err != nil {
+			m.health.Set(err)
+		}
+	}()
 
+	go func() {
+		<-m.ctx.Done()
+		srv.Stop()
+	}()
+
+	m.health.Set(nil)
 	m.logger.Info("cisco.mdt.dialout", zap.String("address", conf.Addr), zap.Bool("tls", m.cfg.Global().Dialout.TLSConfig.Enabled))
 
 	return nil
 }
 
-// Update updates path to output once the configuration changed.
-func (m *Dialout) Update() {
-	m.Lock()
-	defer m.Unlock()
+// Stop stops the gRPC server and the workers, the metrics are
+// unregistered since a restarted service registers its own.
+func (m *Dialout) Stop() {
+	m.cancel()
+	m.health.Set(errors.New("stopped"))
+	status.Unregister(status.Labels{}, m.metrics)
+}
+
+// Health returns error if the gRPC server isn't serving.
+func (m *Dialout) Health() error {
+	return m.health.Check()
+}
 
-	m.pathOutput = make(map[string]string)
-	for _, sensor := range m.cfg.Sensors() {
-		m.pathOutput[sensor.Subscription] = sensor.Output
+// Update updates the dial-out peers once the configuration changed.
+func (m *Dialout) Update() {
+	if err := m.peers.Update(); err != nil {
+		m.logger.Error("cisco.mdt.dialout", zap.Error(err))
 	}
 }
 
-// MdtDialout gets stream metrics and fan-out to workers.
-func (m *Dialout) MdtDialout(stream dialout.GRPCMdtDialout_MdtDialoutServer) error {
+// MdtDialout authenticates the peer, gets stream metrics and fan-out to workers.
+func (m *Dialout) MdtDialout(stream mdtDialout.GRPCMdtDialout_MdtDialoutServer) error {
 	var buf *bytes.Buffer
 
 	p, ok := peer.FromContext(stream.Context())
-
 	if !ok {
-		m.logger.Warn("cisco.mdt.dialout", zap.String("event", "connect"), zap.String("host", "peer address is unavailable"))
-	} else {
-		m.logger.Info("cisco.mtd.dialout", zap.String("event", "connect"), zap.String("peer", p.Addr.String()))
+		m.metrics["rejectsTotal"].Inc()
+		m.logger.Warn("cisco.mdt.dialout", zap.String("event", "reject"), zap.String("reason", "peer address is unavailable"))
+		return grpcstatus.Error(codes.Unauthenticated, "peer address is unavailable")
+	}
+
+	device, err := m.identify(p)
+	if err != nil {
+		return err
 	}
 
+	m.logger.Info("cisco.mdt.dialout", zap.String("event", "connect"), zap.String("host", device.Host), zap.String("peer", p.Addr.String()))
+
 	for {
 		dialoutArgs, err := stream.Recv()
 		if err != nil {
 			return err
 		}
 
+		// the device might be changed, removed or owned by another shard
+		device, err = m.identify(p)
+		if err != nil {
+			return err
+		}
+
 		if dialoutArgs.TotalSize == 0 {
-			m.dataChan <- dialoutArgs.Data
+			m.dataChan <- dialoutData{device: device, data: dialoutArgs.Data}
 			continue
 		}
 
 		buf.Write(dialoutArgs.Data)
 		if int32(buf.Len()) >= dialoutArgs.TotalSize {
-			m.dataChan <- dialoutArgs.Data
+			m.dataChan <- dialoutData{device: device, data: dialoutArgs.Data}
 			buf.Reset()
 		}
 	}
 }
 
+// identify returns the dial-out device of the peer, the unknown peers are rejected.
+func (m *Dialout) identify(p *peer.Peer) (config.Device, error) {
+	var state *tls.ConnectionState
+
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		state = &info.State
+	}
+
+	device, err := m.peers.Identify(p.Addr, state)
+	if err != nil {
+		m.metrics["rejectsTotal"].Inc()
+		m.logger.Warn("cisco.mdt.dialout", zap.String("event", "reject"), zap.String("peer", p.Addr.String()), zap.Error(err))
+		return device, grpcstatus.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return device, nil
+}
+
 func (m *Dialout) worker() {
 	var buf = new(bytes.Buffer)
 	for {
@@ -157,7 +229,7 @@ func (m *Dialout) worker() {
 				return
 			}
 
-			if err := m.datastore(buf, d); err != nil {
+			if err := m.datastore(buf, d.device, d.data); err != nil {
 				m.logger.Error("cisco.mdt.dialout", zap.Error(err))
 			}
 
@@ -167,19 +239,19 @@ func (m *Dialout) worker() {
 	}
 }
 
-func (m *Dialout) datastore(buf *bytes.Buffer, data []byte) error {
+func (m *Dialout) datastore(buf *bytes.Buffer, device config.Device, data []byte) error {
 	tm := &mdt.Telemetry{}
 	err := proto.Unmarshal(data, tm)
 	if err != nil {
 		return err
 	}
 
-	m.handler(buf, tm)
+	m.handler(buf, device, tm)
 
 	return nil
 }
 
-func (m *Dialout) handler(buf *bytes.Buffer, tm *mdt.Telemetry) {
+func (m *Dialout) handler(buf *bytes.Buffer, device config.Device, tm *mdt.Telemetry) {
 	var (
 		prefix, output string
 		timestamp      uint64
@@ -187,9 +259,10 @@ func (m *Dialout) handler(buf *bytes.Buffer, tm *mdt.Telemetry) {
 	)
 
 	for _, gpbkv := range tm.DataGpbkv {
-		output, err = m.getOutput(tm.GetSubscriptionIdStr())
+		output, err = m.getOutput(device, tm.GetSubscriptionIdStr())
 		if err != nil {
-			m.logger.Error("cisco.mdt.dialout", zap.Error(err))
+			m.logger.Error("cisco.mdt.dialout", zap.String("host", device.Host), zap.Error(err))
+			continue
 		}
 
 		timestamp = getTimestamp(gpbkv.Timestamp, tm.MsgTimestamp)
@@ -230,7 +303,7 @@ func (m *Dialout) handler(buf *bytes.Buffer, tm *mdt.Telemetry) {
 				"prefix":    prefix,
 				"labels":    labels,
 				"timestamp": timestamp,
-				"system_id": tm.GetNodeIdStr(),
+				"system_id": device.Host,
 				"key":       key,
 				"value":     value,
 			}
@@ -250,15 +323,17 @@ func (m *Dialout) handler(buf *bytes.Buffer, tm *mdt.Telemetry) {
 	}
 }
 
-func (m *Dialout) getOutput(sub string) (string, error) {
-	if m.cfg.Global().Dialout.DefaultOutput != "" {
-		return m.cfg.Global().Dialout.DefaultOutput, nil
+// getOutput returns the output of the device sensor that has the subscription,
+// otherwise the dial-out default output.
+func (m *Dialout) getOutput(device config.Device, sub string) (string, error) {
+	for _, sensor := range device.Sensors[dialoutService] {
+		if sensor.Subscription == sub {
+			return sensor.Output, nil
+		}
 	}
 
-	m.RLock()
-	defer m.RUnlock()
-	if output, ok := m.pathOutput[sub]; ok {
-		return output, nil
+	if m.cfg.Global().Dialout.DefaultOutput != "" {
+		return m.cfg.Global().Dialout.DefaultOutput, nil
 	}
 
 	return "", errors.New("output not found")
@@ -6,16 +6,39 @@ import (
 	"testing"
 	"time"
 
-	dialout "github.com/cisco-ie/nx-telemetry-proto/mdt_dialout"
+	mdtDialout "github.com/cisco-ie/nx-telemetry-proto/mdt_dialout"
 	"github.com/golang/protobuf/proto"
+	gnmi "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
 
 	"github.com/yahoo/panoptes-stream/config"
 	"github.com/yahoo/panoptes-stream/telemetry"
 	"github.com/yahoo/panoptes-stream/telemetry/mock"
 )
 
+type subscriber struct {
+	devices []config.Device
+}
+
+func (s *subscriber) Serve(ctx context.Context, device config.Device, updates <-chan *gnmi.SubscribeResponse) error {
+	return nil
+}
+
+func (s *subscriber) GetDialoutDevices() []config.Device {
+	return s.devices
+}
+
+var dialoutDevice = config.Device{
+	DeviceConfig: config.DeviceConfig{Host: "127.0.0.1", Dialout: true},
+	Sensors: map[string][]*config.Sensor{
+		"cisco.mdt.dialout": {{Service: "cisco.mdt.dialout", Subscription: "Sub3", Output: "console::stdout"}},
+	},
+}
+
 func TestDialoutStart(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -31,26 +54,61 @@ func TestDialoutStart(t *testing.T) {
 		},
 	}
 
-	d := NewDialout(ctx, cfg, ch)
+	d := NewDialout(ctx, cfg, ch, &subscriber{devices: []config.Device{dialoutDevice}})
 	go d.Start()
 	time.Sleep(time.Second)
 
 	conn, err := grpc.DialContext(ctx, "127.0.0.1:50051", grpc.WithInsecure())
 	assert.NoError(t, err)
-	mdtDialoutClient := dialout.NewGRPCMdtDialoutClient(conn)
-	mdtDialout, err := mdtDialoutClient.MdtDialout(ctx)
+	mdtDialoutClient := mdtDialout.NewGRPCMdtDialoutClient(conn)
+	stream, err := mdtDialoutClient.MdtDialout(ctx)
 	assert.NoError(t, err)
 
 	tm := mock.MDTInterfaceII()
 	b, err := proto.Marshal(tm)
 	assert.NoError(t, err)
-	mdtDialout.Send(&dialout.MdtDialoutArgs{ReqId: 1, Data: b})
+	stream.Send(&mdtDialout.MdtDialoutArgs{ReqId: 1, Data: b})
 	time.Sleep(time.Second)
 	r := <-ch
+	assert.Equal(t, "127.0.0.1", r.DS["system_id"])
+	assert.Equal(t, "console::stdout", r.Output)
 	labels := r.DS["labels"].(map[string]string)
 	assert.Equal(t, "Sub3", labels["subscriptionId"])
 	assert.Equal(t, "ios", labels["nodeId"])
 	assert.Equal(t, "openconfig-interfaces:interfaces/interface", labels["path"])
+	assert.NoError(t, d.Health())
+
+	d.Stop()
+	assert.EqualError(t, d.Health(), "stopped")
+}
+
+func TestDialoutReject(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := config.NewMockConfig()
+
+	cfg.Global().Dialout = config.Dialout{
+		Services: map[string]config.DialoutService{
+			"cisco.mdt": {
+				Addr:    "127.0.0.1:50052",
+				Workers: 1,
+			},
+		},
+	}
+
+	d := NewDialout(ctx, cfg, make(telemetry.ExtDSChan, 1), &subscriber{})
+	assert.NoError(t, d.Start())
+
+	conn, err := grpc.DialContext(ctx, "127.0.0.1:50052", grpc.WithInsecure())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	stream, err := mdtDialout.NewGRPCMdtDialoutClient(conn).MdtDialout(ctx)
+	assert.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.PermissionDenied, grpcstatus.Code(err))
+	assert.Equal(t, uint64(1), d.metrics["rejectsTotal"].Get())
 }
 
 func TestDialoutHandler(t *testing.T) {
@@ -58,13 +116,12 @@ func TestDialoutHandler(t *testing.T) {
 	ch := make(telemetry.ExtDSChan, 10)
 
 	m := &Dialout{
-		cfg:        config.NewMockConfig(),
-		pathOutput: map[string]string{"Sub3": "console::stdout"},
-		outChan:    ch,
+		cfg:     config.NewMockConfig(),
+		outChan: ch,
 	}
 
 	tm := mock.MDTInterfaceII()
-	m.handler(buf, tm)
+	m.handler(buf, dialoutDevice, tm)
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
@@ -109,7 +166,7 @@ func TestDialoutHandler(t *testing.T) {
 			assert.Equal(t, "console::stdout", r.Output)
 			assert.Equal(t, exp.value, r.DS["value"])
 			assert.Equal(t, exp.labels, r.DS["labels"])
-			assert.Equal(t, "ios", r.DS["system_id"])
+			assert.Equal(t, "127.0.0.1", r.DS["system_id"])
 			assert.Equal(t, exp.timestamp, r.DS["timestamp"])
 
 		case <-ctx.Done():
@@ -119,18 +176,46 @@ func TestDialoutHandler(t *testing.T) {
 	}
 }
 
-func TestUpdate(t *testing.T) {
+func TestGetOutput(t *testing.T) {
 	cfg := config.NewMockConfig()
-	m := &Dialout{
-		cfg:        cfg,
-		pathOutput: map[string]string{"Sub3": "console::stdout"},
-	}
+	m := &Dialout{cfg: cfg}
 
-	cfg.MSensors = []config.Sensor{
-		{Subscription: "Sub2", Output: "test"},
+	output, err := m.getOutput(dialoutDevice, "Sub3")
+	assert.NoError(t, err)
+	assert.Equal(t, "console::stdout", output)
+
+	_, err = m.getOutput(dialoutDevice, "Sub2")
+	assert.Error(t, err)
+
+	cfg.MGlobal.Dialout.DefaultOutput = "kafka1::test"
+	output, err = m.getOutput(dialoutDevice, "Sub2")
+	assert.NoError(t, err)
+	assert.Equal(t, "kafka1::test", output)
+}
+
+func TestDialoutRestartMetrics(t *testing.T) {
+	var rejects = func() float64 {
+		mfs, err := prometheus.DefaultGatherer.Gather()
+		assert.NoError(t, err)
+		for _, mf := range mfs {
+			if mf.GetName() == "panoptes_cisco_mdt_dialout_rejects_total" {
+				return mf.Metric[0].GetCounter().GetValue()
+			}
+		}
+		return -1
 	}
 
-	m.Update()
+	cfg := config.NewMockConfig()
+	d := NewDialout(context.Background(), cfg, make(telemetry.ExtDSChan, 1), &subscriber{})
+	d.metrics["rejectsTotal"].Inc()
+	assert.Equal(t, float64(1), rejects())
+
+	d.Stop()
+	assert.Equal(t, float64(-1), rejects())
+
+	// the restarted service exports its own metrics
+	d = NewDialout(context.Background(), cfg, make(telemetry.ExtDSChan, 1), &subscriber{})
+	assert.Equal(t, float64(0), rejects())
 
-	assert.Equal(t, map[string]string{"Sub2": "test"}, m.pathOutput)
+	d.Stop()
 }
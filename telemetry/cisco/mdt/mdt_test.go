@@ -112,7 +112,7 @@ func TestMDTMockServer(t *testing.T) {
 		Output:       "test",
 	})
 
-	m := New(cfg.Logger(), conn, sensors, ch)
+	m := New(cfg.Logger(), conn, sensors, ch, nil)
 	m.Start(ctx)
 
 	time.Sleep(time.Second)
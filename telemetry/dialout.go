@@ -0,0 +1,281 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/yahoo/panoptes-stream/config"
+)
+
+// relayBufferSize is the in-process relay connection buffer size.
+const relayBufferSize = 1024 * 1024
+
+// session represents a dial-out device session.
+type session struct {
+	device config.Device
+	states map[string]*serviceState
+}
+
+// sessionStream records the received data time of a dial-out session stream.
+type sessionStream struct {
+	grpc.ClientStream
+	session *session
+}
+
+// relay serves the published updates of a dial-out device to a service
+// NMI as a gNMI subscription over an in-process connection, so the vendor
+// NMIs decode the dial-out updates the same way as the dial-in ones.
+type relay struct {
+	gpb.UnimplementedGNMIServer
+
+	ln     *bufconn.Listener
+	server *grpc.Server
+	ch     chan *gpb.SubscribeResponse
+	done   chan struct{}
+	once   sync.Once
+}
+
+// GetDialoutDevices returns the devices that initiate the connection
+// based on the filters (if exist).
+func (t *Telemetry) GetDialoutDevices() []config.Device {
+	var devices []config.Device
+
+	for _, device := range t.cfg.Devices() {
+		if device.Dialout && t.isOwned(device) {
+			devices = append(devices, device)
+		}
+	}
+
+	return devices
+}
+
+// Serve runs the device service over the updates that the device publishes
+// (gNMI dial-out), the subscriptions are configured on the device and the
+// dial-out device has a single service (see config.DeviceValidation). It returns
+// once the updates channel is closed and all of the services are terminated
+// or the context is canceled.
+func (t *Telemetry) Serve(ctx context.Context, device config.Device, updates <-chan *gpb.SubscribeResponse) error {
+	var (
+		wg     sync.WaitGroup
+		relays []*relay
+		target = net.JoinHostPort(device.Host, strconv.Itoa(device.Port))
+	)
+
+	s := &session{device: device, states: make(map[string]*serviceState)}
+	for service, sensors := range device.Sensors {
+		if _, ok := t.telemetryRegistrar.GetNMIFactory(service); !ok {
+			t.logger.Warn("dialout", zap.String("error", "service not exist"), zap.String("host", device.Host), zap.String("service", service))
+			continue
+		}
+
+		s.states[service] = newServiceState(service, sensors)
+	}
+
+	if len(s.states) < 1 {
+		return errors.New("no service available")
+	}
+
+	t.mu.Lock()
+	t.sessions[device.Host] = s
+	t.states[device.Host] = s.states
+	t.mu.Unlock()
+
+	defer func() {
+		t.mu.Lock()
+		if t.sessions[device.Host] == s {
+			delete(t.sessions, device.Host)
+			delete(t.states, device.Host)
+		}
+		t.mu.Unlock()
+
+		if !t.isDialoutDevice(device.Host) {
+			t.deviceMetrics.unregister(device.Host)
+		}
+	}()
+
+	t.metrics["gRPConnCurrent"].Inc()
+	defer t.metrics["gRPConnCurrent"].Dec()
+
+	t.logger.Info("dialout", zap.String("event", "connect"), zap.String("host", device.Host))
+
+	for service, state := range s.states {
+		r := newRelay()
+		defer r.stop()
+
+		gConn, err := r.dial(ctx, target, s.streamInterceptor)
+		if err != nil {
+			return err
+		}
+		defer gConn.Close()
+
+		relays = append(relays, r)
+
+		wg.Add(1)
+		go func(service string, state *serviceState, r *relay) {
+			defer wg.Done()
+			defer r.close()
+
+			state.setConnected()
+
+			new, _ := t.telemetryRegistrar.GetNMIFactory(service)
+			logger := config.DeviceLogger(config.NamedLogger(t.logger, service), device.Host)
+			deviceMetrics := t.deviceMetrics.get(device.Host, service, device.Host)
+			nmi := new(logger, gConn, device.Sensors[service], t.outChan, deviceMetrics)
+			err := nmi.Start(ctx)
+
+			state.setDisconnected(err)
+
+			if err != nil && ctx.Err() == nil {
+				t.logger.Warn("dialout", zap.String("event", "nmi"), zap.Error(err), zap.String("host", device.Host), zap.String("service", service))
+			}
+		}(service, state, r)
+	}
+
+	go func() {
+		defer func() {
+			for _, r := range relays {
+				r.end()
+			}
+		}()
+
+		for {
+			select {
+			case resp, ok := <-updates:
+				if !ok {
+					return
+				}
+
+				for _, r := range relays {
+					r.send(ctx, resp)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+
+	t.logger.Info("dialout", zap.String("event", "terminate"), zap.String("host", device.Host))
+
+	return nil
+}
+
+func newRelay() *relay {
+	r := &relay{
+		ln:     bufconn.Listen(relayBufferSize),
+		server: grpc.NewServer(),
+		ch:     make(chan *gpb.SubscribeResponse, 100),
+		done:   make(chan struct{}),
+	}
+
+	gpb.RegisterGNMIServer(r.server, r)
+	go r.server.Serve(r.ln)
+
+	return r
+}
+
+// dial returns the relay client connection, the target is the device address
+// that the NMIs take as the data system_id.
+func (r *relay) dial(ctx context.Context, target string, interceptor grpc.StreamClientInterceptor) (*grpc.ClientConn, error) {
+	return grpc.DialContext(ctx, target,
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return r.ln.Dial()
+		}),
+		grpc.WithInsecure(),
+		grpc.WithUserAgent("Panoptes"),
+		grpc.WithStreamInterceptor(interceptor),
+	)
+}
+
+// Subscribe streams the published updates, the subscription request
+// is ignored since the device has the subscriptions.
+func (r *relay) Subscribe(stream gpb.GNMI_SubscribeServer) error {
+	if _, err := stream.Recv(); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case resp, ok := <-r.ch:
+			if !ok {
+				return nil
+			}
+
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		case <-r.done:
+			return nil
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// send sends the update unless the service has been terminated.
+func (r *relay) send(ctx context.Context, resp *gpb.SubscribeResponse) {
+	select {
+	case r.ch <- resp:
+	case <-r.done:
+	case <-ctx.Done():
+	}
+}
+
+// end ends the subscription once the queued updates are sent.
+func (r *relay) end() {
+	close(r.ch)
+}
+
+// close releases the relay once its service terminated.
+func (r *relay) close() {
+	r.once.Do(func() { close(r.done) })
+}
+
+func (r *relay) stop() {
+	r.close()
+	r.server.Stop()
+}
+
+// streamInterceptor records the received data time of all of the session services
+// since they share the published updates.
+func (s *session) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sessionStream{ClientStream: cs, session: s}, nil
+}
+
+func (s *sessionStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		for _, state := range s.session.states {
+			state.received()
+		}
+	}
+
+	return err
+}
+
+func (t *Telemetry) isDialoutDevice(host string) bool {
+	for _, device := range t.GetDialoutDevices() {
+		if device.Host == host {
+			return true
+		}
+	}
+
+	return false
+}
@@ -5,45 +5,128 @@ package dialout
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
 
 	"github.com/yahoo/panoptes-stream/config"
 	"github.com/yahoo/panoptes-stream/telemetry"
-	"github.com/yahoo/panoptes-stream/telemetry/cisco/mdt"
 )
 
 // Dialout represents dial-out mode for all telemetries.
 type Dialout struct {
-	cfg     config.Config
-	ctx     context.Context
-	outChan telemetry.ExtDSChan
+	ctx        context.Context
+	cfg        config.Config
+	logger     *zap.Logger
+	registrar  *Registrar
+	outChan    telemetry.ExtDSChan
+	subscriber Subscriber
 
-	mdtHandler *mdt.Dialout
+	sync.Mutex
+	services map[string]Service
 }
 
-// New creates a new dialout instance.
-func New(ctx context.Context, cfg config.Config, outChan telemetry.ExtDSChan) *Dialout {
+// New creates a new dialout instance, the subscriber runs
+// the gNMI subscriptions of the gNMI dial-out devices.
+func New(ctx context.Context, cfg config.Config, registrar *Registrar, outChan telemetry.ExtDSChan, subscriber Subscriber) *Dialout {
 	return &Dialout{
-		cfg:     cfg,
-		ctx:     ctx,
-		outChan: outChan,
+		ctx:        ctx,
+		cfg:        cfg,
+		logger:     config.NamedLogger(cfg.Logger(), "dialout"),
+		registrar:  registrar,
+		outChan:    outChan,
+		subscriber: subscriber,
+		services:   make(map[string]Service),
 	}
 }
 
-// Start starts available dialout telemetries.
+// Start starts the configured dial-out services.
 func (d *Dialout) Start() {
-	for service := range d.cfg.Global().Dialout.Services {
-		if service == "cisco.mdt" {
-			d.mdtHandler = mdt.NewDialout(d.ctx, d.cfg, d.outChan)
-			d.mdtHandler.Start()
-		}
+	d.Lock()
+	defer d.Unlock()
+
+	for name := range d.cfg.Global().Dialout.Services {
+		d.start(name)
 	}
 }
 
-// Update updates dialout telemetries once configuration changed.
+// Update starts the added services, stops the removed
+// services and updates the rest once configuration changed.
 func (d *Dialout) Update() {
-	for service := range d.cfg.Global().Dialout.Services {
-		if service == "cisco.mdt" {
-			d.mdtHandler.Update()
+	d.Lock()
+	defer d.Unlock()
+
+	services := d.cfg.Global().Dialout.Services
+
+	for name, s := range d.services {
+		if _, ok := services[name]; !ok {
+			d.logger.Info("dialout", zap.String("event", "stop"), zap.String("service", name))
+			s.Stop()
+			delete(d.services, name)
+		}
+	}
+
+	for name := range services {
+		if s, ok := d.services[name]; ok {
+			s.Update()
+			continue
+		}
+
+		d.start(name)
+	}
+}
+
+// Stop stops all of the dial-out services.
+func (d *Dialout) Stop() {
+	d.Lock()
+	defer d.Unlock()
+
+	for name, s := range d.services {
+		s.Stop()
+		delete(d.services, name)
+	}
+}
+
+// Validate returns error if any of the configured services isn't registered.
+func (d *Dialout) Validate() error {
+	return d.registrar.Validate(d.cfg.Global().Dialout.Services)
+}
+
+// Check returns the first unhealthy service error, it's a readiness check.
+func (d *Dialout) Check() error {
+	d.Lock()
+	defer d.Unlock()
+
+	var names []string
+	for name := range d.services {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := d.services[name].Health(); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
 		}
 	}
+
+	return nil
+}
+
+func (d *Dialout) start(name string) {
+	new, ok := d.registrar.GetFactory(name)
+	if !ok {
+		d.logger.Error("dialout", zap.String("error", "service not available"), zap.String("service", name))
+		return
+	}
+
+	s := new(d.ctx, d.cfg, d.outChan, d.subscriber)
+	if err := s.Start(); err != nil {
+		d.logger.Error("dialout", zap.String("event", "start"), zap.String("service", name), zap.Error(err))
+	}
+
+	// the failed service is kept to report its health
+	d.services[name] = s
 }
@@ -0,0 +1,82 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package dialout
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+type testService struct {
+	started, updated, stopped int
+	err                       error
+}
+
+var testServices []*testService
+
+func (s *testService) Start() error {
+	s.started++
+	return s.err
+}
+
+func (s *testService) Update() { s.updated++ }
+
+func (s *testService) Stop() { s.stopped++ }
+
+func (s *testService) Health() error { return s.err }
+
+func testServiceNew(ctx context.Context, cfg config.Config, outChan telemetry.ExtDSChan, subscriber Subscriber) Service {
+	s := &testService{}
+	testServices = append(testServices, s)
+	return s
+}
+
+func testFailedServiceNew(ctx context.Context, cfg config.Config, outChan telemetry.ExtDSChan, subscriber Subscriber) Service {
+	return &testService{err: errors.New("address is empty")}
+}
+
+func TestDialout(t *testing.T) {
+	testServices = nil
+
+	cfg := &config.MockConfig{MGlobal: &config.Global{}}
+	cfg.MGlobal.Dialout.Services = map[string]config.DialoutService{"test": {}, "unknown": {}}
+
+	r := NewRegistrar(zap.NewNop())
+	r.Register("test", "0.0.0", testServiceNew)
+	r.Register("failed", "0.0.0", testFailedServiceNew)
+
+	d := New(context.Background(), cfg, r, nil, nil)
+	d.logger = zap.NewNop()
+
+	assert.Error(t, d.Validate())
+
+	d.Start()
+	assert.Len(t, d.services, 1)
+	assert.Equal(t, 1, testServices[0].started)
+	assert.NoError(t, d.Check())
+
+	// the failed service is added
+	cfg.MGlobal.Dialout.Services = map[string]config.DialoutService{"test": {}, "failed": {}}
+	d.Update()
+	assert.Len(t, d.services, 2)
+	assert.Equal(t, 1, testServices[0].updated)
+	assert.EqualError(t, d.Check(), "failed: address is empty")
+
+	// the test service is removed
+	cfg.MGlobal.Dialout.Services = map[string]config.DialoutService{"failed": {}}
+	d.Update()
+	assert.Len(t, d.services, 1)
+	assert.Equal(t, 1, testServices[0].stopped)
+
+	d.Stop()
+	assert.Len(t, d.services, 0)
+	assert.NoError(t, d.Check())
+}
@@ -0,0 +1,294 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package gnmi
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"sync"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	grpcstatus "google.golang.org/grpc/status"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/secret"
+	"github.com/yahoo/panoptes-stream/status"
+	"github.com/yahoo/panoptes-stream/telemetry"
+	"github.com/yahoo/panoptes-stream/telemetry/dialout"
+)
+
+var (
+	gnmiVersion = "0.0.1"
+
+	errSessionTerminated = grpcstatus.Error(codes.Unavailable, "session terminated")
+)
+
+// publishServiceDesc is the gNMI dial-out service of the Nokia SR OS sros_dialout.proto,
+// the devices publish gnmi.SubscribeResponse over the Publish stream and the
+// PublishResponse is an empty message that it's never sent.
+var publishServiceDesc = grpc.ServiceDesc{
+	ServiceName: "Nokia.SROS.DialoutTelemetry",
+	HandlerType: (*interface{})(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Publish",
+			Handler:       publishHandler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "sros_dialout.proto",
+}
+
+// Dialout represents gNMI dial-out, the devices initiate the connection
+// (e.g. behind NAT or firewall) and publish their configured subscriptions
+// over the Publish stream. The device is identified by its verified TLS
+// client certificate (common name or SAN) or its source address, see dialout.Peers.
+type Dialout struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	cfg        config.Config
+	logger     *zap.Logger
+	subscriber dialout.Subscriber
+	metrics    map[string]status.Metrics
+	health     *status.CheckState
+	peers      *dialout.Peers
+
+	sync.Mutex
+	sessions map[string]*session
+}
+
+type session struct {
+	device config.Device
+	addr   net.Addr
+	state  *tls.ConnectionState
+	cancel context.CancelFunc
+}
+
+// NewDialout returns a new instance of gNMI dial-out.
+func NewDialout(ctx context.Context, cfg config.Config, subscriber dialout.Subscriber) *Dialout {
+	var metrics = make(map[string]status.Metrics)
+
+	metrics["sessionsCurrent"] = status.NewGauge("gnmi_dialout_sessions", "Active gNMI dial-out sessions")
+	metrics["rejectsTotal"] = status.NewCounter("gnmi_dialout_rejects_total", "Rejected gNMI dial-out connections")
+	metrics["updatesTotal"] = status.NewCounter("gnmi_dialout_updates_total", "Published gNMI dial-out updates")
+
+	status.Register(status.Labels{}, metrics)
+
+	d := &Dialout{
+		cfg:        cfg,
+		logger:     config.NamedLogger(cfg.Logger(), "dialout.gnmi"),
+		subscriber: subscriber,
+		metrics:    metrics,
+		health:     status.NewCheckState(errors.New("not started")),
+		peers:      dialout.NewPeers(cfg, subscriber),
+		sessions:   make(map[string]*session),
+	}
+
+	d.ctx, d.cancel = context.WithCancel(ctx)
+
+	return d
+}
+
+// Start starts the dial-out gRPC server.
+func (d *Dialout) Start() error {
+	var opts []grpc.ServerOption
+
+	tlsConf := d.cfg.Global().Dialout.TLSConfig
+	conf := d.cfg.Global().Dialout.Services["gnmi"]
+
+	if conf.Addr == "" {
+		err := errors.New("address is empty")
+		d.health.Set(err)
+		return err
+	}
+
+	if err := d.peers.Update(); err != nil {
+		d.health.Set(err)
+		return err
+	}
+
+	if tlsConf.Enabled {
+		tlsConfig, err := secret.GetTLSServerConfig(&tlsConf)
+		if err != nil {
+			d.health.Set(err)
+			return err
+		}
+
+		// the client certificate identifies the device
+		if tlsConfig.RootCAs != nil {
+			tlsConfig.ClientCAs = tlsConfig.RootCAs
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	ln, err := net.Listen("tcp", conf.Addr)
+	if err != nil {
+		d.health.Set(err)
+		return err
+	}
+
+	gServer := grpc.NewServer(opts...)
+	gServer.RegisterService(&publishServiceDesc, d)
+
+	go func() {
+		<-d.ctx.Done()
+		gServer.Stop()
+	}()
+
+	go func() {
+		if err := gServer.Serve(ln); err != nil && d.ctx.Err() == nil {
+			d.logger.Error("gnmi.dialout", zap.Error(err))
+			d.health.Set(err)
+		}
+	}()
+
+	d.health.Set(nil)
+	d.logger.Info("gnmi.dialout", zap.String("address", conf.Addr), zap.Bool("tls", tlsConf.Enabled))
+
+	return nil
+}
+
+// Stop stops the dial-out gRPC server and terminates the sessions, the
+// metrics are unregistered since a restarted service registers its own.
+func (d *Dialout) Stop() {
+	d.cancel()
+	d.health.Set(errors.New("stopped"))
+	status.Unregister(status.Labels{}, d.metrics)
+}
+
+// Health returns error if the dial-out gRPC server isn't serving.
+func (d *Dialout) Health() error {
+	return d.health.Check()
+}
+
+// Update updates the dial-out peers once the configuration changed, the sessions
+// of the changed, removed or no longer allowed devices are terminated.
+func (d *Dialout) Update() {
+	if err := d.peers.Update(); err != nil {
+		d.logger.Error("gnmi.dialout", zap.Error(err))
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	for host, s := range d.sessions {
+		if device, err := d.peers.Identify(s.addr, s.state); err != nil || !reflect.DeepEqual(device, s.device) {
+			d.logger.Info("gnmi.dialout", zap.String("event", "terminate"), zap.String("host", host), zap.String("reason", "config changed"))
+			s.cancel()
+		}
+	}
+}
+
+func publishHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(*Dialout).publish(stream)
+}
+
+// publish identifies the device and runs its services over the published
+// updates until the device closes the stream or the session is terminated.
+func (d *Dialout) publish(stream grpc.ServerStream) error {
+	var state *tls.ConnectionState
+
+	p, ok := peer.FromContext(stream.Context())
+	if !ok {
+		return grpcstatus.Error(codes.Internal, "peer not found")
+	}
+
+	if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		state = &tlsInfo.State
+	}
+
+	device, err := d.peers.Identify(p.Addr, state)
+	if err != nil {
+		d.metrics["rejectsTotal"].Inc()
+		d.logger.Warn("gnmi.dialout", zap.String("event", "reject"), zap.String("peer", p.Addr.String()), zap.Error(err))
+		return grpcstatus.Error(codes.PermissionDenied, err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(d.ctx)
+	defer cancel()
+
+	s := &session{device: device, addr: p.Addr, state: state, cancel: cancel}
+
+	d.Lock()
+	if old, ok := d.sessions[device.Host]; ok {
+		old.cancel()
+	}
+	d.sessions[device.Host] = s
+	d.Unlock()
+
+	d.metrics["sessionsCurrent"].Inc()
+
+	defer func() {
+		d.Lock()
+		if d.sessions[device.Host] == s {
+			delete(d.sessions, device.Host)
+		}
+		d.Unlock()
+
+		d.metrics["sessionsCurrent"].Dec()
+	}()
+
+	updates := make(chan *gpb.SubscribeResponse, 100)
+	go d.recv(ctx, stream, device, updates)
+
+	err = d.subscriber.Serve(ctx, device, updates)
+	if err != nil {
+		d.logger.Error("gnmi.dialout", zap.String("host", device.Host), zap.Error(err))
+		return grpcstatus.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	if ctx.Err() != nil && stream.Context().Err() == nil {
+		return errSessionTerminated
+	}
+
+	return nil
+}
+
+// recv receives the published updates, the updates channel
+// is closed once the device closed the stream.
+func (d *Dialout) recv(ctx context.Context, stream grpc.ServerStream, device config.Device, updates chan<- *gpb.SubscribeResponse) {
+	defer close(updates)
+
+	for {
+		resp := &gpb.SubscribeResponse{}
+		if err := stream.RecvMsg(resp); err != nil {
+			if err != io.EOF && ctx.Err() == nil {
+				d.logger.Warn("gnmi.dialout", zap.String("host", device.Host), zap.Error(err))
+			}
+			return
+		}
+
+		d.metrics["updatesTotal"].Inc()
+
+		select {
+		case updates <- resp:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Register registers gNMI dial-out.
+func Register(r *dialout.Registrar) {
+	r.Register("gnmi", Version(), func(ctx context.Context, cfg config.Config, _ telemetry.ExtDSChan, subscriber dialout.Subscriber) dialout.Service {
+		return NewDialout(ctx, cfg, subscriber)
+	})
+}
+
+// Version returns version
+func Version() string {
+	return gnmiVersion
+}
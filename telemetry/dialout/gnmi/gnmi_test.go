@@ -0,0 +1,218 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package gnmi
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/telemetry"
+	"github.com/yahoo/panoptes-stream/telemetry/arista/gnmi"
+	"github.com/yahoo/panoptes-stream/telemetry/mock"
+)
+
+type subscriber struct {
+	sync.Mutex
+	devices []config.Device
+	served  chan config.Device
+	updates chan *gpb.SubscribeResponse
+}
+
+func (s *subscriber) Serve(ctx context.Context, device config.Device, updates <-chan *gpb.SubscribeResponse) error {
+	s.served <- device
+
+	for {
+		select {
+		case resp, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			s.updates <- resp
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (s *subscriber) GetDialoutDevices() []config.Device {
+	s.Lock()
+	defer s.Unlock()
+	return s.devices
+}
+
+func waitFor(f func() bool) bool {
+	for i := 0; i < 50; i++ {
+		if f() {
+			return true
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	return false
+}
+
+func gatherValue(t *testing.T, name string) float64 {
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	assert.NoError(t, err)
+
+	for _, mf := range mfs {
+		if mf.GetName() == name && len(mf.Metric) > 0 {
+			return mf.Metric[0].GetCounter().GetValue()
+		}
+	}
+
+	return -1
+}
+
+// publish opens a gNMI dial-out Publish stream like a device does.
+func publish(ctx context.Context, t *testing.T, addr string) grpc.ClientStream {
+	conn, err := grpc.DialContext(ctx, addr, grpc.WithInsecure(), grpc.WithBlock())
+	assert.NoError(t, err)
+
+	stream, err := conn.NewStream(ctx, &publishServiceDesc.Streams[0], "/Nokia.SROS.DialoutTelemetry/Publish")
+	assert.NoError(t, err)
+
+	return stream
+}
+
+func TestDialout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.NewMockConfig()
+	cfg.MGlobal.Dialout.Services = map[string]config.DialoutService{"gnmi": {Addr: "127.0.0.1:50590"}}
+
+	device := config.Device{DeviceConfig: config.DeviceConfig{Host: "127.0.0.1", Dialout: true}}
+	s := &subscriber{
+		devices: []config.Device{device},
+		served:  make(chan config.Device, 1),
+		updates: make(chan *gpb.SubscribeResponse, 1),
+	}
+
+	d := NewDialout(ctx, cfg, s)
+	assert.Error(t, d.Health())
+	assert.NoError(t, d.Start())
+	assert.NoError(t, d.Health())
+
+	stream := publish(ctx, t, "127.0.0.1:50590")
+	update := &gpb.SubscribeResponse{Response: &gpb.SubscribeResponse_Update{Update: mock.AristaUpdate()}}
+	assert.NoError(t, stream.SendMsg(update))
+
+	select {
+	case served := <-s.served:
+		assert.Equal(t, device, served)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "device not served")
+	}
+
+	select {
+	case resp := <-s.updates:
+		assert.Equal(t, mock.AristaUpdate().Timestamp, resp.GetUpdate().Timestamp)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "update not received")
+	}
+
+	assert.True(t, waitFor(func() bool { return d.metrics["sessionsCurrent"].Get() == 1 }))
+	assert.Equal(t, uint64(1), d.metrics["updatesTotal"].Get())
+
+	// the device is removed
+	s.Lock()
+	s.devices = nil
+	s.Unlock()
+	d.Update()
+
+	err := stream.RecvMsg(&gpb.SubscribeResponse{})
+	assert.Equal(t, codes.Unavailable, grpcstatus.Code(err))
+	assert.True(t, waitFor(func() bool { return d.metrics["sessionsCurrent"].Get() == 0 }))
+
+	// the unknown device is rejected
+	stream = publish(ctx, t, "127.0.0.1:50590")
+	err = stream.RecvMsg(&gpb.SubscribeResponse{})
+	assert.Equal(t, codes.PermissionDenied, grpcstatus.Code(err))
+	assert.Equal(t, uint64(1), d.metrics["rejectsTotal"].Get())
+
+	d.Stop()
+	assert.EqualError(t, d.Health(), "stopped")
+}
+
+func TestDialoutTelemetry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	device := config.Device{
+		DeviceConfig: config.DeviceConfig{Host: "127.0.0.1", Dialout: true},
+		Sensors: map[string][]*config.Sensor{
+			"arista.gnmi": {{Service: "arista.gnmi", Path: "/interfaces/interface/state/counters/", Output: "console::stdout"}},
+		},
+	}
+
+	cfg := config.NewMockConfig()
+	cfg.MDevices = []config.Device{device}
+	cfg.MGlobal.Dialout.Services = map[string]config.DialoutService{"gnmi": {Addr: "127.0.0.1:50593"}}
+
+	telemetryRegistrar := telemetry.NewRegistrar(zap.NewNop())
+	telemetryRegistrar.Register("arista.gnmi", gnmi.Version(), gnmi.New)
+
+	outChan := make(telemetry.ExtDSChan, 10)
+	tm := telemetry.New(ctx, cfg, telemetryRegistrar, outChan)
+
+	d := NewDialout(ctx, cfg, tm)
+	assert.NoError(t, d.Start())
+	defer d.Stop()
+
+	// the device publishes its configured subscription
+	stream := publish(ctx, t, "127.0.0.1:50593")
+	update := &gpb.SubscribeResponse{Response: &gpb.SubscribeResponse_Update{Update: mock.AristaUpdate()}}
+	assert.NoError(t, stream.SendMsg(update))
+
+	select {
+	case resp := <-outChan:
+		assert.Equal(t, "console::stdout", resp.Output)
+		assert.Equal(t, "127.0.0.1", resp.DS["system_id"])
+		assert.Equal(t, "/interfaces/interface/state/counters", resp.DS["prefix"])
+		assert.Equal(t, "out-octets", resp.DS["key"])
+		assert.Equal(t, map[string]string{"name": "Ethernet1"}, resp.DS["labels"])
+		assert.Equal(t, int64(50302030597), resp.DS["value"])
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "data not received")
+	}
+
+	// the device closes the stream
+	assert.NoError(t, stream.CloseSend())
+	err := stream.RecvMsg(&gpb.SubscribeResponse{})
+	assert.NotEqual(t, codes.PermissionDenied, grpcstatus.Code(err))
+	assert.True(t, waitFor(func() bool { return d.metrics["sessionsCurrent"].Get() == 0 }))
+}
+
+func TestDialoutRestartMetrics(t *testing.T) {
+	cfg := config.NewMockConfig()
+	s := &subscriber{}
+
+	d := NewDialout(context.Background(), cfg, s)
+	for i := 0; i < 5; i++ {
+		d.metrics["rejectsTotal"].Inc()
+	}
+	assert.Equal(t, float64(5), gatherValue(t, "panoptes_gnmi_dialout_rejects_total"))
+
+	d.Stop()
+	assert.Equal(t, float64(-1), gatherValue(t, "panoptes_gnmi_dialout_rejects_total"))
+
+	// the restarted service exports its own metrics
+	d = NewDialout(context.Background(), cfg, s)
+	d.metrics["rejectsTotal"].Inc()
+	assert.Equal(t, float64(1), gatherValue(t, "panoptes_gnmi_dialout_rejects_total"))
+
+	d.Stop()
+}
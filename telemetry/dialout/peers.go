@@ -0,0 +1,198 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package dialout
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yahoo/panoptes-stream/config"
+)
+
+var (
+	// ErrPeerNotAllowed is returned if the peer isn't in the allowlist.
+	ErrPeerNotAllowed = errors.New("peer not allowed")
+
+	// ErrUnknownPeer is returned if the peer doesn't map to any dial-out device.
+	ErrUnknownPeer = errors.New("unknown peer")
+
+	// lookupHost resolves the dial-out device hostnames.
+	lookupHost = net.DefaultResolver.LookupHost
+
+	lookupTimeout = 5 * time.Second
+)
+
+// Peers authenticates the dial-out peers by the allowlist (source CIDR
+// and TLS client certificate CN/SAN) and maps them to the configured
+// dial-out devices that this node owns.
+type Peers struct {
+	cfg        config.Config
+	subscriber Subscriber
+
+	sync.RWMutex
+	cidrs   []*net.IPNet
+	names   map[string]bool
+	devices map[string]config.Device
+}
+
+// NewPeers returns a new instance of peers.
+func NewPeers(cfg config.Config, subscriber Subscriber) *Peers {
+	return &Peers{
+		cfg:        cfg,
+		subscriber: subscriber,
+		names:      make(map[string]bool),
+		devices:    make(map[string]config.Device),
+	}
+}
+
+// Update loads the allowlist and the dial-out devices, the previous
+// allowlist is kept if the configured CIDRs are invalid. The device
+// hostnames are resolved so the peers without a TLS client certificate
+// are mapped by their source address as well.
+func (p *Peers) Update() error {
+	var cidrs []*net.IPNet
+
+	conf := p.cfg.Global().Dialout
+	for _, c := range conf.AllowedCIDRs {
+		_, cidr, err := net.ParseCIDR(c)
+		if err != nil {
+			return fmt.Errorf("dialout allowed cidr: %v", err)
+		}
+
+		cidrs = append(cidrs, cidr)
+	}
+
+	names := make(map[string]bool)
+	for _, name := range conf.AllowedNames {
+		names[strings.ToLower(name)] = true
+	}
+
+	devices := make(map[string]config.Device)
+	if p.subscriber != nil {
+		for _, device := range p.subscriber.GetDialoutDevices() {
+			devices[strings.ToLower(device.Host)] = device
+		}
+
+		for _, device := range p.subscriber.GetDialoutDevices() {
+			if net.ParseIP(device.Host) != nil {
+				continue
+			}
+
+			for _, addr := range resolve(device.Host) {
+				if _, ok := devices[addr]; !ok {
+					devices[addr] = device
+				}
+			}
+		}
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	p.cidrs = cidrs
+	p.names = names
+	p.devices = devices
+
+	return nil
+}
+
+// Identify returns the dial-out device of the peer. The peer is identified
+// by the verified client certificate CN/SAN and the source address.
+func (p *Peers) Identify(addr net.Addr, state *tls.ConnectionState) (config.Device, error) {
+	var (
+		names []string
+		host  string
+	)
+
+	if state != nil {
+		names = TLSIdentities(*state)
+	}
+
+	if h, _, err := net.SplitHostPort(addr.String()); err == nil {
+		host = h
+	}
+
+	p.RLock()
+	defer p.RUnlock()
+
+	if !p.allowed(net.ParseIP(host), names) {
+		return config.Device{}, ErrPeerNotAllowed
+	}
+
+	for _, id := range append(names, host) {
+		if device, ok := p.devices[strings.ToLower(id)]; ok {
+			return device, nil
+		}
+	}
+
+	return config.Device{}, ErrUnknownPeer
+}
+
+// resolve returns the addresses of the host, it returns
+// nil if the host can't be resolved.
+func resolve(host string) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+
+	addrs, err := lookupHost(ctx, host)
+	if err != nil {
+		return nil
+	}
+
+	for i, addr := range addrs {
+		if ip := net.ParseIP(addr); ip != nil {
+			addrs[i] = ip.String()
+		}
+	}
+
+	return addrs
+}
+
+// allowed returns true if the allowlist is empty or the peer matches any of its entries.
+func (p *Peers) allowed(ip net.IP, names []string) bool {
+	if len(p.cidrs) < 1 && len(p.names) < 1 {
+		return true
+	}
+
+	for _, cidr := range p.cidrs {
+		if ip != nil && cidr.Contains(ip) {
+			return true
+		}
+	}
+
+	for _, name := range names {
+		if p.names[strings.ToLower(name)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TLSIdentities returns the common name and the SANs of the verified client certificate.
+func TLSIdentities(state tls.ConnectionState) []string {
+	var identities []string
+
+	if len(state.VerifiedChains) < 1 || len(state.VerifiedChains[0]) < 1 {
+		return nil
+	}
+
+	cert := state.VerifiedChains[0][0]
+	if cert.Subject.CommonName != "" {
+		identities = append(identities, cert.Subject.CommonName)
+	}
+
+	identities = append(identities, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		identities = append(identities, ip.String())
+	}
+
+	return identities
+}
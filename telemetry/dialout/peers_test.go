@@ -0,0 +1,128 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package dialout
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net"
+	"testing"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yahoo/panoptes-stream/config"
+)
+
+type subscriber struct {
+	devices []config.Device
+}
+
+func (s *subscriber) Serve(ctx context.Context, device config.Device, updates <-chan *gpb.SubscribeResponse) error {
+	return nil
+}
+
+func (s *subscriber) GetDialoutDevices() []config.Device {
+	return s.devices
+}
+
+func TestPeers(t *testing.T) {
+	lax := config.Device{DeviceConfig: config.DeviceConfig{Host: "core1.lax", Dialout: true}}
+	lhr := config.Device{DeviceConfig: config.DeviceConfig{Host: "192.0.2.2", Dialout: true}}
+
+	cfg := &config.MockConfig{MGlobal: &config.Global{}}
+	p := NewPeers(cfg, &subscriber{devices: []config.Device{lax, lhr}})
+	assert.NoError(t, p.Update())
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "Core1.LAX"}}
+	state := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+
+	// mapped by the client certificate common name
+	device, err := p.Identify(&net.TCPAddr{IP: net.ParseIP("198.51.100.1")}, state)
+	assert.NoError(t, err)
+	assert.Equal(t, lax, device)
+
+	// mapped by the source address
+	device, err = p.Identify(&net.TCPAddr{IP: net.ParseIP("192.0.2.2")}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, lhr, device)
+
+	_, err = p.Identify(&net.TCPAddr{IP: net.ParseIP("192.0.2.3")}, nil)
+	assert.Equal(t, ErrUnknownPeer, err)
+
+	cfg.MGlobal.Dialout.AllowedCIDRs = []string{"192.0.2.0/24"}
+	cfg.MGlobal.Dialout.AllowedNames = []string{"core1.lax"}
+	assert.NoError(t, p.Update())
+
+	_, err = p.Identify(&net.TCPAddr{IP: net.ParseIP("198.51.100.1")}, state)
+	assert.NoError(t, err)
+
+	_, err = p.Identify(&net.TCPAddr{IP: net.ParseIP("192.0.2.2")}, nil)
+	assert.NoError(t, err)
+
+	_, err = p.Identify(&net.TCPAddr{IP: net.ParseIP("198.51.100.1")}, nil)
+	assert.Equal(t, ErrPeerNotAllowed, err)
+
+	// the invalid allowlist keeps the previous one
+	cfg.MGlobal.Dialout.AllowedCIDRs = []string{"192.0.2.0"}
+	assert.Error(t, p.Update())
+
+	_, err = p.Identify(&net.TCPAddr{IP: net.ParseIP("192.0.2.2")}, nil)
+	assert.NoError(t, err)
+}
+
+func TestPeersResolve(t *testing.T) {
+	defer func(f func(context.Context, string) ([]string, error)) { lookupHost = f }(lookupHost)
+	lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		switch host {
+		case "core1.lax":
+			return []string{"192.0.2.1", "2001:0db8::1"}, nil
+		case "core1.bur":
+			// the configured address takes precedence
+			return []string{"192.0.2.2"}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	lax := config.Device{DeviceConfig: config.DeviceConfig{Host: "core1.lax", Dialout: true}}
+	bur := config.Device{DeviceConfig: config.DeviceConfig{Host: "core1.bur", Dialout: true}}
+	lhr := config.Device{DeviceConfig: config.DeviceConfig{Host: "192.0.2.2", Dialout: true}}
+	ams := config.Device{DeviceConfig: config.DeviceConfig{Host: "core1.ams", Dialout: true}}
+
+	cfg := &config.MockConfig{MGlobal: &config.Global{}}
+	p := NewPeers(cfg, &subscriber{devices: []config.Device{lax, bur, lhr, ams}})
+	assert.NoError(t, p.Update())
+
+	// mapped by the resolved source address
+	device, err := p.Identify(&net.TCPAddr{IP: net.ParseIP("192.0.2.1")}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, lax, device)
+
+	device, err = p.Identify(&net.TCPAddr{IP: net.ParseIP("2001:db8::1")}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, lax, device)
+
+	device, err = p.Identify(&net.TCPAddr{IP: net.ParseIP("192.0.2.2")}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, lhr, device)
+
+	_, err = p.Identify(&net.TCPAddr{IP: net.ParseIP("192.0.2.3")}, nil)
+	assert.Equal(t, ErrUnknownPeer, err)
+}
+
+func TestTLSIdentities(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "core1.lax"},
+		DNSNames:    []string{"core1.lax.example.com"},
+		IPAddresses: []net.IP{net.ParseIP("192.0.2.1")},
+	}
+
+	assert.Nil(t, TLSIdentities(tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}))
+
+	identities := TLSIdentities(tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}})
+	assert.Equal(t, []string{"core1.lax", "core1.lax.example.com", "192.0.2.1"}, identities)
+}
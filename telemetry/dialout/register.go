@@ -0,0 +1,92 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package dialout
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"go.uber.org/zap"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/telemetry"
+)
+
+// Service represents a dial-out service.
+type Service interface {
+	Start() error
+	Update()
+	Stop()
+	Health() error
+}
+
+// Subscriber runs the device services over the updates that the device publishes.
+type Subscriber interface {
+	Serve(ctx context.Context, device config.Device, updates <-chan *gpb.SubscribeResponse) error
+	GetDialoutDevices() []config.Device
+}
+
+// Factory creates a dial-out service.
+type Factory func(context.Context, config.Config, telemetry.ExtDSChan, Subscriber) Service
+
+// Registrar represents dial-out service registry.
+type Registrar struct {
+	factories map[string]Factory
+	logger    *zap.Logger
+	sync.RWMutex
+}
+
+// NewRegistrar creates a new registrar instance.
+func NewRegistrar(logger *zap.Logger) *Registrar {
+	return &Registrar{
+		factories: make(map[string]Factory),
+		logger:    logger,
+	}
+}
+
+// Register adds new dial-out service factory.
+func (r *Registrar) Register(name, version string, f Factory) {
+	r.logger.Info("dialout", zap.String("event", "register"), zap.String("name", name), zap.String("version", version))
+	r.set(name, f)
+}
+
+// GetFactory returns requested dial-out service factory.
+func (r *Registrar) GetFactory(name string) (Factory, bool) {
+	return r.get(name)
+}
+
+// Validate returns error if any of the configured services isn't registered.
+func (r *Registrar) Validate(services map[string]config.DialoutService) error {
+	var names []string
+
+	for name := range services {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, ok := r.get(name); !ok {
+			return fmt.Errorf("dialout service: %s not available", name)
+		}
+	}
+
+	return nil
+}
+
+func (r *Registrar) set(name string, f Factory) {
+	r.Lock()
+	defer r.Unlock()
+	r.factories[name] = f
+}
+
+func (r *Registrar) get(name string) (Factory, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	v, ok := r.factories[name]
+	return v, ok
+}
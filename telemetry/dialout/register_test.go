@@ -0,0 +1,37 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package dialout
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/yahoo/panoptes-stream/config"
+)
+
+func TestRegister(t *testing.T) {
+	var f Factory
+
+	r := NewRegistrar(zap.NewNop())
+	r.Register("test", "0.0.0", f)
+
+	_, ok := r.GetFactory("test")
+	assert.True(t, ok)
+
+	_, ok = r.GetFactory("unknown")
+	assert.False(t, ok)
+}
+
+func TestValidate(t *testing.T) {
+	r := NewRegistrar(zap.NewNop())
+	r.Register("test", "0.0.0", testServiceNew)
+
+	assert.NoError(t, r.Validate(nil))
+	assert.NoError(t, r.Validate(map[string]config.DialoutService{"test": {}}))
+
+	err := r.Validate(map[string]config.DialoutService{"test": {}, "unknown": {}})
+	assert.EqualError(t, err, "dialout service: unknown not available")
+}
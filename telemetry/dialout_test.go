@@ -0,0 +1,123 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package telemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/telemetry/mock"
+)
+
+type testDialoutNMI struct {
+	conn    *grpc.ClientConn
+	outChan ExtDSChan
+}
+
+func (n *testDialoutNMI) Start(ctx context.Context) error {
+	stream, err := gpb.NewGNMIClient(n.conn).Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = stream.Send(&gpb.SubscribeRequest{Request: &gpb.SubscribeRequest_Subscribe{Subscribe: &gpb.SubscriptionList{}}})
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		n.outChan <- ExtDataStore{DS: DataStore{"timestamp": resp.GetUpdate().GetTimestamp()}}
+	}
+}
+
+func testDialoutNMINew(logger *zap.Logger, conn *grpc.ClientConn, sensors []*config.Sensor, outChan ExtDSChan, deviceMetrics *DeviceMetrics) NMI {
+	return &testDialoutNMI{conn: conn, outChan: outChan}
+}
+
+func TestServe(t *testing.T) {
+	device := config.Device{
+		DeviceConfig: config.DeviceConfig{
+			Host:    "core1.lax",
+			Dialout: true,
+		},
+		Sensors: map[string][]*config.Sensor{
+			"test.gnmi": {{Service: "test.gnmi", Path: "/interfaces/", Output: "console::stdout"}},
+			"none.gnmi": {},
+		},
+	}
+
+	cfg := &config.MockConfig{
+		MGlobal:  &config.Global{},
+		MDevices: []config.Device{device, {DeviceConfig: config.DeviceConfig{Host: "core1.lhr"}}},
+	}
+
+	outChan := make(ExtDSChan, 10)
+	telemetryRegistrar := NewRegistrar(zap.NewNop())
+	telemetryRegistrar.Register("test.gnmi", "0.0.0", testDialoutNMINew)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tm := New(ctx, cfg, telemetryRegistrar, outChan)
+	tm.logger = zap.NewNop()
+
+	assert.Equal(t, []config.Device{device}, tm.GetDialoutDevices())
+	assert.Len(t, tm.GetDevices(), 1)
+
+	// the device publishes the update
+	updates := make(chan *gpb.SubscribeResponse, 1)
+	updates <- &gpb.SubscribeResponse{Response: &gpb.SubscribeResponse_Update{Update: mock.AristaUpdate()}}
+
+	sCtx, sCancel := context.WithCancel(ctx)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- tm.Serve(sCtx, device, updates)
+	}()
+
+	select {
+	case extDS := <-outChan:
+		assert.Equal(t, mock.AristaUpdate().Timestamp, extDS.DS["timestamp"])
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "data not received")
+	}
+
+	states := tm.DeviceStates()
+	assert.Len(t, states, 1)
+	assert.Equal(t, "core1.lax", states[0].Host)
+	assert.True(t, states[0].Dialout)
+	assert.Len(t, states[0].Services, 1)
+	assert.NotNil(t, states[0].Services[0].LastData)
+
+	sCancel()
+
+	select {
+	case err := <-errCh:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "serve didn't return")
+	}
+
+	assert.Len(t, tm.sessions, 0)
+	assert.Len(t, tm.DeviceStates(), 0)
+
+	// the device closed the stream
+	close(updates)
+	err := tm.Serve(ctx, device, updates)
+	assert.NoError(t, err)
+
+	// no service available
+	err = tm.Serve(ctx, config.Device{DeviceConfig: config.DeviceConfig{Host: "core1.lhr"}}, updates)
+	assert.EqualError(t, err, "no service available")
+}
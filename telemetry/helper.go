@@ -40,6 +40,24 @@ func GetKey(buf *bytes.Buffer, path []*gpb.PathElem) (string, map[string]string)
 	return buf.String(), labels
 }
 
+// GetPathName returns the path without the keys, e.g. /interfaces/interface/state.
+func GetPathName(path *gpb.Path) string {
+	var b strings.Builder
+
+	for _, elem := range path.GetElem() {
+		if len(elem.Name) > 0 {
+			b.WriteRune('/')
+			b.WriteString(elem.Name)
+		}
+	}
+
+	if b.Len() < 1 {
+		return "/"
+	}
+
+	return b.String()
+}
+
 // GetValue returns telemetry value.
 func GetValue(tv *gpb.TypedValue) (interface{}, error) {
 	var (
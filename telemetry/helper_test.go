@@ -90,6 +90,19 @@ func TestGetKey(t *testing.T) {
 	assert.Equal(t, map[string]string{"name": "Ethernet1", "/interfaces/interface/state/name": "test"}, labels)
 }
 
+func TestGetPathName(t *testing.T) {
+	path := &gnmi.Path{
+		Elem: []*gnmi.PathElem{
+			{Name: "interfaces"},
+			{Name: "interface", Key: map[string]string{"name": "Ethernet1"}},
+			{Name: "state"},
+		},
+	}
+
+	assert.Equal(t, "/interfaces/interface/state", GetPathName(path))
+	assert.Equal(t, "/", GetPathName(nil))
+}
+
 func TestGetDefaultOutput(t *testing.T) {
 	sensors := []*config.Sensor{
 		{
@@ -42,15 +42,8 @@ type GNMI struct {
 }
 
 // New creates a GNMI.
-func New(logger *zap.Logger, conn *grpc.ClientConn, sensors []*config.Sensor, outChan telemetry.ExtDSChan) telemetry.NMI {
-	var metrics = make(map[string]status.Metrics)
-
-	metrics["gRPCDataTotal"] = status.NewCounter("juniper_gnmi_grpc_data_total", "")
-	metrics["dropsTotal"] = status.NewCounter("juniper_gnmi_drops_total", "")
-	metrics["errorsTotal"] = status.NewCounter("juniper_gnmi_errors_total", "")
-	metrics["processNSecond"] = status.NewGauge("juniper_gnmi_process_nanosecond", "")
-
-	status.Register(status.Labels{"host": conn.Target()}, metrics)
+func New(logger *zap.Logger, conn *grpc.ClientConn, sensors []*config.Sensor, outChan telemetry.ExtDSChan, deviceMetrics *telemetry.DeviceMetrics) telemetry.NMI {
+	metrics := deviceMetrics.Get(newMetrics)
 
 	return &GNMI{
 		logger:        logger,
@@ -63,10 +56,20 @@ func New(logger *zap.Logger, conn *grpc.ClientConn, sensors []*config.Sensor, ou
 	}
 }
 
+// newMetrics creates the NMI metrics.
+func newMetrics() map[string]status.Metrics {
+	var metrics = make(map[string]status.Metrics)
+
+	metrics["gRPCDataTotal"] = status.NewCounter("juniper_gnmi_grpc_data_total", "")
+	metrics["dropsTotal"] = status.NewCounter("juniper_gnmi_drops_total", "")
+	metrics["errorsTotal"] = status.NewCounter("juniper_gnmi_errors_total", "")
+	metrics["processSeconds"] = status.NewHistogramVec("juniper_gnmi_process_seconds", "", status.LatencyBuckets, "path")
+
+	return metrics
+}
+
 // Start starts to get stream and fan-out to workers
 func (g *GNMI) Start(ctx context.Context) error {
-	defer status.Unregister(status.Labels{"host": g.conn.Target()}, g.metrics)
-
 	client := gpb.NewGNMIClient(g.conn)
 	subReq := &gpb.SubscribeRequest{
 		Request: &gpb.SubscribeRequest_Subscribe{
@@ -136,7 +139,7 @@ func (g *GNMI) worker(ctx context.Context) {
 
 			buf.Reset()
 
-			g.metrics["processNSecond"].Set(uint64(time.Since(start).Nanoseconds()))
+			g.metrics["processSeconds"].With(telemetry.GetPathName(resp.Update.Prefix)).Observe(time.Since(start).Seconds())
 
 		case <-ctx.Done():
 			return
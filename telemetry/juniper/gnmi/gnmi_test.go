@@ -47,7 +47,7 @@ func TestJuniperCountersMock(t *testing.T) {
 		Path:    "/interfaces/interface/state/counters",
 	})
 
-	g := New(cfg.Logger(), conn, sensors, ch)
+	g := New(cfg.Logger(), conn, sensors, ch, nil)
 	g.Start(ctx)
 
 	expected := []struct {
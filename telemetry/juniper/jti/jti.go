@@ -42,20 +42,13 @@ type JTI struct {
 }
 
 // New creates a JTI.
-func New(logger *zap.Logger, conn *grpc.ClientConn, sensors []*config.Sensor, outChan telemetry.ExtDSChan) telemetry.NMI {
+func New(logger *zap.Logger, conn *grpc.ClientConn, sensors []*config.Sensor, outChan telemetry.ExtDSChan, deviceMetrics *telemetry.DeviceMetrics) telemetry.NMI {
 	var (
 		paths      = []*jpb.Path{}
 		pathOutput = make(map[string]string)
-		metrics    = make(map[string]status.Metrics)
+		metrics    = deviceMetrics.Get(newMetrics)
 	)
 
-	metrics["gRPCDataTotal"] = status.NewCounter("juniper_jti_grpc_data_total", "")
-	metrics["dropsTotal"] = status.NewCounter("juniper_jti_drops_total", "")
-	metrics["errorsTotal"] = status.NewCounter("juniper_jti_errors_total", "")
-	metrics["processNSecond"] = status.NewGauge("juniper_jti_process_nanosecond", "")
-
-	status.Register(status.Labels{"host": conn.Target()}, metrics)
-
 	for _, sensor := range sensors {
 		path := &jpb.Path{
 			Path:            sensor.Path,
@@ -81,10 +74,20 @@ func New(logger *zap.Logger, conn *grpc.ClientConn, sensors []*config.Sensor, ou
 	}
 }
 
+// newMetrics creates the NMI metrics.
+func newMetrics() map[string]status.Metrics {
+	var metrics = make(map[string]status.Metrics)
+
+	metrics["gRPCDataTotal"] = status.NewCounter("juniper_jti_grpc_data_total", "")
+	metrics["dropsTotal"] = status.NewCounter("juniper_jti_drops_total", "")
+	metrics["errorsTotal"] = status.NewCounter("juniper_jti_errors_total", "")
+	metrics["processSeconds"] = status.NewHistogramVec("juniper_jti_process_seconds", "", status.LatencyBuckets, "path")
+
+	return metrics
+}
+
 // Start starts to get stream and fan-out to workers.
 func (j *JTI) Start(ctx context.Context) error {
-	defer status.Unregister(status.Labels{"host": j.conn.Target()}, j.metrics)
-
 	if err := j.auth(ctx); err != nil {
 		return err
 	}
@@ -180,7 +183,7 @@ func (j *JTI) worker(ctx context.Context) {
 
 			j.datastore(rBuf, wBuf, data, output)
 
-			j.metrics["processNSecond"].Set(uint64(time.Since(start).Nanoseconds()))
+			j.metrics["processSeconds"].With(path[1]).Observe(time.Since(start).Seconds())
 
 		case <-ctx.Done():
 			return
@@ -72,7 +72,7 @@ func JuniperLo0InterfaceSample(t *testing.T) {
 		Path:    "/interfaces/interface[name='lo0']/state/counters/",
 	})
 
-	j := New(cfg.Logger(), conn, sensors, ch)
+	j := New(cfg.Logger(), conn, sensors, ch, nil)
 	j.Start(ctx)
 
 	KV := mock.JuniperJTILo0InterfaceSample().Kv
@@ -137,7 +137,7 @@ func JuniperMix(t *testing.T) {
 		Path:    "/mixes/mix[name='lo0']/state/",
 	})
 
-	j := New(cfg.Logger(), conn, sensors, ch)
+	j := New(cfg.Logger(), conn, sensors, ch, nil)
 	j.Start(ctx)
 
 	KV := mock.JuniperJTIMix().Kv
@@ -212,7 +212,7 @@ func JuniperBGPSample(t *testing.T) {
 		Path:    "/network-instances/network-instance/protocols/protocol/bgp/",
 	})
 
-	j := New(cfg.Logger(), conn, sensors, ch)
+	j := New(cfg.Logger(), conn, sensors, ch, nil)
 	j.Start(ctx)
 
 	r := new(bytes.Buffer)
@@ -4,9 +4,14 @@
 package juniper
 
 import (
+	"context"
+
+	"github.com/yahoo/panoptes-stream/config"
 	"github.com/yahoo/panoptes-stream/telemetry"
+	"github.com/yahoo/panoptes-stream/telemetry/dialout"
 	"github.com/yahoo/panoptes-stream/telemetry/juniper/gnmi"
 	"github.com/yahoo/panoptes-stream/telemetry/juniper/jti"
+	"github.com/yahoo/panoptes-stream/telemetry/juniper/udp"
 )
 
 // Register Juniper telemetries
@@ -14,3 +19,10 @@ func Register(telemetryRegistrar *telemetry.Registrar) {
 	telemetryRegistrar.Register("juniper.gnmi", gnmi.Version(), gnmi.New)
 	telemetryRegistrar.Register("juniper.jti", jti.Version(), jti.New)
 }
+
+// RegisterDialout registers Juniper dial-out telemetries
+func RegisterDialout(dialoutRegistrar *dialout.Registrar) {
+	dialoutRegistrar.Register("juniper.udp", udp.Version(), func(ctx context.Context, cfg config.Config, outChan telemetry.ExtDSChan, subscriber dialout.Subscriber) dialout.Service {
+		return udp.New(ctx, cfg, outChan, subscriber)
+	})
+}
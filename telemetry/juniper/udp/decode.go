@@ -0,0 +1,254 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package udp
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	// the compiled-in sensors
+	_ "github.com/yahoo/panoptes-stream/telemetry/juniper/proto/port"
+	jpb "github.com/yahoo/panoptes-stream/telemetry/juniper/proto/telemetry_top"
+)
+
+// emitFunc receives the flattened sensor data.
+type emitFunc func(key string, labels map[string]string, value interface{})
+
+// extensions represents the Juniper Networks sensors of the descriptor
+// sets by their field number, it resolves the sensors that aren't compiled
+// in and it falls back to the registered types.
+type extensions map[protoreflect.FieldNumber]protoreflect.ExtensionType
+
+var juniperNetworksSensors = (&jpb.JuniperNetworksSensors{}).ProtoReflect().Descriptor().FullName()
+
+// decodeStream decodes the TelemetryStream, the required fields aren't checked.
+func (e extensions) decodeStream(b []byte) (*jpb.TelemetryStream, error) {
+	s := &jpb.TelemetryStream{}
+	err := proto.UnmarshalOptions{Resolver: e, AllowPartial: true}.Unmarshal(b, s)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// decodeSensors flattens the Juniper Networks sensors of the stream, it
+// returns the number of the sensors that their types aren't available.
+func decodeSensors(s *jpb.TelemetryStream, emit emitFunc) int {
+	var unknown int
+
+	enterprise := s.GetEnterprise()
+	if enterprise == nil || !proto.HasExtension(enterprise, jpb.E_JuniperNetworks) {
+		return 0
+	}
+
+	sensors, ok := proto.GetExtension(enterprise, jpb.E_JuniperNetworks).(*jpb.JuniperNetworksSensors)
+	if !ok {
+		return 0
+	}
+
+	m := sensors.ProtoReflect()
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.IsExtension() && fd.Message() != nil {
+			walkMessage(v.Message(), "", nil, emit)
+		}
+		return true
+	})
+
+	for b := m.GetUnknown(); len(b) > 0; unknown++ {
+		_, _, n := protowire.ConsumeField(b)
+		if n < 0 {
+			break
+		}
+		b = b[n:]
+	}
+
+	return unknown
+}
+
+// walkMessage flattens the sensor message, the field names are the keys
+// and the key fields (telemetry_options.is_key) are labels.
+func walkMessage(m protoreflect.Message, prefix string, labels map[string]string, emit emitFunc) {
+	labels = copyLabels(labels)
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if !fd.IsList() && isKey(fd) {
+			setLabel(labels, prefix, string(fd.Name()), fmt.Sprint(v.Interface()))
+		}
+		return true
+	})
+
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		key := joinKey(prefix, string(fd.Name()))
+
+		switch {
+		case fd.IsMap():
+		case fd.IsList():
+			l := v.List()
+			for i := 0; i < l.Len(); i++ {
+				walkValue(fd, l.Get(i), key, labels, emit)
+			}
+		case isKey(fd):
+		default:
+			walkValue(fd, v, key, labels, emit)
+		}
+
+		return true
+	})
+}
+
+func walkValue(fd protoreflect.FieldDescriptor, v protoreflect.Value, key string, labels map[string]string, emit emitFunc) {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		walkMessage(v.Message(), key, labels, emit)
+	case protoreflect.BoolKind:
+		emit(key, labels, v.Bool())
+	case protoreflect.StringKind:
+		emit(key, labels, v.String())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			emit(key, labels, string(ev.Name()))
+		} else {
+			emit(key, labels, int64(v.Enum()))
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		emit(key, labels, v.Int())
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		emit(key, labels, v.Uint())
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		emit(key, labels, v.Float())
+	}
+}
+
+// isKey returns true if the field has the telemetry_options is_key option.
+func isKey(fd protoreflect.FieldDescriptor) bool {
+	opts, ok := fd.Options().(*descriptorpb.FieldOptions)
+	if !ok || opts == nil || !proto.HasExtension(opts, jpb.E_TelemetryOptions) {
+		return false
+	}
+
+	o, _ := proto.GetExtension(opts, jpb.E_TelemetryOptions).(*jpb.TelemetryFieldOptions)
+
+	return o.GetIsKey()
+}
+
+// loadExtensions returns the Juniper Networks sensors of the descriptor
+// set file (protoc --include_imports --descriptor_set_out).
+func loadExtensions(filename string) (extensions, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(b, set); err != nil {
+		return nil, err
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, err
+	}
+
+	e := make(extensions)
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		e.add(fd.Extensions())
+		e.addMessages(fd.Messages())
+		return true
+	})
+
+	if len(e) < 1 {
+		return nil, errors.New("juniper networks sensors not found")
+	}
+
+	return e, nil
+}
+
+func (e extensions) add(xds protoreflect.ExtensionDescriptors) {
+	for i := 0; i < xds.Len(); i++ {
+		xd := xds.Get(i)
+		if xd.ContainingMessage().FullName() == juniperNetworksSensors && xd.Message() != nil {
+			e[xd.Number()] = dynamicpb.NewExtensionType(xd)
+		}
+	}
+}
+
+func (e extensions) addMessages(mds protoreflect.MessageDescriptors) {
+	for i := 0; i < mds.Len(); i++ {
+		e.add(mds.Get(i).Extensions())
+		e.addMessages(mds.Get(i).Messages())
+	}
+}
+
+// FindExtensionByName resolves the extension by its name.
+func (e extensions) FindExtensionByName(field protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	for _, xt := range e {
+		if xt.TypeDescriptor().FullName() == field {
+			return xt, nil
+		}
+	}
+
+	return protoregistry.GlobalTypes.FindExtensionByName(field)
+}
+
+// FindExtensionByNumber resolves the sensor by the descriptor sets first.
+func (e extensions) FindExtensionByNumber(message protoreflect.FullName, field protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	if message == juniperNetworksSensors {
+		if xt, ok := e[field]; ok {
+			return xt, nil
+		}
+	}
+
+	return protoregistry.GlobalTypes.FindExtensionByNumber(message, field)
+}
+
+// getSensorPath returns the sensor path of the sensor name, e.g.
+// sensor_1000_4_1:/junos/system/linecard/interface/:/junos/system/linecard/interface/:PFE
+func getSensorPath(name string) string {
+	parts := strings.Split(name, ":")
+	if len(parts) < 2 || parts[1] == "" {
+		return ""
+	}
+
+	return path.Clean(parts[1])
+}
+
+// setLabel adds the label, the prefix distinguishes the parent labels with the same name.
+func setLabel(labels map[string]string, prefix, name, value string) {
+	if _, ok := labels[name]; ok && prefix != "" {
+		labels[prefix+"/"+name] = value
+		return
+	}
+
+	labels[name] = value
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	c := make(map[string]string, len(labels))
+	for k, v := range labels {
+		c[k] = v
+	}
+
+	return c
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "/" + name
+}
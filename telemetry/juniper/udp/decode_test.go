@@ -0,0 +1,214 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package udp
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	jpb "github.com/yahoo/panoptes-stream/telemetry/juniper/proto/telemetry_top"
+	"github.com/yahoo/panoptes-stream/telemetry/mock"
+)
+
+type testPoint struct {
+	labels map[string]string
+	value  interface{}
+}
+
+func testEmit(points map[string][]testPoint) emitFunc {
+	return func(key string, labels map[string]string, value interface{}) {
+		points[key] = append(points[key], testPoint{labels: labels, value: value})
+	}
+}
+
+func testStream(t *testing.T, s *jpb.TelemetryStream) []byte {
+	b, err := proto.Marshal(s)
+	assert.NoError(t, err)
+	return b
+}
+
+func TestDecodeStream(t *testing.T) {
+	var e extensions
+
+	s, err := e.decodeStream(testStream(t, mock.JuniperPortStream()))
+	assert.NoError(t, err)
+	assert.Equal(t, "mx1-lax:192.0.2.1", s.GetSystemId())
+	assert.Equal(t, uint32(1), s.GetComponentId())
+	assert.Equal(t, uint64(1597098791076), s.GetTimestamp())
+
+	points := make(map[string][]testPoint)
+	assert.Equal(t, 0, decodeSensors(s, testEmit(points)))
+
+	labels := map[string]string{"if_name": "xe-1/0/0"}
+	queueLabels := map[string]string{"if_name": "xe-1/0/0", "queue_number": "0"}
+
+	assert.Equal(t, []testPoint{{labels, uint64(1597098791)}}, points["interface_stats/init_time"])
+	assert.Equal(t, []testPoint{{labels, uint64(531)}}, points["interface_stats/snmp_if_index"])
+	assert.Equal(t, []testPoint{{labels, "UP"}}, points["interface_stats/if_operational_status"])
+	assert.Equal(t, []testPoint{{labels, uint64(1507)}}, points["interface_stats/ingress_stats/if_pkts"])
+	assert.Equal(t, []testPoint{{labels, uint64(186311)}}, points["interface_stats/ingress_stats/if_octets"])
+	assert.Equal(t, []testPoint{{queueLabels, uint64(1200)}}, points["interface_stats/egress_queue_info/packets"])
+	assert.Equal(t, []testPoint{{queueLabels, uint64(156000)}}, points["interface_stats/egress_queue_info/bytes"])
+
+	// the key fields aren't values
+	assert.NotContains(t, points, "interface_stats/if_name")
+	assert.NotContains(t, points, "interface_stats/egress_queue_info/queue_number")
+
+	_, err = e.decodeStream([]byte{0x0a, 0x10, 0x01})
+	assert.Error(t, err)
+}
+
+func TestDecodeUnknownSensor(t *testing.T) {
+	var e extensions
+
+	// a sensor that isn't compiled in
+	var sensor []byte
+	sensor = protowire.AppendTag(sensor, 99, protowire.BytesType)
+	sensor = protowire.AppendBytes(sensor, []byte{0x08, 0x01})
+
+	sensors := &jpb.JuniperNetworksSensors{}
+	sensors.ProtoReflect().SetUnknown(sensor)
+	enterprise := &jpb.EnterpriseSensors{}
+	proto.SetExtension(enterprise, jpb.E_JuniperNetworks, sensors)
+
+	s, err := e.decodeStream(testStream(t, &jpb.TelemetryStream{SystemId: proto.String("mx1-lax"), Enterprise: enterprise}))
+	assert.NoError(t, err)
+
+	points := make(map[string][]testPoint)
+	assert.Equal(t, 1, decodeSensors(s, testEmit(points)))
+	assert.Len(t, points, 0)
+
+	// without any sensor
+	s, err = e.decodeStream(testStream(t, &jpb.TelemetryStream{SystemId: proto.String("mx1-lax")}))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, decodeSensors(s, testEmit(points)))
+}
+
+// testDescriptorSet writes a descriptor set of a sensor that isn't compiled in:
+// extend JuniperNetworksSensors { optional CpuMemoryUtilization cpu_memory_util_ext = 1; }
+func testDescriptorSet(t *testing.T, dir string) string {
+	str := proto.String
+	num := proto.Int32
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	uint64Type := descriptorpb.FieldDescriptorProto_TYPE_UINT64.Enum()
+	message := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+
+	isKey := &descriptorpb.FieldOptions{}
+	proto.SetExtension(isKey, jpb.E_TelemetryOptions, &jpb.TelemetryFieldOptions{IsKey: proto.Bool(true)})
+
+	cpu := &descriptorpb.FileDescriptorProto{
+		Name:       str("cpu_memory_utilization.proto"),
+		Syntax:     str("proto2"),
+		Dependency: []string{"telemetry_top.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: str("CpuMemoryUtilization"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: str("summary"), Number: num(1), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(), Type: message, TypeName: str(".CpuMemoryUtilizationSummary")},
+				},
+			},
+			{
+				Name: str("CpuMemoryUtilizationSummary"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: str("resource_name"), Number: num(1), Label: optional, Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Options: isKey},
+					{Name: str("size"), Number: num(2), Label: optional, Type: uint64Type},
+					{Name: str("bytes_allocated"), Number: num(3), Label: optional, Type: uint64Type},
+				},
+			},
+		},
+		Extension: []*descriptorpb.FieldDescriptorProto{
+			{Name: str("cpu_memory_util_ext"), Number: num(1), Label: optional, Type: message, TypeName: str(".CpuMemoryUtilization"), Extendee: str(".JuniperNetworksSensors")},
+		},
+	}
+
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+		protodesc.ToFileDescriptorProto(jpb.File_telemetry_top_proto),
+		cpu,
+	}}
+
+	b, err := proto.Marshal(set)
+	assert.NoError(t, err)
+
+	filename := filepath.Join(dir, "juniper.desc")
+	assert.NoError(t, ioutil.WriteFile(filename, b, 0644))
+
+	return filename
+}
+
+func TestDecodeDescriptorSet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udp")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	e, err := loadExtensions(testDescriptorSet(t, dir))
+	assert.NoError(t, err)
+	assert.Len(t, e, 1)
+
+	// the device export of the descriptor set sensor
+	xt := e[1]
+	cpu := dynamicpb.NewMessage(xt.TypeDescriptor().Message())
+	summaryFd := cpu.Descriptor().Fields().ByName("summary")
+	summary := cpu.Mutable(summaryFd).List()
+	item := summary.NewElement()
+	fields := item.Message().Descriptor().Fields()
+	item.Message().Set(fields.ByName("resource_name"), protoreflect.ValueOfString("Kernel"))
+	item.Message().Set(fields.ByName("size"), protoreflect.ValueOfUint64(3221225472))
+	item.Message().Set(fields.ByName("bytes_allocated"), protoreflect.ValueOfUint64(1021665184))
+	summary.Append(item)
+
+	sensors := &jpb.JuniperNetworksSensors{}
+	proto.SetExtension(sensors, xt, cpu)
+	enterprise := &jpb.EnterpriseSensors{}
+	proto.SetExtension(enterprise, jpb.E_JuniperNetworks, sensors)
+
+	b := testStream(t, &jpb.TelemetryStream{
+		SystemId:   proto.String("mx1-lax"),
+		SensorName: proto.String("sensor_1001:/junos/system/linecard/cpu/memory/:/junos/system/linecard/cpu/memory/:PFE"),
+		Enterprise: enterprise,
+	})
+
+	// the sensor isn't known without the descriptor set
+	var none extensions
+	s, err := none.decodeStream(b)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, decodeSensors(s, testEmit(make(map[string][]testPoint))))
+
+	s, err = e.decodeStream(b)
+	assert.NoError(t, err)
+
+	points := make(map[string][]testPoint)
+	assert.Equal(t, 0, decodeSensors(s, testEmit(points)))
+
+	labels := map[string]string{"resource_name": "Kernel"}
+	assert.Equal(t, map[string][]testPoint{
+		"summary/size":            {{labels, uint64(3221225472)}},
+		"summary/bytes_allocated": {{labels, uint64(1021665184)}},
+	}, points)
+
+	_, err = loadExtensions(filepath.Join(dir, "notexist.desc"))
+	assert.Error(t, err)
+}
+
+func TestGetSensorPath(t *testing.T) {
+	assert.Equal(t, "/junos/system/linecard/interface", getSensorPath(mock.JuniperPortStream().GetSensorName()))
+	assert.Equal(t, "", getSensorPath("sensor_1000"))
+	assert.Equal(t, "", getSensorPath("sensor_1000::"))
+}
+
+func TestSetLabel(t *testing.T) {
+	labels := map[string]string{"name": "xe-0/0/0"}
+	setLabel(labels, "queue", "name", "q1")
+	assert.Equal(t, map[string]string{"name": "xe-0/0/0", "queue/name": "q1"}, labels)
+}
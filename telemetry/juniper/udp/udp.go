@@ -0,0 +1,271 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package udp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/status"
+	"github.com/yahoo/panoptes-stream/telemetry"
+	"github.com/yahoo/panoptes-stream/telemetry/dialout"
+)
+
+// service is the dial-out and the sensors service name.
+const service = "juniper.udp"
+
+var (
+	udpVersion    = "0.0.1"
+	maxPacketSize = 65535
+	queueSize     = 10000
+)
+
+// UDP represents Juniper native sensors receiver, the linecards export
+// the sensors over UDP as GPB TelemetryStream messages. The peer is mapped
+// to a configured dial-out device and the sensor name is mapped to the
+// device's juniper.udp sensors by their path.
+type UDP struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	cfg        config.Config
+	dataChan   chan packet
+	outChan    telemetry.ExtDSChan
+	logger     *zap.Logger
+	metrics    map[string]status.Metrics
+	health     *status.CheckState
+	peers      *dialout.Peers
+	extensions extensions
+}
+
+type packet struct {
+	addr net.Addr
+	data []byte
+}
+
+// New returns a new instance of Juniper native sensors receiver.
+func New(ctx context.Context, cfg config.Config, outChan telemetry.ExtDSChan, subscriber dialout.Subscriber) *UDP {
+	var metrics = make(map[string]status.Metrics)
+
+	metrics["packetsTotal"] = status.NewCounter("juniper_udp_packets_total", "Received UDP packets")
+	metrics["packetDropsTotal"] = status.NewCounter("juniper_udp_packet_drops_total", "Dropped UDP packets since the workers are busy")
+	metrics["dropsTotal"] = status.NewCounter("juniper_udp_drops_total", "Dropped datasets")
+	metrics["errorsTotal"] = status.NewCounter("juniper_udp_errors_total", "Decode and output lookup errors")
+	metrics["rejectsTotal"] = status.NewCounter("juniper_udp_rejects_total", "Rejected UDP packets from unknown peers")
+	metrics["unknownSensorsTotal"] = status.NewCounter("juniper_udp_unknown_sensors_total", "Sensors that their types aren't available")
+
+	status.Register(status.Labels{}, metrics)
+
+	u := &UDP{
+		cfg:      cfg,
+		outChan:  outChan,
+		logger:   config.NamedLogger(cfg.Logger(), "dialout.juniper.udp"),
+		dataChan: make(chan packet, queueSize),
+		metrics:  metrics,
+		health:   status.NewCheckState(errors.New("not started")),
+		peers:    dialout.NewPeers(cfg, subscriber),
+	}
+
+	u.ctx, u.cancel = context.WithCancel(ctx)
+
+	return u
+}
+
+// Start creates workers and starts UDP server.
+func (u *UDP) Start() error {
+	var err error
+
+	conf := u.cfg.Global().Dialout.Services[service]
+
+	if conf.Addr == "" {
+		err = errors.New("address is empty")
+		u.health.Set(err)
+		return err
+	}
+
+	if err = u.peers.Update(); err != nil {
+		u.health.Set(err)
+		return err
+	}
+
+	if conf.Descriptors != "" {
+		u.extensions, err = loadExtensions(conf.Descriptors)
+		if err != nil {
+			u.health.Set(err)
+			return err
+		}
+	}
+
+	if conf.Workers < 1 {
+		conf.Workers = 2
+	}
+
+	conn, err := net.ListenPacket("udp", conf.Addr)
+	if err != nil {
+		u.health.Set(err)
+		return err
+	}
+
+	for i := 0; i < conf.Workers; i++ {
+		go u.worker()
+	}
+
+	go u.receive(conn)
+
+	go func() {
+		<-u.ctx.Done()
+		conn.Close()
+	}()
+
+	u.health.Set(nil)
+	u.logger.Info("juniper.udp", zap.String("address", conf.Addr), zap.Int("workers", conf.Workers), zap.Int("extensions", len(u.extensions)))
+
+	return nil
+}
+
+// Stop stops the UDP server and the workers, the metrics are
+// unregistered since a restarted service registers its own.
+func (u *UDP) Stop() {
+	u.cancel()
+	u.health.Set(errors.New("stopped"))
+	status.Unregister(status.Labels{}, u.metrics)
+}
+
+// Health returns error if the UDP server isn't serving.
+func (u *UDP) Health() error {
+	return u.health.Check()
+}
+
+// Update updates the dial-out peers once the configuration changed.
+func (u *UDP) Update() {
+	if err := u.peers.Update(); err != nil {
+		u.logger.Error("juniper.udp", zap.Error(err))
+	}
+}
+
+// receive reads the packets and fan-out to workers, the packets
+// are dropped if the workers can't keep up.
+func (u *UDP) receive(conn net.PacketConn) {
+	buf := make([]byte, maxPacketSize)
+
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if u.ctx.Err() == nil {
+				u.logger.Error("juniper.udp", zap.Error(err))
+				u.health.Set(err)
+			}
+			return
+		}
+
+		u.metrics["packetsTotal"].Inc()
+
+		data := make([]byte, n)
+		copy(data, buf[:n])
+
+		select {
+		case u.dataChan <- packet{addr: addr, data: data}:
+		default:
+			u.metrics["packetDropsTotal"].Inc()
+		}
+	}
+}
+
+func (u *UDP) worker() {
+	for {
+		select {
+		case p := <-u.dataChan:
+			if err := u.handler(p); err != nil {
+				u.metrics["errorsTotal"].Inc()
+				u.logger.Error("juniper.udp", zap.String("peer", p.addr.String()), zap.Error(err))
+			}
+
+		case <-u.ctx.Done():
+			return
+		}
+	}
+}
+
+func (u *UDP) handler(p packet) error {
+	device, err := u.peers.Identify(p.addr, nil)
+	if err != nil {
+		u.metrics["rejectsTotal"].Inc()
+		u.logger.Debug("juniper.udp", zap.String("event", "reject"), zap.String("peer", p.addr.String()), zap.Error(err))
+		return nil
+	}
+
+	s, err := u.extensions.decodeStream(p.data)
+	if err != nil {
+		return err
+	}
+
+	prefix := getSensorPath(s.GetSensorName())
+	output, err := u.getOutput(device, prefix)
+	if err != nil {
+		return err
+	}
+
+	streamLabels := map[string]string{
+		"component_id":     strconv.FormatUint(uint64(s.GetComponentId()), 10),
+		"sub_component_id": strconv.FormatUint(uint64(s.GetSubComponentId()), 10),
+	}
+
+	// convert to nanoseconds, the producers expect int64
+	timestamp := int64(s.GetTimestamp()) * 1000000
+
+	emit := func(key string, labels map[string]string, value interface{}) {
+		ds := telemetry.DataStore{
+			"prefix":    prefix,
+			"labels":    telemetry.MergeLabels(copyLabels(labels), streamLabels),
+			"timestamp": timestamp,
+			"system_id": device.Host,
+			"key":       key,
+			"value":     value,
+		}
+
+		select {
+		case u.outChan <- telemetry.ExtDataStore{
+			DS:     ds,
+			Output: output,
+		}:
+		default:
+			u.metrics["dropsTotal"].Inc()
+		}
+	}
+
+	if unknown := decodeSensors(s, emit); unknown > 0 {
+		for i := 0; i < unknown; i++ {
+			u.metrics["unknownSensorsTotal"].Inc()
+		}
+		u.logger.Debug("juniper.udp", zap.String("event", "unknown sensor"), zap.String("sensor", s.GetSensorName()))
+	}
+
+	return nil
+}
+
+// getOutput returns the output of the device sensor that has the path, regardless of trailing slash,
+// otherwise the dial-out default output.
+func (u *UDP) getOutput(device config.Device, sensorPath string) (string, error) {
+	for _, sensor := range device.Sensors[service] {
+		if path.Clean(sensor.Path) == sensorPath {
+			return sensor.Output, nil
+		}
+	}
+
+	if u.cfg.Global().Dialout.DefaultOutput != "" {
+		return u.cfg.Global().Dialout.DefaultOutput, nil
+	}
+
+	return "", errors.New("output not found: " + sensorPath)
+}
+
+// Version returns version
+func Version() string {
+	return udpVersion
+}
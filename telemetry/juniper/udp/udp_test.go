@@ -0,0 +1,207 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package udp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/telemetry"
+	"github.com/yahoo/panoptes-stream/telemetry/mock"
+)
+
+type subscriber struct {
+	devices []config.Device
+}
+
+func (s *subscriber) Serve(ctx context.Context, device config.Device, updates <-chan *gpb.SubscribeResponse) error {
+	return nil
+}
+
+func (s *subscriber) GetDialoutDevices() []config.Device {
+	return s.devices
+}
+
+var dialoutDevice = config.Device{
+	DeviceConfig: config.DeviceConfig{Host: "127.0.0.1", Dialout: true},
+	Sensors: map[string][]*config.Sensor{
+		"juniper.udp": {{Service: "juniper.udp", Path: "/junos/system/linecard/interface/", Output: "console::stdout"}},
+	},
+}
+
+func waitFor(f func() bool) bool {
+	for i := 0; i < 50; i++ {
+		if f() {
+			return true
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	return false
+}
+
+func TestStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.NewMockConfig()
+	cfg.MGlobal.Dialout.Services = map[string]config.DialoutService{"juniper.udp": {Addr: "127.0.0.1:50591"}}
+
+	outChan := make(telemetry.ExtDSChan, 10)
+	u := New(ctx, cfg, outChan, &subscriber{devices: []config.Device{dialoutDevice}})
+	assert.Error(t, u.Health())
+	assert.NoError(t, u.Start())
+	assert.NoError(t, u.Health())
+
+	conn, err := net.Dial("udp", "127.0.0.1:50591")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write(testStream(t, mock.JuniperPortStream()))
+	assert.NoError(t, err)
+
+	select {
+	case resp := <-outChan:
+		assert.Equal(t, "console::stdout", resp.Output)
+		assert.Equal(t, "127.0.0.1", resp.DS["system_id"])
+		assert.Equal(t, "/junos/system/linecard/interface", resp.DS["prefix"])
+		assert.Equal(t, int64(1597098791076000000), resp.DS["timestamp"])
+		assert.Equal(t, "xe-1/0/0", resp.DS["labels"].(map[string]string)["if_name"])
+		assert.Equal(t, "1", resp.DS["labels"].(map[string]string)["component_id"])
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "time limit exceeded")
+	}
+
+	assert.True(t, waitFor(func() bool { return u.metrics["packetsTotal"].Get() == 1 }))
+
+	u.Stop()
+	assert.EqualError(t, u.Health(), "stopped")
+}
+
+func TestStartEmptyAddr(t *testing.T) {
+	cfg := config.NewMockConfig()
+	cfg.MGlobal.Dialout.Services = map[string]config.DialoutService{"juniper.udp": {}}
+
+	u := New(context.Background(), cfg, make(telemetry.ExtDSChan, 1), &subscriber{})
+	defer u.Stop()
+	assert.EqualError(t, u.Start(), "address is empty")
+	assert.EqualError(t, u.Health(), "address is empty")
+}
+
+func TestHandler(t *testing.T) {
+	cfg := config.NewMockConfig()
+	outChan := make(telemetry.ExtDSChan, 1)
+
+	u := New(context.Background(), cfg, outChan, &subscriber{devices: []config.Device{dialoutDevice}})
+	defer u.Stop()
+	assert.NoError(t, u.peers.Update())
+
+	data := testStream(t, mock.JuniperPortStream())
+
+	// unknown peer
+	err := u.handler(packet{addr: &net.UDPAddr{IP: net.ParseIP("192.0.2.9"), Port: 21111}, data: data})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), u.metrics["rejectsTotal"].Get())
+
+	// the output channel is full
+	addr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 21111}
+	err = u.handler(packet{addr: addr, data: data})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(12), u.metrics["dropsTotal"].Get())
+
+	// malformed packet
+	err = u.handler(packet{addr: addr, data: []byte{0x0a, 0x10, 0x01}})
+	assert.Error(t, err)
+
+	// sensor output not found
+	stream := mock.JuniperPortStream()
+	stream.SensorName = proto.String("sensor_1001:/junos/system/linecard/cpu/memory/:/junos/system/linecard/cpu/memory/:PFE")
+	err = u.handler(packet{addr: addr, data: testStream(t, stream)})
+	assert.EqualError(t, err, "output not found: /junos/system/linecard/cpu/memory")
+}
+
+func TestReceiveDrop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.NewMockConfig()
+	u := New(ctx, cfg, make(telemetry.ExtDSChan, 1), &subscriber{})
+	defer u.Stop()
+	u.dataChan = make(chan packet, 1)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:50592")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	// there is no worker to drain the queue
+	go u.receive(conn)
+
+	client, err := net.Dial("udp", "127.0.0.1:50592")
+	assert.NoError(t, err)
+	defer client.Close()
+
+	for i := 0; i < 3; i++ {
+		_, err = client.Write([]byte{0x01})
+		assert.NoError(t, err)
+	}
+
+	assert.True(t, waitFor(func() bool { return u.metrics["packetDropsTotal"].Get() == 2 }))
+}
+
+func TestGetOutput(t *testing.T) {
+	cfg := config.NewMockConfig()
+	u := New(context.Background(), cfg, make(telemetry.ExtDSChan, 1), &subscriber{})
+	defer u.Stop()
+
+	output, err := u.getOutput(dialoutDevice, "/junos/system/linecard/interface")
+	assert.NoError(t, err)
+	assert.Equal(t, "console::stdout", output)
+
+	_, err = u.getOutput(dialoutDevice, "/junos/system/linecard/cpu/memory")
+	assert.Error(t, err)
+
+	cfg.MGlobal.Dialout.DefaultOutput = "console::stderr"
+	output, err = u.getOutput(dialoutDevice, "/junos/system/linecard/cpu/memory")
+	assert.NoError(t, err)
+	assert.Equal(t, "console::stderr", output)
+}
+
+func TestRestartMetrics(t *testing.T) {
+	var rejects = func() float64 {
+		mfs, err := prometheus.DefaultGatherer.Gather()
+		assert.NoError(t, err)
+		for _, mf := range mfs {
+			if mf.GetName() == "panoptes_juniper_udp_rejects_total" {
+				return mf.Metric[0].GetCounter().GetValue()
+			}
+		}
+		return -1
+	}
+
+	cfg := config.NewMockConfig()
+	u := New(context.Background(), cfg, make(telemetry.ExtDSChan, 1), &subscriber{})
+	u.metrics["rejectsTotal"].Inc()
+	assert.Equal(t, float64(1), rejects())
+
+	u.Stop()
+	assert.Equal(t, float64(-1), rejects())
+
+	// the restarted service exports its own metrics
+	u = New(context.Background(), cfg, make(telemetry.ExtDSChan, 1), &subscriber{})
+	assert.Equal(t, float64(0), rejects())
+
+	u.Stop()
+}
+
+func TestVersion(t *testing.T) {
+	assert.Equal(t, udpVersion, Version())
+}
@@ -0,0 +1,96 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package telemetry
+
+import (
+	"sync"
+
+	"github.com/yahoo/panoptes-stream/status"
+)
+
+// DeviceMetrics represents a device NMI metrics, the metrics
+// are registered once and they're kept across the reconnects.
+type DeviceMetrics struct {
+	sync.Mutex
+	labels  status.Labels
+	metrics map[string]status.Metrics
+	closed  bool
+}
+
+// metricsRegistry keeps the devices metrics per host and service.
+type metricsRegistry struct {
+	sync.Mutex
+	devices map[string]map[string]*DeviceMetrics
+}
+
+// Get returns the device metrics, it creates the metrics by new and
+// registers them at the first call. The metrics aren't registered if
+// the device metrics is nil or it has been unregistered.
+func (d *DeviceMetrics) Get(new func() map[string]status.Metrics) map[string]status.Metrics {
+	if d == nil {
+		return new()
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	if d.closed {
+		return new()
+	}
+
+	if d.metrics == nil {
+		d.metrics = new()
+		status.Register(d.labels, d.metrics)
+	}
+
+	return d.metrics
+}
+
+func (d *DeviceMetrics) unregister() {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.metrics != nil {
+		status.Unregister(d.labels, d.metrics)
+	}
+
+	d.closed = true
+}
+
+func newMetricsRegistry() *metricsRegistry {
+	return &metricsRegistry{
+		devices: make(map[string]map[string]*DeviceMetrics),
+	}
+}
+
+// get returns the device metrics of the given host and service,
+// addr is the host label value of the metrics.
+func (r *metricsRegistry) get(host, service, addr string) *DeviceMetrics {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.devices[host]; !ok {
+		r.devices[host] = make(map[string]*DeviceMetrics)
+	}
+
+	if _, ok := r.devices[host][service]; !ok {
+		r.devices[host][service] = &DeviceMetrics{
+			labels: status.Labels{"host": addr},
+		}
+	}
+
+	return r.devices[host][service]
+}
+
+// unregister unregisters and removes the given host metrics.
+func (r *metricsRegistry) unregister(host string) {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, dm := range r.devices[host] {
+		dm.unregister()
+	}
+
+	delete(r.devices, host)
+}
@@ -0,0 +1,95 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/status"
+)
+
+func testNewMetrics() map[string]status.Metrics {
+	var metrics = make(map[string]status.Metrics)
+
+	metrics["gRPCDataTotal"] = status.NewCounter("test_nmi_grpc_data_total", "")
+	metrics["processSeconds"] = status.NewHistogramVec("test_nmi_process_seconds", "", status.LatencyBuckets, "path")
+
+	return metrics
+}
+
+func gatherCount(t *testing.T, name string) int {
+	count, err := testutil.GatherAndCount(prometheus.DefaultGatherer, name)
+	assert.NoError(t, err)
+	return count
+}
+
+func TestDeviceMetricsReconnect(t *testing.T) {
+	r := newMetricsRegistry()
+	dm := r.get("core1.lax", "test.gnmi", "core1.lax:50051")
+
+	// reconnect loop, the NMI is created per connection
+	for i := 0; i < 5; i++ {
+		metrics := dm.Get(testNewMetrics)
+		metrics["gRPCDataTotal"].Inc()
+		metrics["processSeconds"].With("/interfaces").Observe(0.001)
+	}
+
+	assert.Equal(t, dm, r.get("core1.lax", "test.gnmi", "core1.lax:50051"))
+	assert.Equal(t, uint64(5), dm.Get(testNewMetrics)["gRPCDataTotal"].Get())
+	assert.Equal(t, 1, gatherCount(t, "panoptes_test_nmi_grpc_data_total"))
+	assert.Equal(t, 1, gatherCount(t, "panoptes_test_nmi_process_seconds"))
+
+	r.unregister("core1.lax")
+	assert.Equal(t, 0, gatherCount(t, "panoptes_test_nmi_grpc_data_total"))
+	assert.Equal(t, 0, gatherCount(t, "panoptes_test_nmi_process_seconds"))
+	assert.Len(t, r.devices, 0)
+
+	// a stale NMI after the removal doesn't register the metrics
+	dm.Get(testNewMetrics)["gRPCDataTotal"].Inc()
+	assert.Equal(t, 0, gatherCount(t, "panoptes_test_nmi_grpc_data_total"))
+
+	// the device is subscribed again
+	dm = r.get("core1.lax", "test.gnmi", "core1.lax:50051")
+	assert.Equal(t, uint64(0), dm.Get(testNewMetrics)["gRPCDataTotal"].Get())
+	assert.Equal(t, 1, gatherCount(t, "panoptes_test_nmi_grpc_data_total"))
+
+	r.unregister("core1.lax")
+}
+
+func TestDeviceMetricsNil(t *testing.T) {
+	var dm *DeviceMetrics
+
+	metrics := dm.Get(testNewMetrics)
+	metrics["gRPCDataTotal"].Inc()
+
+	assert.Equal(t, uint64(1), metrics["gRPCDataTotal"].Get())
+	assert.Equal(t, 0, gatherCount(t, "panoptes_test_nmi_grpc_data_total"))
+}
+
+func TestUnsubscribeMetrics(t *testing.T) {
+	cfg := &config.MockConfig{}
+	tm := New(context.Background(), cfg, nil, nil)
+	device := config.Device{
+		DeviceConfig: config.DeviceConfig{
+			Host: "device2",
+			Port: 50051,
+		},
+	}
+
+	tm.devices["device2"] = device
+	_, tm.register["device2"] = context.WithCancel(context.Background())
+	tm.deviceMetrics.get("device2", "test.gnmi", "device2:50051").Get(testNewMetrics)
+	tm.deviceMetrics.get("device2", "test.jti", "device2:50051")
+
+	tm.unsubscribe(device)
+
+	assert.Len(t, tm.deviceMetrics.devices, 0)
+	assert.Equal(t, 0, gatherCount(t, "panoptes_test_nmi_grpc_data_total"))
+}
@@ -0,0 +1,57 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package mock
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yahoo/panoptes-stream/telemetry/juniper/proto/port"
+	jtop "github.com/yahoo/panoptes-stream/telemetry/juniper/proto/telemetry_top"
+)
+
+// JuniperPortStream returns a Juniper native port sensor (UDP) export
+// of an MX linecard with an interface and its egress queue.
+func JuniperPortStream() *jtop.TelemetryStream {
+	sensors := &jtop.JuniperNetworksSensors{}
+	proto.SetExtension(sensors, port.E_JnprInterfaceExt, &port.Port{
+		InterfaceStats: []*port.InterfaceInfos{
+			{
+				IfName:                 proto.String("xe-1/0/0"),
+				InitTime:               proto.Uint64(1597098791),
+				SnmpIfIndex:            proto.Uint32(531),
+				IfOperationalStatus:    proto.String("UP"),
+				IfAdministrationStatus: proto.String("UP"),
+				EgressQueueInfo: []*port.QueueStats{
+					{
+						QueueNumber: proto.Uint32(0),
+						Packets:     proto.Uint64(1200),
+						Bytes:       proto.Uint64(156000),
+					},
+				},
+				IngressStats: &port.InterfaceStats{
+					IfPkts:        proto.Uint64(1507),
+					IfOctets:      proto.Uint64(186311),
+					If_1SecPkts:   proto.Uint64(2),
+					If_1SecOctets: proto.Uint64(244),
+					IfUcPkts:      proto.Uint64(1507),
+					IfMcPkts:      proto.Uint64(0),
+					IfBcPkts:      proto.Uint64(0),
+				},
+			},
+		},
+	})
+
+	enterprise := &jtop.EnterpriseSensors{}
+	proto.SetExtension(enterprise, jtop.E_JuniperNetworks, sensors)
+
+	return &jtop.TelemetryStream{
+		SystemId:       proto.String("mx1-lax:192.0.2.1"),
+		ComponentId:    proto.Uint32(1),
+		SubComponentId: proto.Uint32(0),
+		SensorName:     proto.String("sensor_1000_4_1:/junos/system/linecard/interface/:/junos/system/linecard/interface/:PFE"),
+		SequenceNumber: proto.Uint32(5),
+		Timestamp:      proto.Uint64(1597098791076),
+		Enterprise:     enterprise,
+	}
+}
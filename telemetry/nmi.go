@@ -12,8 +12,9 @@ import (
 	"github.com/yahoo/panoptes-stream/config"
 )
 
-// NMIFactory is a function that returns a new instance of a NMI
-type NMIFactory func(*zap.Logger, *grpc.ClientConn, []*config.Sensor, ExtDSChan) NMI
+// NMIFactory is a function that returns a new instance of a NMI,
+// the NMI gets its metrics through DeviceMetrics to keep them across reconnects.
+type NMIFactory func(*zap.Logger, *grpc.ClientConn, []*config.Sensor, ExtDSChan, *DeviceMetrics) NMI
 
 // NMI represents a NMI
 type NMI interface {
@@ -18,7 +18,7 @@ type testNMI struct{}
 
 func (testNMI) Start(ctx context.Context) error { return nil }
 
-func NewNMI(logger *zap.Logger, conn *grpc.ClientConn, sensors []*config.Sensor, outChan ExtDSChan) NMI {
+func NewNMI(logger *zap.Logger, conn *grpc.ClientConn, sensors []*config.Sensor, outChan ExtDSChan, deviceMetrics *DeviceMetrics) NMI {
 	return testNMI{}
 }
 
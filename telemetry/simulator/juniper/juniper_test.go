@@ -46,7 +46,7 @@ func TestJuniper(t *testing.T) {
 	}
 	conn, err := grpc.DialContext(ctx, ln.Addr().String(), grpc.WithInsecure())
 	assert.NoError(t, err)
-	g := jGNMI.New(cfg.Logger(), conn, sensors, ch)
+	g := jGNMI.New(cfg.Logger(), conn, sensors, ch, nil)
 	g.Start(ctx)
 
 	t.Log(cfg.LogOutput.String())
@@ -0,0 +1,198 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package telemetry
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/yahoo/panoptes-stream/config"
+	"github.com/yahoo/panoptes-stream/status"
+)
+
+// serviceState represents a device telemetry service connection state.
+type serviceState struct {
+	sync.Mutex
+	service     string
+	sensors     []*config.Sensor
+	connected   bool
+	connectedAt time.Time
+	lastData    time.Time
+	backoff     time.Duration
+	reconnects  uint64
+	err         error
+}
+
+// stateStream records the received data time of a client stream.
+type stateStream struct {
+	grpc.ClientStream
+	state *serviceState
+}
+
+func newServiceState(service string, sensors []*config.Sensor) *serviceState {
+	return &serviceState{
+		service: service,
+		sensors: sensors,
+	}
+}
+
+func (s *serviceState) setBackoff(d time.Duration) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.backoff = d
+	if d != 0 {
+		s.reconnects++
+	}
+}
+
+func (s *serviceState) setConnected() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.connected = true
+	s.connectedAt = time.Now()
+	s.err = nil
+}
+
+func (s *serviceState) setDisconnected(err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.connected = false
+	s.err = err
+}
+
+func (s *serviceState) received() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.lastData = time.Now()
+}
+
+// streamInterceptor records the received data time of the NMI streams.
+func (s *serviceState) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stateStream{ClientStream: cs, state: s}, nil
+}
+
+func (s *stateStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.state.received()
+	}
+
+	return err
+}
+
+func (s *serviceState) get() status.ServiceState {
+	s.Lock()
+	defer s.Unlock()
+
+	state := status.ServiceState{
+		Service:    s.service,
+		Sensors:    []status.SensorState{},
+		Connected:  s.connected,
+		Backoff:    s.backoff.String(),
+		Reconnects: s.reconnects,
+	}
+
+	if s.connected {
+		connectedAt := s.connectedAt
+		state.ConnectedAt = &connectedAt
+	}
+
+	if !s.lastData.IsZero() {
+		lastData := s.lastData
+		state.LastData = &lastData
+	}
+
+	if s.err != nil {
+		state.Error = s.err.Error()
+	}
+
+	for _, sensor := range s.sensors {
+		state.Sensors = append(state.Sensors, status.SensorState{
+			Origin:         sensor.Origin,
+			Path:           sensor.Path,
+			Subscription:   sensor.Subscription,
+			Mode:           sensor.Mode,
+			SampleInterval: sensor.SampleInterval,
+			Output:         sensor.Output,
+		})
+	}
+
+	return state
+}
+
+// DeviceStates returns the subscribed devices state, it
+// implements status.DeviceReporter for the admin API.
+func (t *Telemetry) DeviceStates() []status.DeviceState {
+	var states []status.DeviceState
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	filters := t.deviceFilterOpts.getNamedOpts()
+
+	devices := make(map[string]config.Device, len(t.devices)+len(t.sessions))
+	for host, device := range t.devices {
+		devices[host] = device
+	}
+	for host, s := range t.sessions {
+		devices[host] = s.device
+	}
+
+	for host, device := range devices {
+		state := status.DeviceState{
+			Host:     host,
+			Port:     device.Port,
+			GroupID:  device.GroupID,
+			Dialout:  device.Dialout,
+			Services: []status.ServiceState{},
+		}
+
+		for name, filter := range filters {
+			if filter(device) {
+				state.Filters = append(state.Filters, name)
+			}
+		}
+		sort.Strings(state.Filters)
+
+		for _, s := range t.states[host] {
+			state.Services = append(state.Services, s.get())
+		}
+		sort.Slice(state.Services, func(i, j int) bool {
+			return state.Services[i].Service < state.Services[j].Service
+		})
+
+		states = append(states, state)
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Host < states[j].Host
+	})
+
+	return states
+}
+
+// FilterOpts returns the active filter option names.
+func (t *Telemetry) FilterOpts() []string {
+	var names []string
+
+	for name := range t.deviceFilterOpts.getNamedOpts() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,99 @@
+//: Copyright Verizon Media
+//: Licensed under the terms of the Apache 2.0 License. See LICENSE file in the project root for terms.
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/yahoo/panoptes-stream/config"
+)
+
+type testClientStream struct {
+	grpc.ClientStream
+	msgs int
+}
+
+func (s *testClientStream) RecvMsg(m interface{}) error {
+	if s.msgs < 1 {
+		return io.EOF
+	}
+
+	s.msgs--
+	return nil
+}
+
+func TestServiceState(t *testing.T) {
+	sensors := []*config.Sensor{{Path: "/interfaces/", Mode: "sample", Output: "console::stdout"}}
+	s := newServiceState("juniper.gnmi", sensors)
+
+	s.setBackoff(0)
+	s.setDisconnected(errors.New("context deadline exceeded"))
+	s.setBackoff(2 * time.Second)
+
+	state := s.get()
+	assert.False(t, state.Connected)
+	assert.Nil(t, state.ConnectedAt)
+	assert.Nil(t, state.LastData)
+	assert.Equal(t, "2s", state.Backoff)
+	assert.Equal(t, uint64(1), state.Reconnects)
+	assert.Equal(t, "context deadline exceeded", state.Error)
+	assert.Equal(t, "/interfaces/", state.Sensors[0].Path)
+	assert.Equal(t, "console::stdout", state.Sensors[0].Output)
+
+	s.setConnected()
+
+	cs, err := s.streamInterceptor(context.Background(), nil, nil, "", func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		return &testClientStream{msgs: 1}, nil
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, cs.RecvMsg(nil))
+	assert.Equal(t, io.EOF, cs.RecvMsg(nil))
+
+	state = s.get()
+	assert.True(t, state.Connected)
+	assert.NotNil(t, state.ConnectedAt)
+	assert.NotNil(t, state.LastData)
+	assert.Empty(t, state.Error)
+}
+
+func TestDeviceStates(t *testing.T) {
+	tm := New(context.Background(), &config.MockConfig{}, nil, nil)
+	device := config.Device{
+		DeviceConfig: config.DeviceConfig{
+			Host:    "core1.lax",
+			Port:    50051,
+			GroupID: 5,
+		},
+	}
+
+	tm.devices["core1.lax"] = device
+	tm.states["core1.lax"] = map[string]*serviceState{
+		"juniper.jti":  newServiceState("juniper.jti", nil),
+		"juniper.gnmi": newServiceState("juniper.gnmi", nil),
+	}
+
+	tm.AddFilterOpt("mainShard", func(d config.Device) bool { return d.GroupID == 5 })
+	tm.AddFilterOpt("extraShard", func(d config.Device) bool { return false })
+
+	states := tm.DeviceStates()
+	assert.Len(t, states, 1)
+	assert.Equal(t, "core1.lax", states[0].Host)
+	assert.Equal(t, 50051, states[0].Port)
+	assert.Equal(t, []string{"mainShard"}, states[0].Filters)
+	assert.Equal(t, "juniper.gnmi", states[0].Services[0].Service)
+	assert.Equal(t, "juniper.jti", states[0].Services[1].Service)
+
+	assert.Equal(t, []string{"extraShard", "mainShard"}, tm.FilterOpts())
+
+	_, tm.register["core1.lax"] = context.WithCancel(context.Background())
+	tm.unsubscribe(device)
+	assert.Len(t, tm.DeviceStates(), 0)
+}
@@ -26,8 +26,10 @@ import (
 
 // Telemetry represents telemetry
 type Telemetry struct {
+	mu                 sync.RWMutex
 	register           map[string]context.CancelFunc
 	devices            map[string]config.Device
+	states             map[string]map[string]*serviceState
 	cfg                config.Config
 	ctx                context.Context
 	group              singleflight.Group
@@ -37,6 +39,8 @@ type Telemetry struct {
 	informer           chan struct{}
 	deviceFilterOpts   DeviceFilterOpts
 	metrics            map[string]status.Metrics
+	deviceMetrics      *metricsRegistry
+	sessions           map[string]*session
 }
 
 type delta struct {
@@ -102,14 +106,17 @@ func New(ctx context.Context, cfg config.Config, tr *Registrar, outChan ExtDSCha
 	return &Telemetry{
 		ctx:                ctx,
 		cfg:                cfg,
-		logger:             cfg.Logger(),
+		logger:             config.NamedLogger(cfg.Logger(), "telemetry"),
 		register:           make(map[string]context.CancelFunc),
 		deviceFilterOpts:   DeviceFilterOpts{filterOpts: make(map[string]DeviceFilterOpt)},
 		devices:            make(map[string]config.Device),
+		states:             make(map[string]map[string]*serviceState),
 		informer:           make(chan struct{}, 1),
 		outChan:            outChan,
 		telemetryRegistrar: tr,
 		metrics:            metrics,
+		deviceMetrics:      newMetricsRegistry(),
+		sessions:           make(map[string]*session),
 	}
 }
 
@@ -121,22 +128,36 @@ func (t *Telemetry) subscribe(device config.Device) {
 		return
 	}
 
-	t.devices[device.Host] = device
-	ctx, t.register[device.Host] = context.WithCancel(t.ctx)
-	t.metrics["devicesCurrent"].Inc()
-
 	sensorsPerService, err := getSensorsPerService(device.Sensors)
 	if err != nil {
 		t.logger.Fatal("subscribe", zap.Error(err))
 	}
 
+	states := make(map[string]*serviceState)
+	for service, sensors := range sensorsPerService {
+		states[service] = newServiceState(service, sensors)
+	}
+
+	t.mu.Lock()
+	t.devices[device.Host] = device
+	t.states[device.Host] = states
+	ctx, t.register[device.Host] = context.WithCancel(t.ctx)
+	t.mu.Unlock()
+
+	t.metrics["devicesCurrent"].Inc()
+
+	addr := net.JoinHostPort(device.Host, strconv.Itoa(device.Port))
+
 	for service, sensors := range sensorsPerService {
+		deviceMetrics := t.deviceMetrics.get(device.Host, service, addr)
+		state := states[service]
+
 		go func(service string, sensors []*config.Sensor) {
-			addr := net.JoinHostPort(device.Host, strconv.Itoa(device.Port))
 			backoff := backoff{}
 
 			for {
 				backoffDuration := backoff.next()
+				state.setBackoff(backoffDuration)
 
 				select {
 				case <-time.After(backoffDuration):
@@ -157,25 +178,31 @@ func (t *Telemetry) subscribe(device config.Device) {
 				opts, err := t.getDialOpts(&device, service)
 				if err != nil {
 					t.logger.Error("subscribe", zap.String("event", "grpc.dialopts"), zap.Error(err))
+					state.setDisconnected(err)
 					continue
 				}
+				opts = append(opts, grpc.WithStreamInterceptor(state.streamInterceptor))
 
 				gCtx, cancel := context.WithTimeout(ctx, t.getTimeout(device.Timeout))
 				conn, err := grpc.DialContext(gCtx, addr, opts...)
 				cancel()
 				if err != nil {
 					t.logger.Error("subscribe", zap.String("event", "grpc.dial"), zap.String("host", device.Host), zap.Error(err))
+					state.setDisconnected(err)
 					continue
 				}
 
+				state.setConnected()
 				t.metrics["gRPConnCurrent"].Inc()
 				t.logger.Info("subscribe", zap.String("event", "grpc.connect"), zap.String("host", device.Host), zap.String("service", service))
 
 				new, _ := t.telemetryRegistrar.GetNMIFactory(service)
-				nmi := new(t.logger, conn, sensors, t.outChan)
+				logger := config.DeviceLogger(config.NamedLogger(t.logger, service), device.Host)
+				nmi := new(logger, conn, sensors, t.outChan, deviceMetrics)
 				err = nmi.Start(ctx)
 
 				conn.Close()
+				state.setDisconnected(err)
 				t.metrics["gRPConnCurrent"].Dec()
 
 				if err != nil {
@@ -190,9 +217,14 @@ func (t *Telemetry) subscribe(device config.Device) {
 }
 
 func (t *Telemetry) unsubscribe(device config.Device) {
+	t.mu.Lock()
 	t.register[device.Host]()
 	delete(t.register, device.Host)
 	delete(t.devices, device.Host)
+	delete(t.states, device.Host)
+	t.mu.Unlock()
+
+	t.deviceMetrics.unregister(device.Host)
 	t.metrics["devicesCurrent"].Dec()
 }
 
@@ -242,23 +274,33 @@ func (t *Telemetry) Update() {
 	}
 }
 
-// GetDevices returns devices based on the filters (if exist)
+// GetDevices returns devices based on the filters (if exist),
+// the dial-out devices are excluded.
 func (t *Telemetry) GetDevices() []config.Device {
 	var filteredDevcies []config.Device
 
+	for _, device := range t.cfg.Devices() {
+		if !device.Dialout && t.isOwned(device) {
+			filteredDevcies = append(filteredDevcies, device)
+		}
+	}
+
+	return filteredDevcies
+}
+
+// isOwned returns true if the shards are disabled or any of the filters match the device.
+func (t *Telemetry) isOwned(device config.Device) bool {
 	if !t.cfg.Global().Shards.Enabled {
-		return t.cfg.Devices()
+		return true
 	}
 
-	for _, device := range t.cfg.Devices() {
-		for _, filter := range t.deviceFilterOpts.getOpts() {
-			if filter(device) {
-				filteredDevcies = append(filteredDevcies, device)
-			}
+	for _, filter := range t.deviceFilterOpts.getOpts() {
+		if filter(device) {
+			return true
 		}
 	}
 
-	return filteredDevcies
+	return false
 }
 
 // AddFilterOpt adds filter option
@@ -283,6 +325,19 @@ func (d *DeviceFilterOpts) del(key string) {
 	delete(d.filterOpts, key)
 }
 
+func (d *DeviceFilterOpts) getNamedOpts() map[string]DeviceFilterOpt {
+	opts := make(map[string]DeviceFilterOpt)
+
+	d.RLock()
+	defer d.RUnlock()
+
+	for name, opt := range d.filterOpts {
+		opts[name] = opt
+	}
+
+	return opts
+}
+
 func (d *DeviceFilterOpts) getOpts() []DeviceFilterOpt {
 	var opts []DeviceFilterOpt
 
@@ -53,6 +53,12 @@ func TestGetDevices(t *testing.T) {
 				Host: "core1.lhr",
 			},
 		},
+		{
+			DeviceConfig: config.DeviceConfig{
+				Host:    "core1.ams",
+				Dialout: true,
+			},
+		},
 	}
 
 	cfg := &config.MockConfig{
@@ -75,10 +81,12 @@ func TestGetDevices(t *testing.T) {
 	devicesActual = tm.GetDevices()
 	assert.Len(t, devicesActual, 1)
 	assert.Equal(t, "core1.lhr", devicesActual[0].Host)
+	assert.Len(t, tm.GetDialoutDevices(), 1)
 
 	tm.DelFilterOpt("filter1")
 	devicesActual = tm.GetDevices()
 	assert.Len(t, devicesActual, 0)
+	assert.Len(t, tm.GetDialoutDevices(), 0)
 }
 
 type testGnmi struct{}
@@ -90,7 +98,7 @@ func (testGnmi) Start(ctx context.Context) error {
 	}
 	return nil
 }
-func testGnmiNew(logger *zap.Logger, conn *grpc.ClientConn, sensors []*config.Sensor, outChan ExtDSChan) NMI {
+func testGnmiNew(logger *zap.Logger, conn *grpc.ClientConn, sensors []*config.Sensor, outChan ExtDSChan, deviceMetrics *DeviceMetrics) NMI {
 	return &testGnmi{}
 }
 